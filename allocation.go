@@ -0,0 +1,26 @@
+package main
+
+// AllocationResult is the muni-vs-taxable verdict for one scenario.
+type AllocationResult struct {
+	// Federal bracket (%) where the scenario's national muni and fully
+	// taxable yields break even; NaN if they never do.
+	BreakevenBracket float64
+	// Whether the national muni wins after tax at the scenario's own settings.
+	FavorsMuni bool
+}
+
+// OptimalAllocationAnalysis reports, per scenario, the bracket at which its
+// national muni stops beating its fully taxable yield and which side its
+// current bracket lands on.
+func OptimalAllocationAnalysis(scenarios []Inputs) []AllocationResult {
+	out := make([]AllocationResult, len(scenarios))
+	for i, in := range scenarios {
+		muni := in.Instrument(NatlTaxExemptKind)
+		taxable := in.Instrument(FullyTaxableKind)
+		out[i] = AllocationResult{
+			BreakevenBracket: BreakevenFedBracket(muni.EffectiveYield(), taxable.EffectiveYield(), in),
+			FavorsMuni:       muni.AfterTax(in) > taxable.AfterTax(in),
+		}
+	}
+	return out
+}
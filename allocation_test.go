@@ -0,0 +1,26 @@
+package main
+
+import (
+	"math"
+	"testing"
+)
+
+func TestOptimalAllocationAnalysis(t *testing.T) {
+	// no state tax: a 3.8% muni matches 5% taxable at a 24% bracket
+	low := Inputs{FullyTaxable: 5, NatlTaxExempt: 3.8, FedBracket: 12}
+	high := Inputs{FullyTaxable: 5, NatlTaxExempt: 3.8, FedBracket: 35}
+	never := Inputs{FullyTaxable: 5, NatlTaxExempt: 5.5, FedBracket: 24} // the muni always wins
+	got := OptimalAllocationAnalysis([]Inputs{low, high, never})
+	if len(got) != 3 {
+		t.Fatalf("%d results", len(got))
+	}
+	if got[0].FavorsMuni || !near(got[0].BreakevenBracket, 24) {
+		t.Errorf("12%% bracket: %+v, want taxable favored, breakeven 24", got[0])
+	}
+	if !got[1].FavorsMuni || !near(got[1].BreakevenBracket, 24) {
+		t.Errorf("35%% bracket: %+v, want the muni favored, breakeven 24", got[1])
+	}
+	if !got[2].FavorsMuni || !math.IsNaN(got[2].BreakevenBracket) {
+		t.Errorf("muni above taxable: %+v, want the muni favored, no breakeven", got[2])
+	}
+}
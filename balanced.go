@@ -0,0 +1,27 @@
+package main
+
+// BalancedFund is a fund paying a mix of ordinary interest and qualified
+// dividends, like a 60/40 balanced fund. The fractions split Yield and must
+// sum to 1; both parts are state-taxable.
+type BalancedFund struct {
+	Yield             float64 // distribution yield (%)
+	OrdinaryFraction  float64 // share (0..1) taxed as ordinary income
+	QualifiedFraction float64 // share (0..1) taxed at Inputs.QDIRate
+}
+
+// Instrument is f as an Instrument, the Balanced Fund line.
+func (f BalancedFund) Instrument() Instrument {
+	return Instrument{Kind: BalancedFundKind, Yield: f.Yield, Rule: blendedRule{f.OrdinaryFraction, f.QualifiedFraction}, Optional: true}
+}
+
+// blendedRule taxes the ordinary part at the federal bracket and the
+// qualified part like qualified dividends, for one blended after-tax yield.
+type blendedRule struct {
+	ordinary, qualified float64
+}
+
+func (r blendedRule) AfterTax(yield float64, in Inputs) float64 {
+	return calcAfterTaxYield(yield*r.ordinary, true, true, 0, in) + qualifiedAfterTax(yield*r.qualified, in)
+}
+
+func (blendedRule) builtinRule() {}
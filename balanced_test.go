@@ -0,0 +1,63 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestBalancedFund(t *testing.T) {
+	simple := Inputs{FedBracket: 32, StateBracket: 5, QDIRate: 15}
+	itemizing := exampleInputs()
+	itemizing.QDIRate = 15
+	cases := []struct {
+		name string
+		in   Inputs
+		want float64
+	}{
+		// 60% at 32%+5%, 40% at 15%+5%
+		{"no itemizing", simple, 5 * (0.6*(1-0.37) + 0.4*(1-0.20))},
+		// the state tax is deducted at the 24% ordinary bracket on both parts
+		{"itemizing", itemizing, 5 * (0.6*(1-0.24-0.093*0.76) + 0.4*(1-0.15-0.093*0.76))},
+	}
+	for _, c := range cases {
+		c.in.Balanced = BalancedFund{Yield: 5, OrdinaryFraction: 0.6, QualifiedFraction: 0.4}
+		l, ok := Compute(c.in).Line(BalancedFundKind)
+		if !ok {
+			t.Fatalf("%s: no Balanced Fund line", c.name)
+		}
+		if !near(l.AfterTax, c.want) {
+			t.Errorf("%s: after tax %v, want %v", c.name, l.AfterTax, c.want)
+		}
+		if l.Label != "Balanced Fund" {
+			t.Errorf("%s: label %q", c.name, l.Label)
+		}
+	}
+	if _, ok := Compute(simple).Line(BalancedFundKind); ok {
+		t.Error("Balanced Fund line reported without a yield")
+	}
+}
+
+func TestBalancedFundValidate(t *testing.T) {
+	cases := []struct {
+		ordinary, qualified float64
+		ok                  bool
+	}{
+		{0.6, 0.4, true},
+		{1, 0, true},
+		{0, 1, true},
+		{0.6, 0.5, false},
+		{0.5, 0.4, false},
+		{1.2, -0.2, false},
+	}
+	for _, c := range cases {
+		in := exampleInputs()
+		in.Balanced = BalancedFund{Yield: 5, OrdinaryFraction: c.ordinary, QualifiedFraction: c.qualified}
+		err := in.Validate()
+		if (err == nil) != c.ok {
+			t.Errorf("%g/%g: Validate = %v, want ok %v", c.ordinary, c.qualified, err, c.ok)
+		}
+		if err != nil && !strings.Contains(err.Error(), "Balanced fractions") {
+			t.Errorf("%g/%g: error %q doesn't name the fractions", c.ordinary, c.qualified, err)
+		}
+	}
+}
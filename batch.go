@@ -0,0 +1,57 @@
+package main
+
+import (
+	"context"
+	"runtime"
+	"sync"
+)
+
+// ComputeBatch runs Compute on each of inputs, in order.
+func ComputeBatch(inputs []Inputs) []Result {
+	out := make([]Result, len(inputs))
+	for i, in := range inputs {
+		out[i] = Compute(in)
+	}
+	return out
+}
+
+// ComputeBatchParallel is ComputeBatch spread over GOMAXPROCS workers.
+// Results stay in input order.
+func ComputeBatchParallel(inputs []Inputs) []Result {
+	out, _ := ComputeBatchContext(context.Background(), inputs)
+	return out
+}
+
+// ComputeBatchContext is ComputeBatchParallel, but stops handing out work once
+// ctx is done and returns ctx's error.
+func ComputeBatchContext(ctx context.Context, inputs []Inputs) ([]Result, error) {
+	out := make([]Result, len(inputs))
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < runtime.GOMAXPROCS(0); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				out[i] = Compute(inputs[i])
+			}
+		}()
+	}
+
+	var err error
+feed:
+	for i := range inputs {
+		select {
+		case jobs <- i:
+		case <-ctx.Done():
+			err = ctx.Err()
+			break feed
+		}
+	}
+	close(jobs)
+	wg.Wait()
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
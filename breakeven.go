@@ -0,0 +1,93 @@
+package main
+
+import "math"
+
+// MuniBreakevenYield is the national (state-taxable) muni yield whose after-tax
+// value matches taxableYield's after tax. AMT inclusion uses in.NatlAmTPct.
+func MuniBreakevenYield(taxableYield float64, in Inputs) float64 {
+	muni := Instrument{Kind: NatlTaxExemptKind, StateTaxable: true, AMTPct: in.NatlAmTPct, InStateFraction: in.NatlInStateFraction}
+	return muniBreakeven(taxableYield, muni, in)
+}
+
+// InStateMuniBreakevenYield is MuniBreakevenYield for an in-state
+// (double-exempt) muni, with AMT inclusion from in.StateAmTPct.
+func InStateMuniBreakevenYield(taxableYield float64, in Inputs) float64 {
+	muni := Instrument{Kind: StateTaxExemptKind, AMTPct: in.StateAmTPct}
+	return muniBreakeven(taxableYield, muni, in)
+}
+
+func muniBreakeven(taxableYield float64, muni Instrument, in Inputs) float64 {
+	target := calcAfterTaxYield(taxableYield, true, true, 0, in)
+	// After-tax yield is linear in the pretax yield, so one unit is enough.
+	muni.Yield = 1
+	return target / muni.AfterTax(in)
+}
+
+// BreakevenFedBracket is the federal bracket (%) at which a national muni
+// yielding muniYield and a fully taxable bond yielding taxableYield are worth
+// the same after tax, with everything else in in held fixed. It's NaN if they
+// don't cross in [0,100], which includes the AMT case (the AMT rate replaces
+// the bracket, so it has no effect).
+func BreakevenFedBracket(muniYield, taxableYield float64, in Inputs) float64 {
+	muni := Instrument{Kind: NatlTaxExemptKind, Yield: muniYield, StateTaxable: true, AMTPct: in.NatlAmTPct, InStateFraction: in.NatlInStateFraction}
+	diff := func(fed float64) float64 {
+		in.FedBracket = fed
+		return muni.AfterTax(in) -
+			calcAfterTaxYield(taxableYield, true, true, 0, in)
+	}
+	return linearRoot(diff, 0, 100)
+}
+
+// BreakevenStateBracket is BreakevenFedBracket for the state bracket: the
+// state rate (%) at which an in-state muni yielding muniYield (AMT inclusion
+// from in.StateAmTPct) and a fully taxable bond yielding taxableYield are
+// worth the same after tax, with the federal side held fixed. Below it the
+// taxable bond wins. The itemized deduction of the state tax is linear in the
+// bracket too, so the crossover is exact. Any source-state credit is ignored;
+// the question is what a given home state's rate does. It's NaN if they
+// don't cross in [0,15].
+func BreakevenStateBracket(muniYield, taxableYield float64, in Inputs) float64 {
+	muni := Instrument{Kind: StateTaxExemptKind, Yield: muniYield, AMTPct: in.StateAmTPct}
+	in.SourceState, in.ResidentState = "", ""
+	diff := func(state float64) float64 {
+		in.StateBracket = state
+		return muni.AfterTax(in) -
+			calcAfterTaxYield(taxableYield, true, true, 0, in)
+	}
+	return linearRoot(diff, 0, 15)
+}
+
+// linearRoot finds where f, which must be linear on [lo,hi], crosses zero.
+// It's NaN if f doesn't change sign there.
+func linearRoot(f func(float64) float64, lo, hi float64) float64 {
+	flo, fhi := f(lo), f(hi)
+	if flo == 0 {
+		return lo
+	}
+	if flo == fhi || math.Signbit(flo) == math.Signbit(fhi) && fhi != 0 {
+		return math.NaN()
+	}
+	return lo + (hi-lo)*flo/(flo-fhi)
+}
+
+// RequiredPretaxYield is the pretax yield an instrument with the given
+// taxability needs to net afterTax under in's settings. With fedTaxable and
+// stateTaxable both set, that's the usual fully-taxable gross-up.
+func RequiredPretaxYield(afterTax float64, fedTaxable, stateTaxable bool, in Inputs) float64 {
+	if afterTax == 0 {
+		return 0
+	}
+	return afterTax / calcAfterTaxYield(1, fedTaxable, stateTaxable, 0, in)
+}
+
+// EquivalentTaxableYield is the fully taxable yield that nets the same after
+// tax as a muni yielding muniYield (state-taxable or not, with amtPct of it
+// AMT-includable), along with that after-tax yield.
+func EquivalentTaxableYield(muniYield float64, muniStateTaxable bool, amtPct float64, in Inputs) (taxable, afterTax float64) {
+	muni := Instrument{Kind: NatlTaxExemptKind, Yield: muniYield, StateTaxable: muniStateTaxable, AMTPct: amtPct}
+	if !muniStateTaxable {
+		muni.Kind = StateTaxExemptKind
+	}
+	afterTax = muni.AfterTax(in)
+	return RequiredPretaxYield(afterTax, true, true, in), afterTax
+}
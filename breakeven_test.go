@@ -0,0 +1,111 @@
+package main
+
+import (
+	"math"
+	"strings"
+	"testing"
+)
+
+func TestMuniBreakevenYield(t *testing.T) {
+	amt := exampleInputs()
+	amt.AMT = true
+	noState := exampleInputs()
+	noState.StateBracket = 0
+	for name, in := range map[string]Inputs{"example": exampleInputs(), "AMT": amt, "no state tax": noState} {
+		target := calcAfterTaxYield(5, true, true, 0, in)
+		natl := MuniBreakevenYield(5, in)
+		if got := calcAfterTaxYield(natl, false, true, in.NatlAmTPct, in); !near(got, target) {
+			t.Errorf("%s: national muni at %v nets %v, want %v", name, natl, got, target)
+		}
+		inState := InStateMuniBreakevenYield(5, in)
+		if got := calcAfterTaxYield(inState, false, false, in.StateAmTPct, in); !near(got, target) {
+			t.Errorf("%s: in-state muni at %v nets %v, want %v", name, inState, got, target)
+		}
+		if !(inState <= natl) {
+			t.Errorf("%s: in-state breakeven %v above the national %v", name, inState, natl)
+		}
+	}
+}
+
+func TestRunMuniBreakeven(t *testing.T) {
+	var out strings.Builder
+	if err := runCommand("muni-breakeven", []string{"-taxable", "5%", "-fed", "24", "-state", "0"}, &out); err != nil {
+		t.Fatal(err)
+	}
+	// with no state tax both munis need 5 * (1 - 0.24)
+	want := "To match 5.000% fully taxable:\nNat'l Tax-Exempt:   3.800%\nState Tax-Exempt:   3.800%\n"
+	if out.String() != want {
+		t.Errorf("got\n%s\nwant\n%s", out.String(), want)
+	}
+}
+
+func TestEquivalentTaxableYield(t *testing.T) {
+	for _, tt := range []struct {
+		name         string
+		in           Inputs
+		stateTaxable bool
+		amtPct       float64
+	}{
+		{"in-state muni", Inputs{FedBracket: 24, StateBracket: 5}, false, 0},
+		{"out-of-state muni", Inputs{FedBracket: 24, StateBracket: 5}, true, 0},
+		{"itemizing", Inputs{FedBracket: 35, StateBracket: 9.3, Itemize: true}, true, 0},
+		{"AMT exposure", Inputs{FedBracket: 32, StateBracket: 5, AMT: true, AMTBracketIndex: 4}, true, 30},
+	} {
+		taxable, afterTax := EquivalentTaxableYield(3.5, tt.stateTaxable, tt.amtPct, tt.in)
+		if want := calcAfterTaxYield(3.5, false, tt.stateTaxable, tt.amtPct, tt.in); !near(afterTax, want) {
+			t.Errorf("%s: muni after tax %v, want %v", tt.name, afterTax, want)
+		}
+		// round trip: the taxable yield nets the muni's after-tax yield
+		if got := calcAfterTaxYield(taxable, true, true, 0, tt.in); !near(got, afterTax) {
+			t.Errorf("%s: %v taxable nets %v, want %v", tt.name, taxable, got, afterTax)
+		}
+		if taxable <= 3.5 {
+			t.Errorf("%s: taxable equivalent %v isn't above the muni's 3.5", tt.name, taxable)
+		}
+	}
+}
+
+func TestBreakevenStateBracket(t *testing.T) {
+	// sweep is a brute-force crossover: the first state rate, in 0.001
+	// steps over [0,15], where the winner changes.
+	sweep := func(muniYield, taxableYield float64, in Inputs) float64 {
+		diff := func(s float64) float64 {
+			in.StateBracket = s
+			return calcAfterTaxYield(muniYield, false, false, 0, in) - calcAfterTaxYield(taxableYield, true, true, 0, in)
+		}
+		start := diff(0)
+		for s := 0.0; s <= 15; s += 0.001 {
+			if d := diff(s); d == 0 || math.Signbit(d) != math.Signbit(start) {
+				return s
+			}
+		}
+		return math.NaN()
+	}
+	for _, tt := range []struct {
+		name          string
+		muni, taxable float64
+		in            Inputs
+		want          float64
+	}{
+		// 4.8 * (0.76 - s) = 3.5
+		{"24%", 3.5, 4.8, Inputs{FedBracket: 24}, 100 * (0.76 - 3.5/4.8)},
+		// 4.8 * (0.76 - 0.76s) = 3.5
+		{"24%, itemizing", 3.5, 4.8, Inputs{FedBracket: 24, Itemize: true}, 100 * (0.76 - 3.5/4.8) / 0.76},
+		{"37%, NIIT", 3.2, 5.5, Inputs{FedBracket: 37, NIIT: true}, 100 * (1 - 0.37 - 0.038 - 3.2/5.5)},
+		{"muni always wins", 4, 4.5, Inputs{FedBracket: 24}, math.NaN()},
+		{"taxable always wins", 2, 5, Inputs{FedBracket: 10}, math.NaN()},
+	} {
+		got := BreakevenStateBracket(tt.muni, tt.taxable, tt.in)
+		brute := sweep(tt.muni, tt.taxable, tt.in)
+		if math.IsNaN(tt.want) {
+			if !math.IsNaN(got) {
+				t.Errorf("%s: %v, want NaN", tt.name, got)
+			}
+		} else if !near(got, tt.want) {
+			t.Errorf("%s: %v, want %v", tt.name, got, tt.want)
+		}
+		if math.IsNaN(got) != math.IsNaN(brute) || !math.IsNaN(got) && math.Abs(got-brute) > 0.001 {
+			t.Errorf("%s: %v, brute-force sweep says %v", tt.name, got, brute)
+		}
+	}
+}
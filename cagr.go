@@ -0,0 +1,31 @@
+package main
+
+import "math"
+
+// AfterTaxCAGR is the compound annual growth (%) of reinvesting at
+// afterTaxYield (%) for years, taxed each year as it's earned. With one
+// rate throughout that's just afterTaxYield back; AfterTaxCAGRByYear is the
+// one that changes. NaN if years < 1.
+func AfterTaxCAGR(afterTaxYield float64, years int) float64 {
+	if years < 1 {
+		return math.NaN()
+	}
+	growth := math.Pow(1+afterTaxYield/100, float64(years))
+	return 100 * (math.Pow(growth, 1/float64(years)) - 1)
+}
+
+// AfterTaxCAGRByYear is AfterTaxCAGR for inst held one year per entry of
+// fedBrackets, each year's interest taxed at that year's federal bracket (the
+// rest of in held fixed), e.g. to model a rising bracket through a ladder.
+// NaN with no years.
+func AfterTaxCAGRByYear(inst Instrument, fedBrackets []float64, in Inputs) float64 {
+	if len(fedBrackets) == 0 {
+		return math.NaN()
+	}
+	growth := 1.0
+	for _, fed := range fedBrackets {
+		in.FedBracket = fed
+		growth *= 1 + inst.AfterTax(in)/100
+	}
+	return 100 * (math.Pow(growth, 1/float64(len(fedBrackets))) - 1)
+}
@@ -0,0 +1,44 @@
+package main
+
+import (
+	"math"
+	"testing"
+)
+
+func TestAfterTaxCAGR(t *testing.T) {
+	for _, years := range []int{1, 5, 30} {
+		if got := AfterTaxCAGR(3.42, years); !near(got, 3.42) {
+			t.Errorf("%d years: %v, want 3.42", years, got)
+		}
+	}
+	if got := AfterTaxCAGR(3.42, 0); !math.IsNaN(got) {
+		t.Errorf("0 years: %v, want NaN", got)
+	}
+}
+
+func TestAfterTaxCAGRByYear(t *testing.T) {
+	treasury := Instrument{Kind: TreasuryKind, Yield: 5, FedTaxable: true}
+	in := Inputs{StateBracket: 5}
+
+	// a flat bracket is the plain after-tax yield
+	if got := AfterTaxCAGRByYear(treasury, []float64{24, 24, 24}, in); !near(got, 3.8) {
+		t.Errorf("flat 24%%: %v, want 3.8", got)
+	}
+
+	// 22% then 24% then 32%: the geometric mean of each year's yield
+	rising := []float64{22, 24, 32}
+	want := 100 * (math.Cbrt((1+0.05*0.78)*(1+0.05*0.76)*(1+0.05*0.68)) - 1)
+	got := AfterTaxCAGRByYear(treasury, rising, in)
+	if !near(got, want) {
+		t.Errorf("rising brackets: %v, want %v", got, want)
+	}
+	if !(got < 5*0.78 && got > 5*0.68) {
+		t.Errorf("rising brackets: %v isn't between the first and last years' yields", got)
+	}
+	if in.FedBracket != 0 {
+		t.Error("the caller's Inputs changed")
+	}
+	if got := AfterTaxCAGRByYear(treasury, nil, in); !math.IsNaN(got) {
+		t.Errorf("no years: %v, want NaN", got)
+	}
+}
@@ -0,0 +1,147 @@
+// Package chart draws simple line charts as PNG or SVG using only the
+// standard library, for the server's /chart endpoint.
+package chart
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"sort"
+)
+
+// Series is one line: Y[i] at X[i], X ascending.
+type Series struct {
+	Name string
+	X, Y []float64
+}
+
+// Chart is a line chart. Marks are vertical dashed lines at X positions,
+// e.g. where two series cross.
+type Chart struct {
+	Title  string
+	XLabel string
+	YLabel string
+	Series []Series
+	Marks  []float64
+}
+
+// Crossings are the X values where a and b cross, interpolated linearly
+// between samples, including any sample where they're equal (the last one
+// too). a and b must share X.
+func Crossings(a, b Series) []float64 {
+	var xs []float64
+	n := min(len(a.X), len(a.Y), len(b.Y))
+	for i := 0; i+1 < n; i++ {
+		d0, d1 := a.Y[i]-b.Y[i], a.Y[i+1]-b.Y[i+1]
+		switch {
+		case math.IsNaN(d0) || math.IsNaN(d1):
+		case d0 == 0:
+			xs = append(xs, a.X[i])
+		case d0*d1 < 0:
+			xs = append(xs, a.X[i]+(a.X[i+1]-a.X[i])*d0/(d0-d1))
+		}
+	}
+	// the loop only looks at each pair's first sample for equality
+	if n > 0 && a.Y[n-1] == b.Y[n-1] {
+		xs = append(xs, a.X[n-1])
+	}
+	return xs
+}
+
+// layout maps data to pixels for a width x height canvas.
+type layout struct {
+	width, height          int
+	left, right, top, bot  int // plot area edges, in pixels
+	xmin, xmax, ymin, ymax float64
+}
+
+const legendWidth = 170
+
+func (c Chart) layout(width, height int) (layout, error) {
+	l := layout{width: width, height: height, left: 56, right: width - legendWidth, top: 30, bot: height - 44}
+	if l.right-l.left < 50 || l.bot-l.top < 50 {
+		return l, fmt.Errorf("chart: %dx%d is too small", width, height)
+	}
+	l.xmin, l.xmax = math.Inf(1), math.Inf(-1)
+	l.ymin, l.ymax = 0, math.Inf(-1)
+	for _, s := range c.Series {
+		for i, x := range s.X {
+			if i >= len(s.Y) || !finite(s.Y[i]) {
+				continue
+			}
+			l.xmin, l.xmax = math.Min(l.xmin, x), math.Max(l.xmax, x)
+			l.ymin, l.ymax = math.Min(l.ymin, s.Y[i]), math.Max(l.ymax, s.Y[i])
+		}
+	}
+	if !finite(l.xmin) || !finite(l.ymax) {
+		return l, fmt.Errorf("chart: no finite points")
+	}
+	if l.xmax == l.xmin {
+		l.xmax = l.xmin + 1
+	}
+	if l.ymax == l.ymin {
+		l.ymax = l.ymin + 1
+	}
+	l.ymax += (l.ymax - l.ymin) * 0.05
+	return l, nil
+}
+
+func (l layout) px(x float64) float64 {
+	return float64(l.left) + (x-l.xmin)/(l.xmax-l.xmin)*float64(l.right-l.left)
+}
+
+func (l layout) py(y float64) float64 {
+	return float64(l.bot) - (y-l.ymin)/(l.ymax-l.ymin)*float64(l.bot-l.top)
+}
+
+// ticks are about n evenly spaced round values covering [lo, hi].
+func ticks(lo, hi float64, n int) []float64 {
+	step := math.Pow(10, math.Floor(math.Log10((hi-lo)/float64(n))))
+	for _, m := range []float64{1, 2, 5, 10} {
+		if (hi-lo)/(step*m) <= float64(n) {
+			step *= m
+			break
+		}
+	}
+	var t []float64
+	for v := math.Ceil(lo/step) * step; v <= hi+step*1e-9; v += step {
+		t = append(t, v)
+	}
+	return t
+}
+
+func tickLabel(v float64) string {
+	if v == math.Trunc(v) {
+		return fmt.Sprintf("%.0f", v)
+	}
+	return fmt.Sprintf("%.1f", v)
+}
+
+func finite(f float64) bool { return !math.IsNaN(f) && !math.IsInf(f, 0) }
+
+// palette colors the series in order, as RGB.
+var palette = [][3]uint8{
+	{31, 119, 180}, {255, 127, 14}, {44, 160, 44}, {214, 39, 40}, {148, 103, 189},
+	{140, 86, 75}, {227, 119, 194}, {127, 127, 127}, {188, 189, 34}, {23, 190, 207},
+}
+
+func seriesColor(i int) [3]uint8 { return palette[i%len(palette)] }
+
+// sortedMarks are c.Marks in order, for stable output.
+func (c Chart) sortedMarks() []float64 {
+	m := append([]float64(nil), c.Marks...)
+	sort.Float64s(m)
+	return m
+}
+
+// Write renders c as "png" or "svg".
+func (c Chart) Write(w io.Writer, format string, width, height int) error {
+	switch format {
+	case "", "png":
+		return c.PNG(w, width, height)
+	case "svg":
+		return c.SVG(w, width, height)
+	default:
+		return fmt.Errorf("chart: unknown format %q", format)
+	}
+}
@@ -0,0 +1,34 @@
+package chart
+
+import (
+	"math"
+	"slices"
+	"testing"
+)
+
+func TestCrossings(t *testing.T) {
+	x := []float64{0, 1, 2, 3}
+	nan := math.NaN()
+	tests := []struct {
+		name string
+		a, b []float64
+		want []float64
+	}{
+		{"none", []float64{1, 2, 3, 4}, []float64{0, 0, 0, 0}, nil},
+		{"between samples", []float64{0, 1, 2, 3}, []float64{1.5, 1.5, 1.5, 1.5}, []float64{1.5}},
+		{"on a sample", []float64{0, 1, 2, 3}, []float64{1, 1, 1, 1}, []float64{1}},
+		{"on the first sample", []float64{1, 2, 3, 4}, []float64{1, 1, 1, 1}, []float64{0}},
+		{"on the last sample", []float64{0, 1, 2, 3}, []float64{3, 3, 3, 3}, []float64{3}},
+		{"twice", []float64{0, 2, 0, 2}, []float64{1, 1, 1, 1}, []float64{0.5, 1.5, 2.5}},
+		{"next to NaN", []float64{0, nan, 2, 3}, []float64{1, 1, 1, 1}, nil},
+	}
+	for _, tt := range tests {
+		got := Crossings(Series{X: x, Y: tt.a}, Series{X: x, Y: tt.b})
+		if !slices.Equal(got, tt.want) {
+			t.Errorf("%s: got %v, want %v", tt.name, got, tt.want)
+		}
+	}
+	if got := Crossings(Series{X: []float64{5}, Y: []float64{1}}, Series{Y: []float64{1}}); !slices.Equal(got, []float64{5}) {
+		t.Errorf("single equal sample: got %v, want [5]", got)
+	}
+}
@@ -0,0 +1,200 @@
+package chart
+
+import (
+	"image"
+	"image/color"
+	"image/png"
+	"io"
+	"math"
+	"strings"
+)
+
+// PNG writes c to w as a width x height PNG. Text uses a built-in 3x5
+// pixel font at double size, uppercase only.
+func (c Chart) PNG(w io.Writer, width, height int) error {
+	l, err := c.layout(width, height)
+	if err != nil {
+		return err
+	}
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	fill(img, img.Bounds(), color.RGBA{255, 255, 255, 255})
+	grid := color.RGBA{235, 235, 235, 255}
+	black := color.RGBA{0, 0, 0, 255}
+
+	for _, x := range ticks(l.xmin, l.xmax, 8) {
+		px := int(math.Round(l.px(x)))
+		line(img, px, l.top, px, l.bot, grid, 1)
+		drawText(img, px, l.bot+8, tickLabel(x), black, alignCenter)
+	}
+	for _, y := range ticks(l.ymin, l.ymax, 6) {
+		py := int(math.Round(l.py(y)))
+		line(img, l.left, py, l.right, py, grid, 1)
+		drawText(img, l.left-6, py-5, tickLabel(y), black, alignRight)
+	}
+	for _, x := range c.sortedMarks() {
+		px := int(math.Round(l.px(x)))
+		for y := l.top; y < l.bot; y += 7 {
+			line(img, px, y, px, min(y+3, l.bot), color.RGBA{136, 136, 136, 255}, 1)
+		}
+	}
+	line(img, l.left, l.top, l.right, l.top, black, 1)
+	line(img, l.left, l.bot, l.right, l.bot, black, 1)
+	line(img, l.left, l.top, l.left, l.bot, black, 1)
+	line(img, l.right, l.top, l.right, l.bot, black, 1)
+
+	for i, s := range c.Series {
+		rgb := seriesColor(i)
+		col := color.RGBA{rgb[0], rgb[1], rgb[2], 255}
+		havePrev := false
+		var x0, y0 int
+		for j, x := range s.X {
+			if j >= len(s.Y) || !finite(s.Y[j]) {
+				havePrev = false
+				continue
+			}
+			x1, y1 := int(math.Round(l.px(x))), int(math.Round(l.py(s.Y[j])))
+			if havePrev {
+				line(img, x0, y0, x1, y1, col, 2)
+			}
+			x0, y0, havePrev = x1, y1, true
+		}
+		y := l.top + 6 + 18*i
+		fill(img, image.Rect(l.right+12, y+3, l.right+26, y+7), col)
+		drawText(img, l.right+32, y, s.Name, black, alignLeft)
+	}
+	drawText(img, (l.left+l.right)/2, 8, c.Title, black, alignCenter)
+	drawText(img, (l.left+l.right)/2, height-18, c.XLabel, black, alignCenter)
+	drawText(img, 4, l.top-16, c.YLabel, black, alignLeft)
+	return png.Encode(w, img)
+}
+
+func fill(img *image.RGBA, r image.Rectangle, c color.RGBA) {
+	r = r.Intersect(img.Bounds())
+	for y := r.Min.Y; y < r.Max.Y; y++ {
+		for x := r.Min.X; x < r.Max.X; x++ {
+			img.SetRGBA(x, y, c)
+		}
+	}
+}
+
+// line draws a Bresenham line thick pixels wide.
+func line(img *image.RGBA, x0, y0, x1, y1 int, c color.RGBA, thick int) {
+	dx, dy := abs(x1-x0), -abs(y1-y0)
+	sx, sy := sign(x1-x0), sign(y1-y0)
+	e := dx + dy
+	for {
+		fill(img, image.Rect(x0, y0, x0+thick, y0+thick), c)
+		if x0 == x1 && y0 == y1 {
+			return
+		}
+		if e2 := 2 * e; e2 >= dy {
+			e += dy
+			x0 += sx
+		} else {
+			e += dx
+			y0 += sy
+		}
+	}
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
+
+func sign(n int) int {
+	switch {
+	case n < 0:
+		return -1
+	case n > 0:
+		return 1
+	}
+	return 0
+}
+
+type align int
+
+const (
+	alignLeft align = iota
+	alignCenter
+	alignRight
+)
+
+// glyphScale is how many pixels each font pixel takes.
+const glyphScale = 2
+
+// drawText draws s with its top at y, x its left, center or right edge.
+func drawText(img *image.RGBA, x, y int, s string, c color.RGBA, a align) {
+	runes := []rune(strings.ToUpper(s))
+	adv := 4 * glyphScale
+	w := len(runes)*adv - glyphScale
+	switch a {
+	case alignCenter:
+		x -= w / 2
+	case alignRight:
+		x -= w
+	}
+	for i, r := range runes {
+		g, ok := glyphs[r]
+		if !ok {
+			continue
+		}
+		for row, bits := range g {
+			for col, b := range bits {
+				if b == '#' {
+					px, py := x+i*adv+col*glyphScale, y+row*glyphScale
+					fill(img, image.Rect(px, py, px+glyphScale, py+glyphScale), c)
+				}
+			}
+		}
+	}
+}
+
+// glyphs is a 3x5 pixel font.
+var glyphs = map[rune][5]string{
+	'0':  {"###", "# #", "# #", "# #", "###"},
+	'1':  {" # ", "## ", " # ", " # ", "###"},
+	'2':  {"###", "  #", "###", "#  ", "###"},
+	'3':  {"###", "  #", "###", "  #", "###"},
+	'4':  {"# #", "# #", "###", "  #", "  #"},
+	'5':  {"###", "#  ", "###", "  #", "###"},
+	'6':  {"###", "#  ", "###", "# #", "###"},
+	'7':  {"###", "  #", "  #", "  #", "  #"},
+	'8':  {"###", "# #", "###", "# #", "###"},
+	'9':  {"###", "# #", "###", "  #", "###"},
+	'.':  {"   ", "   ", "   ", "   ", " # "},
+	'-':  {"   ", "   ", "###", "   ", "   "},
+	'%':  {"# #", "  #", " # ", "#  ", "# #"},
+	'\'': {" # ", " # ", "   ", "   ", "   "},
+	'/':  {"  #", "  #", " # ", "#  ", "#  "},
+	'(':  {" # ", "#  ", "#  ", "#  ", " # "},
+	')':  {" # ", "  #", "  #", "  #", " # "},
+	'A':  {" # ", "# #", "###", "# #", "# #"},
+	'B':  {"## ", "# #", "## ", "# #", "## "},
+	'C':  {" ##", "#  ", "#  ", "#  ", " ##"},
+	'D':  {"## ", "# #", "# #", "# #", "## "},
+	'E':  {"###", "#  ", "## ", "#  ", "###"},
+	'F':  {"###", "#  ", "## ", "#  ", "#  "},
+	'G':  {" ##", "#  ", "# #", "# #", " ##"},
+	'H':  {"# #", "# #", "###", "# #", "# #"},
+	'I':  {"###", " # ", " # ", " # ", "###"},
+	'J':  {"  #", "  #", "  #", "# #", " # "},
+	'K':  {"# #", "# #", "## ", "# #", "# #"},
+	'L':  {"#  ", "#  ", "#  ", "#  ", "###"},
+	'M':  {"# #", "###", "###", "# #", "# #"},
+	'N':  {"## ", "# #", "# #", "# #", "# #"},
+	'O':  {" # ", "# #", "# #", "# #", " # "},
+	'P':  {"## ", "# #", "## ", "#  ", "#  "},
+	'Q':  {" # ", "# #", "# #", "## ", " ##"},
+	'R':  {"## ", "# #", "## ", "# #", "# #"},
+	'S':  {" ##", "#  ", " # ", "  #", "## "},
+	'T':  {"###", " # ", " # ", " # ", " # "},
+	'U':  {"# #", "# #", "# #", "# #", "###"},
+	'V':  {"# #", "# #", "# #", "# #", " # "},
+	'W':  {"# #", "# #", "###", "###", "# #"},
+	'X':  {"# #", "# #", " # ", "# #", "# #"},
+	'Y':  {"# #", "# #", " # ", " # ", " # "},
+	'Z':  {"###", "  #", " # ", "#  ", "###"},
+}
@@ -0,0 +1,59 @@
+package chart
+
+import (
+	"bufio"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// SVG writes c to w as a width x height SVG document.
+func (c Chart) SVG(w io.Writer, width, height int) error {
+	l, err := c.layout(width, height)
+	if err != nil {
+		return err
+	}
+	b := bufio.NewWriter(w)
+	text := func(x, y float64, anchor, s string) {
+		var esc strings.Builder
+		xml.EscapeText(&esc, []byte(s))
+		fmt.Fprintf(b, `<text x="%.1f" y="%.1f" text-anchor="%s">%s</text>`+"\n", x, y, anchor, esc.String())
+	}
+
+	fmt.Fprintf(b, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" font-family="sans-serif" font-size="12">`+"\n", width, height)
+	fmt.Fprintf(b, `<rect width="%d" height="%d" fill="white"/>`+"\n", width, height)
+	for _, x := range ticks(l.xmin, l.xmax, 8) {
+		fmt.Fprintf(b, `<line x1="%.1f" y1="%d" x2="%.1f" y2="%d" stroke="#eee"/>`+"\n", l.px(x), l.top, l.px(x), l.bot)
+		text(l.px(x), float64(l.bot+16), "middle", tickLabel(x))
+	}
+	for _, y := range ticks(l.ymin, l.ymax, 6) {
+		fmt.Fprintf(b, `<line x1="%d" y1="%.1f" x2="%d" y2="%.1f" stroke="#eee"/>`+"\n", l.left, l.py(y), l.right, l.py(y))
+		text(float64(l.left-6), l.py(y)+4, "end", tickLabel(y))
+	}
+	fmt.Fprintf(b, `<rect x="%d" y="%d" width="%d" height="%d" fill="none" stroke="black"/>`+"\n", l.left, l.top, l.right-l.left, l.bot-l.top)
+	for _, x := range c.sortedMarks() {
+		fmt.Fprintf(b, `<line x1="%.1f" y1="%d" x2="%.1f" y2="%d" stroke="#888" stroke-dasharray="4 3"/>`+"\n", l.px(x), l.top, l.px(x), l.bot)
+	}
+	for i, s := range c.Series {
+		col := seriesColor(i)
+		var pts []string
+		for j, x := range s.X {
+			if j < len(s.Y) && finite(s.Y[j]) {
+				pts = append(pts, fmt.Sprintf("%.1f,%.1f", l.px(x), l.py(s.Y[j])))
+			}
+		}
+		fmt.Fprintf(b, `<polyline fill="none" stroke="rgb(%d,%d,%d)" stroke-width="2" points="%s"/>`+"\n", col[0], col[1], col[2], strings.Join(pts, " "))
+		y := float64(l.top + 10 + 18*i)
+		fmt.Fprintf(b, `<rect x="%d" y="%.1f" width="14" height="4" fill="rgb(%d,%d,%d)"/>`+"\n", l.right+12, y-4, col[0], col[1], col[2])
+		text(float64(l.right+32), y+1, "start", s.Name)
+	}
+	text(float64(l.left+l.right)/2, 18, "middle", c.Title)
+	text(float64(l.left+l.right)/2, float64(height-8), "middle", c.XLabel)
+	mid := float64(l.top+l.bot) / 2
+	fmt.Fprintf(b, `<g transform="translate(14 %.1f) rotate(-90)">`, mid)
+	text(0, 0, "middle", c.YLabel)
+	fmt.Fprintln(b, `</g>`)
+	fmt.Fprintln(b, `</svg>`)
+	return b.Flush()
+}
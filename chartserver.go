@@ -0,0 +1,99 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"github.com/kybouw/taxableyield/chart"
+)
+
+// maxChartPoints caps a /chart sweep.
+const maxChartPoints = 1000
+
+// handleChart draws after-tax yield against the federal bracket for each of
+// the posted Inputs' lines, with dashed marks where a line crosses the
+// fully taxable one. The sweep takes query parameters from, to and step
+// (brackets in %, default 10 to 37 by 1), and format=svg for SVG instead of
+// PNG.
+func (cfg serverConfig) handleChart(w http.ResponseWriter, r *http.Request) {
+	var in Inputs
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, cfg.MaxUploadBytes)).Decode(&in); err != nil {
+		httpError(w, r, "bad request: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	q := r.URL.Query()
+	from, err1 := queryFloat(q, "from", 10)
+	to, err2 := queryFloat(q, "to", 37)
+	step, err3 := queryFloat(q, "step", 1)
+	for _, err := range []error{err1, err2, err3} {
+		if err != nil {
+			httpError(w, r, "bad request: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+	if !(step > 0) || !(to >= from) || (to-from)/step+1 > maxChartPoints {
+		httpError(w, r, fmt.Sprintf("bad request: want from <= to and step > 0, at most %d points", maxChartPoints), http.StatusBadRequest)
+		return
+	}
+	format := q.Get("format")
+	contentType := map[string]string{"": "image/png", "png": "image/png", "svg": "image/svg+xml"}[format]
+	if contentType == "" {
+		httpError(w, r, fmt.Sprintf("bad request: unknown format %q", format), http.StatusBadRequest)
+		return
+	}
+
+	c := bracketSweepChart(in, from, to, step)
+	var buf bytes.Buffer
+	if err := c.Write(&buf, format, 800, 480); err != nil {
+		httpError(w, r, err.Error(), http.StatusUnprocessableEntity)
+		return
+	}
+	w.Header().Set("Content-Type", contentType)
+	w.Write(buf.Bytes())
+}
+
+// bracketSweepChart is the /chart chart: one series per line in Compute's
+// order, at each federal bracket from from to to by step.
+func bracketSweepChart(in Inputs, from, to, step float64) chart.Chart {
+	var series []chart.Series
+	index := map[string]int{}
+	for _, p := range BracketSweep(in, from, to, step) {
+		fed, res := p.FedBracket, p.Result
+		for j, key := range lineKeys(res.Lines) {
+			k, ok := index[key]
+			if !ok {
+				k = len(series)
+				index[key] = k
+				series = append(series, chart.Series{Name: res.Lines[j].Label})
+			}
+			series[k].X = append(series[k].X, fed)
+			series[k].Y = append(series[k].Y, res.Lines[j].AfterTax)
+		}
+	}
+	c := chart.Chart{Title: "After-tax yield by federal bracket", XLabel: "Federal bracket (%)", YLabel: "After tax (%)", Series: series}
+	if k, ok := index[instrumentKeys[FullyTaxableKind]]; ok {
+		for i, s := range series {
+			if i != k {
+				c.Marks = append(c.Marks, chart.Crossings(s, series[k])...)
+			}
+		}
+	}
+	return c
+}
+
+// queryFloat is query parameter name, parsed with ParsePercent (so "24%"
+// is fine), or def if it's absent.
+func queryFloat(q url.Values, name string, def float64) (float64, error) {
+	s := q.Get(name)
+	if s == "" {
+		return def, nil
+	}
+	v, err := ParsePercent(s)
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", name, err)
+	}
+	return v, nil
+}
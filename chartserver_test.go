@@ -0,0 +1,81 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"image/png"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func chartPost(t *testing.T, query string) *httptest.ResponseRecorder {
+	t.Helper()
+	body, err := json.Marshal(exampleInputs())
+	if err != nil {
+		t.Fatal(err)
+	}
+	rec := httptest.NewRecorder()
+	newServer(defaultServerConfig).ServeHTTP(rec, httptest.NewRequest("POST", "/chart"+query, bytes.NewReader(body)))
+	return rec
+}
+
+func TestChartPNG(t *testing.T) {
+	rec := chartPost(t, "")
+	if rec.Code != 200 {
+		t.Fatalf("status %d: %s", rec.Code, rec.Body)
+	}
+	if ct := rec.Header().Get("Content-Type"); ct != "image/png" {
+		t.Errorf("Content-Type %q", ct)
+	}
+	b := rec.Body.Bytes()
+	if !bytes.HasPrefix(b, []byte("\x89PNG\r\n\x1a\n")) || len(b) <= 8 {
+		t.Fatalf("not a PNG: %d bytes starting %q", len(b), b[:min(len(b), 8)])
+	}
+	img, err := png.Decode(bytes.NewReader(b))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r := img.Bounds(); r.Dx() != 800 || r.Dy() != 480 {
+		t.Errorf("image is %v, want 800x480", r)
+	}
+}
+
+func TestChartSVG(t *testing.T) {
+	rec := chartPost(t, "?format=svg&from=15&to=35&step=5")
+	if rec.Code != 200 {
+		t.Fatalf("status %d: %s", rec.Code, rec.Body)
+	}
+	if ct := rec.Header().Get("Content-Type"); ct != "image/svg+xml" {
+		t.Errorf("Content-Type %q", ct)
+	}
+	if !strings.Contains(rec.Body.String(), "<svg") {
+		t.Errorf("not an SVG: %.80s", rec.Body)
+	}
+	for _, q := range []string{"?format=gif", "?step=0", "?from=30&to=20", "?from=abc", "?from=0&to=100&step=0.01"} {
+		if rec := chartPost(t, q); rec.Code != 400 {
+			t.Errorf("%s: status %d, want 400", q, rec.Code)
+		}
+	}
+}
+
+func TestBracketSweepChart(t *testing.T) {
+	in := exampleInputs()
+	c := bracketSweepChart(in, 10, 37, 1)
+	if want := len(Compute(in).Lines); len(c.Series) != want {
+		t.Errorf("%d series, want one per line (%d)", len(c.Series), want)
+	}
+	for _, s := range c.Series {
+		if len(s.X) != 28 || s.X[0] != 10 || s.X[27] != 37 {
+			t.Errorf("%s: sampled at %v", s.Name, s.X)
+		}
+	}
+	if len(c.Marks) == 0 {
+		t.Error("no muni/taxable crossover marked")
+	}
+	for _, x := range c.Marks {
+		if x < 10 || x > 37 {
+			t.Errorf("crossover mark %v outside the sweep", x)
+		}
+	}
+}
@@ -0,0 +1,119 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"time"
+)
+
+// runRoot handles the tool run without a subcommand: the example, or a
+// config file, optionally watched for changes.
+func runRoot(args []string, stdout io.Writer) error {
+	fs := flag.NewFlagSet("taxableyield", flag.ContinueOnError)
+	config := fs.String("config", "", "JSON Inputs file to compute instead of the example")
+	watch := fs.Bool("watch", false, "recompute whenever -config changes")
+	forceColor := fs.Bool("color", false, "color output even when it isn't a terminal")
+	noColor := fs.Bool("no-color", false, "never color output")
+	profile := registerProfileFlags(fs)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	color := useColor(stdout, *forceColor, *noColor)
+
+	if *watch {
+		if *config == "" {
+			return fmt.Errorf("-watch needs -config")
+		}
+		if profile.load != "" || profile.save != "" {
+			return fmt.Errorf("-watch can't be combined with -profile or -save-profile")
+		}
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+		defer stop()
+		return watchConfig(ctx, stdout, *config, 250*time.Millisecond, 500*time.Millisecond, color)
+	}
+	in := exampleInputs()
+	if *config != "" {
+		var err error
+		if in, err = LoadConfig(*config); err != nil {
+			return err
+		}
+	}
+	if done, err := profile.resolve(fs, &in, stdout); done || err != nil {
+		return err
+	}
+	fmt.Fprintln(stdout, computeText(in, color))
+	return nil
+}
+
+// runCommand dispatches a CLI subcommand.
+func runCommand(name string, args []string, stdout io.Writer) error {
+	switch name {
+	case "muni-breakeven":
+		return runMuniBreakeven(args, stdout)
+	case "explain":
+		return runExplain(args, stdout)
+	case "vs-savings":
+		return runVsSavings(args, stdout)
+	case "serve":
+		return runServe(args, stdout)
+	case "golden":
+		return runGolden(args, stdout)
+	default:
+		return fmt.Errorf("unknown command %q", name)
+	}
+}
+
+// taxFlags registers the tax settings shared by every subcommand, and the
+// profile flags; call resolve on the result once fs is parsed.
+func taxFlags(fs *flag.FlagSet, in *Inputs) *profileFlags {
+	percentVar(fs, &in.FedBracket, "fed", 24, "federal bracket (%)")
+	percentVar(fs, &in.StateBracket, "state", 0, "state bracket (%)")
+	percentVar(fs, &in.LocalBracket, "local", 0, "local income tax rate (%)")
+	fs.BoolVar(&in.NIIT, "niit", false, "owe the 3.8% net investment income tax")
+	fs.Float64Var(&in.MAGI, "magi", 0, "MAGI ($), to apply -niit only over the threshold")
+	fs.Float64Var(&in.NetInvestmentIncome, "nii", 0, "net investment income ($), with -magi")
+	fs.TextVar(&in.FilingStatus, "filing-status", Single, "single, married-joint, married-separate or head-of-household")
+	fs.BoolVar(&in.Itemize, "itemize", false, "itemize deductions")
+	fs.BoolVar(&in.AutoItemize, "auto-itemize", false, "itemize only if it beats -standard-deduction (instead of -itemize)")
+	fs.Float64Var(&in.StandardDeduction, "standard-deduction", 0, "standard deduction ($), with -auto-itemize")
+	fs.Float64Var(&in.OtherItemized, "other-itemized", 0, "itemized deductions ($) besides state and local tax, with -auto-itemize")
+	fs.Float64Var(&in.SALTCap, "salt-cap", 0, "cap ($) on the state and local tax deduction, with -auto-itemize")
+	fs.BoolVar(&in.StateRateIsEffective, "state-effective", false, "-state is already net of the federal deduction")
+	fs.BoolVar(&in.StateDeductsFederal, "state-deducts-fed", false, "the state lets federal tax be deducted")
+	percentVar(fs, &in.DeductionBenefitRate, "deduction-rate", 0, "federal rate (%) the state-tax deduction is worth, if not -fed")
+	fs.BoolVar(&in.AMT, "amt", false, "subject to AMT")
+	fs.IntVar(&in.AMTBracketIndex, "amt-bracket", 0, "AMT bracket index (0..4)")
+	percentVar(fs, &in.NatlAmTPct, "natl-amt-pct", 0, "AMT-affected portion (%) of national munis")
+	fs.Float64Var(&in.NatlInStateFraction, "natl-in-state", 0, "share (0..1) of national muni income from in-state bonds")
+	percentVar(fs, &in.StateAmTPct, "state-amt-pct", 0, "AMT-affected portion (%) of in-state munis")
+	return registerProfileFlags(fs)
+}
+
+// percentVar is fs.Float64Var, but also accepts "4.5%".
+func percentVar(fs *flag.FlagSet, p *float64, name string, value float64, usage string) {
+	*p = value
+	fs.Var((*percentValue)(p), name, usage)
+}
+
+func runMuniBreakeven(args []string, stdout io.Writer) error {
+	var in Inputs
+	fs := flag.NewFlagSet("muni-breakeven", flag.ContinueOnError)
+	var taxable float64
+	percentVar(fs, &taxable, "taxable", 5, "taxable yield to beat (%)")
+	profile := taxFlags(fs, &in)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if done, err := profile.resolve(fs, &in, stdout); done || err != nil {
+		return err
+	}
+
+	fmt.Fprintf(stdout, "To match %.3f%% fully taxable:\n", taxable)
+	fmt.Fprintf(stdout, "%-18s %6.3f%%\n", "Nat'l Tax-Exempt:", MuniBreakevenYield(taxable, in))
+	fmt.Fprintf(stdout, "%-18s %6.3f%%\n", "State Tax-Exempt:", InStateMuniBreakevenYield(taxable, in))
+	return nil
+}
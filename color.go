@@ -0,0 +1,77 @@
+package main
+
+import (
+	"io"
+	"math"
+	"os"
+	"strings"
+)
+
+const (
+	ansiGreen = "\x1b[32m"
+	ansiRed   = "\x1b[31m"
+	ansiReset = "\x1b[0m"
+)
+
+// colorText is res.Text with the best after-tax yield in green and any
+// negative ones in red. Lines of res.Text are res.Lines, in order, so each
+// line's after-tax figure is the first occurrence of its formatted value.
+func colorText(res Result, opts FormatOptions) string {
+	f := opts.formatter()
+	best := -1
+	for i, l := range res.Lines {
+		if math.IsNaN(l.AfterTax) {
+			continue // unknown, e.g. no fully taxable yield; never the best
+		}
+		if best < 0 || l.AfterTax > res.Lines[best].AfterTax {
+			best = i
+		}
+	}
+	lines := strings.Split(res.Text, "\n")
+	for i, l := range res.Lines {
+		if i >= len(lines) {
+			break
+		}
+		color := ""
+		switch {
+		case l.AfterTax < 0:
+			color = ansiRed
+		case i == best:
+			color = ansiGreen
+		}
+		if color == "" {
+			continue
+		}
+		v := strings.TrimSpace(f.pct(l.AfterTax))
+		lines[i] = strings.Replace(lines[i], v, color+v+ansiReset, 1)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// useColor decides whether to color output to w: never with noColor or
+// NO_COLOR set, always with force, otherwise only on a terminal.
+func useColor(w io.Writer, force, noColor bool) bool {
+	switch {
+	case noColor:
+		return false
+	case force:
+		return true
+	case os.Getenv("NO_COLOR") != "":
+		return false
+	}
+	f, ok := w.(*os.File)
+	if !ok {
+		return false
+	}
+	st, err := f.Stat()
+	return err == nil && st.Mode()&os.ModeCharDevice != 0
+}
+
+// computeText is Compute(in)'s text, colored if color is set.
+func computeText(in Inputs, color bool) string {
+	res := Compute(in)
+	if color {
+		return colorText(res, in.Format)
+	}
+	return res.Text
+}
@@ -0,0 +1,82 @@
+package main
+
+import (
+	"math"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestColorOutput(t *testing.T) {
+	run := func(args ...string) string {
+		t.Helper()
+		var out strings.Builder
+		if err := runRoot(args, &out); err != nil {
+			t.Fatal(err)
+		}
+		return out.String()
+	}
+
+	// AMT Free's 3.7% wins the example
+	if out := run("--color"); !strings.Contains(out, ansiGreen+"3.700%"+ansiReset) || strings.Contains(out, ansiRed) {
+		t.Errorf("--color: want just the AMT Free yield in green:\n%q", out)
+	}
+	for _, args := range [][]string{nil, {"--color", "--no-color"}} {
+		if out := run(args...); strings.Contains(out, "\x1b[") {
+			t.Errorf("%q: color codes in output that isn't a terminal:\n%q", args, out)
+		}
+	}
+	if plain := run(); strings.TrimSpace(plain) != strings.TrimSpace(Compute(exampleInputs()).Text) {
+		t.Errorf("uncolored output isn't Result.Text:\n%s", plain)
+	}
+
+	// a pipe isn't a terminal
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+	defer w.Close()
+	if useColor(w, false, false) {
+		t.Error("useColor on a pipe")
+	}
+	if !useColor(w, true, false) {
+		t.Error("no color on a pipe with force")
+	}
+	t.Setenv("NO_COLOR", "1")
+	if !useColor(w, true, false) || useColor(os.Stdout, false, false) {
+		t.Error("NO_COLOR should win over auto-detection but not --color")
+	}
+}
+
+func TestColorTextNegative(t *testing.T) {
+	// margin interest beyond the yield leaves the taxable lines negative
+	in := Inputs{FullyTaxable: 5, Treasury: 1, NatlTaxExempt: 3, FedBracket: 24, MarginFraction: 1, MarginInterestRate: 8}
+	res := Compute(in)
+	if res.FullyTaxableAfterTax >= 0 || res.TreasuryAfterTax >= 0 {
+		t.Fatalf("after tax %v and %v, want both negative", res.FullyTaxableAfterTax, res.TreasuryAfterTax)
+	}
+	lines := strings.Split(colorText(res, in.Format), "\n")
+	for i, want := range []string{ansiRed, ansiRed, ansiGreen, "", ""} {
+		colored := strings.Contains(lines[i], ansiRed) || strings.Contains(lines[i], ansiGreen)
+		if want == "" && colored || want != "" && !strings.Contains(lines[i], want) {
+			t.Errorf("line %d %q, want color %q", i, lines[i], want)
+		}
+	}
+}
+
+func TestColorTextNaN(t *testing.T) {
+	// no fully taxable yield: its NaN line is unknown, not the best
+	in := Inputs{FullyTaxable: math.NaN(), Treasury: 4, NatlTaxExempt: 3.5, FedBracket: 24}
+	res := Compute(in)
+	if !math.IsNaN(res.Lines[0].AfterTax) {
+		t.Fatalf("fully taxable after tax %v, want NaN", res.Lines[0].AfterTax)
+	}
+	lines := strings.Split(colorText(res, in.Format), "\n")
+	for i, want := range []string{"", "", ansiGreen, "", ""} {
+		colored := strings.Contains(lines[i], ansiRed) || strings.Contains(lines[i], ansiGreen)
+		if want == "" && colored || want != "" && !strings.Contains(lines[i], want) {
+			t.Errorf("line %d %q, want color %q", i, lines[i], want)
+		}
+	}
+}
@@ -0,0 +1,48 @@
+package main
+
+import "math"
+
+// Compounding is how often a quoted yield compounds. Non-annual quotes are
+// per-period rates, so 0.4 Monthly is 0.4% a month.
+type Compounding int
+
+const (
+	Annual Compounding = iota // the default; quotes are used as-is
+	SemiAnnual
+	Monthly
+)
+
+func (c Compounding) String() string {
+	switch c {
+	case Annual:
+		return "annual"
+	case SemiAnnual:
+		return "semiannual"
+	case Monthly:
+		return "monthly"
+	default:
+		return "unknown"
+	}
+}
+
+// periods is how many times a year c compounds.
+func (c Compounding) periods() int {
+	switch c {
+	case SemiAnnual:
+		return 2
+	case Monthly:
+		return 12
+	default:
+		return 1
+	}
+}
+
+// Annualize converts a per-period yield (%) to its effective annual yield:
+// (1 + y/100)^n - 1 for n periods a year. Annual yields come back unchanged.
+func Annualize(y float64, c Compounding) float64 {
+	n := c.periods()
+	if n == 1 {
+		return y
+	}
+	return 100 * (math.Pow(1+y/100, float64(n)) - 1)
+}
@@ -0,0 +1,41 @@
+package main
+
+import (
+	"math"
+	"testing"
+)
+
+func TestAnnualize(t *testing.T) {
+	for _, tt := range []struct {
+		y    float64
+		c    Compounding
+		want float64
+	}{
+		{5, Annual, 5},
+		{2, SemiAnnual, 4.04},
+		{0.4, Monthly, 4.907020},
+		{0, Monthly, 0},
+	} {
+		if got := Annualize(tt.y, tt.c); math.Abs(got-tt.want) > 1e-6 {
+			t.Errorf("Annualize(%v, %v) = %v, want %v", tt.y, tt.c, got, tt.want)
+		}
+	}
+}
+
+func TestCompoundingAfterTax(t *testing.T) {
+	in := Inputs{Treasury: 0.4, FullyTaxable: 5, FedBracket: 24, StateBracket: 5,
+		Compounding: map[InstrumentKind]Compounding{TreasuryKind: Monthly}}
+	res := Compute(in)
+	annual := Annualize(0.4, Monthly)
+	if !near(res.TreasuryAfterTax, annual*0.76) {
+		t.Errorf("0.4%% monthly Treasury nets %v, want %v", res.TreasuryAfterTax, annual*0.76)
+	}
+	// other lines keep their annual quotes
+	if !near(res.FullyTaxableAfterTax, 5*0.71) {
+		t.Errorf("fully taxable nets %v, want 3.55", res.FullyTaxableAfterTax)
+	}
+	in.Compounding = nil
+	if res := Compute(in); !near(res.TreasuryAfterTax, 0.4*0.76) {
+		t.Errorf("without compounding the Treasury nets %v, want %v", res.TreasuryAfterTax, 0.4*0.76)
+	}
+}
@@ -0,0 +1,159 @@
+package main
+
+import "time"
+
+// Logger receives one structured record per computation. It's an interface
+// rather than a logging package so callers can plug in whatever they use.
+type Logger interface {
+	Log(fields map[string]any)
+}
+
+// Yields are the per-scenario holdings a Computer compares: the yield fields
+// of Inputs and what describes each holding (AMT shares, issuer states,
+// per-kind quotes), and nothing about the taxpayer.
+type Yields struct {
+	FullyTaxable           float64
+	Treasury               float64
+	NatlTaxExempt          float64
+	NatlAmTPct             float64
+	NatlInStateFraction    float64
+	StateTaxExempt         float64
+	StateAmTPct            float64
+	AMTFree                float64
+	Corporate              float64
+	Agency                 float64
+	BondFund               float64
+	BondFundNAVDrift       float64
+	TaxFreeMMF             float64
+	TaxFreeMMFAMTPct       float64
+	TaxFreeMMFSingleState  bool
+	AuthorityBond          float64
+	AuthorityAmTPct        float64
+	AuthorityIssuerState   string
+	TaxableMuni            float64
+	TaxableMuniIssuerState string
+	TBillDiscount          float64
+	TBillDays              int
+	TotalReturn            TotalReturnInstrument
+	Balanced               BalancedFund
+	AMTFreeFunds           []NamedYield
+
+	// Per-kind quotes, as in Inputs
+	YieldToWorst    map[InstrumentKind]float64
+	YieldToMaturity map[InstrumentKind]float64
+	CreditSpread    map[InstrumentKind]float64
+	Compounding     map[InstrumentKind]Compounding
+}
+
+// Yields is in's yields and holdings.
+func (in Inputs) Yields() Yields {
+	return Yields{
+		FullyTaxable:           in.FullyTaxable,
+		Treasury:               in.Treasury,
+		NatlTaxExempt:          in.NatlTaxExempt,
+		NatlAmTPct:             in.NatlAmTPct,
+		NatlInStateFraction:    in.NatlInStateFraction,
+		StateTaxExempt:         in.StateTaxExempt,
+		StateAmTPct:            in.StateAmTPct,
+		AMTFree:                in.AMTFree,
+		Corporate:              in.Corporate,
+		Agency:                 in.Agency,
+		BondFund:               in.BondFund,
+		BondFundNAVDrift:       in.BondFundNAVDrift,
+		TaxFreeMMF:             in.TaxFreeMMF,
+		TaxFreeMMFAMTPct:       in.TaxFreeMMFAMTPct,
+		TaxFreeMMFSingleState:  in.TaxFreeMMFSingleState,
+		AuthorityBond:          in.AuthorityBond,
+		AuthorityAmTPct:        in.AuthorityAmTPct,
+		AuthorityIssuerState:   in.AuthorityIssuerState,
+		TaxableMuni:            in.TaxableMuni,
+		TaxableMuniIssuerState: in.TaxableMuniIssuerState,
+		TBillDiscount:          in.TBillDiscount,
+		TBillDays:              in.TBillDays,
+		TotalReturn:            in.TotalReturn,
+		Balanced:               in.Balanced,
+		AMTFreeFunds:           in.AMTFreeFunds,
+		YieldToWorst:           in.YieldToWorst,
+		YieldToMaturity:        in.YieldToMaturity,
+		CreditSpread:           in.CreditSpread,
+		Compounding:            in.Compounding,
+	}
+}
+
+// WithYields is in with its Yields fields replaced by y's.
+func (in Inputs) WithYields(y Yields) Inputs {
+	in.FullyTaxable = y.FullyTaxable
+	in.Treasury = y.Treasury
+	in.NatlTaxExempt = y.NatlTaxExempt
+	in.NatlAmTPct = y.NatlAmTPct
+	in.NatlInStateFraction = y.NatlInStateFraction
+	in.StateTaxExempt = y.StateTaxExempt
+	in.StateAmTPct = y.StateAmTPct
+	in.AMTFree = y.AMTFree
+	in.Corporate = y.Corporate
+	in.Agency = y.Agency
+	in.BondFund = y.BondFund
+	in.BondFundNAVDrift = y.BondFundNAVDrift
+	in.TaxFreeMMF = y.TaxFreeMMF
+	in.TaxFreeMMFAMTPct = y.TaxFreeMMFAMTPct
+	in.TaxFreeMMFSingleState = y.TaxFreeMMFSingleState
+	in.AuthorityBond = y.AuthorityBond
+	in.AuthorityAmTPct = y.AuthorityAmTPct
+	in.AuthorityIssuerState = y.AuthorityIssuerState
+	in.TaxableMuni = y.TaxableMuni
+	in.TaxableMuniIssuerState = y.TaxableMuniIssuerState
+	in.TBillDiscount = y.TBillDiscount
+	in.TBillDays = y.TBillDays
+	in.TotalReturn = y.TotalReturn
+	in.Balanced = y.Balanced
+	in.AMTFreeFunds = y.AMTFreeFunds
+	in.YieldToWorst = y.YieldToWorst
+	in.YieldToMaturity = y.YieldToMaturity
+	in.CreditSpread = y.CreditSpread
+	in.Compounding = y.Compounding
+	return in
+}
+
+// Computer holds who the taxpayer is (brackets, state, year's rates, feature
+// flags, format options) so one can be reused across many sets of Yields.
+// The zero value is ready to use, with zero tax settings.
+type Computer struct {
+	// Tax settings; its Yields fields are ignored, Compute's Yields are
+	// used instead
+	Tax Inputs
+
+	Logger Logger // nil logs nothing
+}
+
+// Compute is Compute for y under c's tax settings.
+func (c *Computer) Compute(y Yields) Result {
+	return c.ComputeInputs(c.Tax.WithYields(y))
+}
+
+// ComputeInputs is Compute(in), ignoring c.Tax, logged to c.Logger with a
+// summary of in and the result and how long it took. Without a Logger it's
+// just Compute.
+func (c *Computer) ComputeInputs(in Inputs) Result {
+	if c.Logger == nil {
+		return ComputeInstruments(in.ReportedInstruments(), in)
+	}
+	start := time.Now()
+	res := ComputeInstruments(in.ReportedInstruments(), in)
+	elapsed := time.Since(start)
+
+	fields := map[string]any{
+		"fingerprint":   in.Fingerprint(),
+		"fed_bracket":   in.FedBracket,
+		"state_bracket": in.StateBracket,
+		"itemize":       in.Itemize,
+		"amt":           in.AMT,
+		"lines":         len(res.Lines),
+		"duration":      elapsed,
+	}
+	if ranked := res.RankByAfterTax(); len(ranked) > 0 {
+		fields["best"] = instrumentKeys[ranked[0].Kind]
+		fields["best_after_tax"] = ranked[0].AfterTax
+	}
+	c.Logger.Log(fields)
+	return res
+}
@@ -0,0 +1,121 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+// captureLogger records every Log call.
+type captureLogger struct {
+	records []map[string]any
+}
+
+func (l *captureLogger) Log(fields map[string]any) { l.records = append(l.records, fields) }
+
+func TestComputerLogger(t *testing.T) {
+	log := &captureLogger{}
+	c := Computer{Tax: exampleInputs(), Logger: log}
+	quotes := []Yields{
+		exampleInputs().Yields(),
+		{FullyTaxable: 6, NatlTaxExempt: 3},
+		{Treasury: 4.5},
+	}
+	for _, y := range quotes {
+		c.Compute(y)
+	}
+	if len(log.records) != len(quotes) {
+		t.Fatalf("%d records for %d computations", len(log.records), len(quotes))
+	}
+
+	first := log.records[0]
+	for k, want := range map[string]any{
+		"fingerprint":    exampleInputs().Fingerprint(),
+		"fed_bracket":    24.0,
+		"state_bracket":  9.3,
+		"itemize":        true,
+		"amt":            false,
+		"lines":          5,
+		"best":           "amt_free",
+		"best_after_tax": 3.7,
+	} {
+		if first[k] != want {
+			t.Errorf("record field %s = %v (%T), want %v", k, first[k], first[k], want)
+		}
+	}
+	if d, ok := first["duration"].(time.Duration); !ok || d < 0 {
+		t.Errorf("duration %v", first["duration"])
+	}
+	if log.records[1]["best"] != "fully_taxable" || log.records[2]["best"] != "treasury" {
+		t.Errorf("best lines %v, %v", log.records[1]["best"], log.records[2]["best"])
+	}
+
+	// logging doesn't change the result, and no Logger logs nothing
+	silent := Computer{Tax: exampleInputs()}
+	for _, y := range quotes {
+		if got, want := c.Compute(y), silent.Compute(y); got.Text != want.Text {
+			t.Errorf("%+v: logged result differs:\n%s\nwant\n%s", y, got.Text, want.Text)
+		}
+	}
+}
+
+func TestComputerReuse(t *testing.T) {
+	tax := exampleInputs()
+	c := Computer{Tax: tax}
+	for _, y := range []Yields{
+		{FullyTaxable: 5, NatlTaxExempt: 3.5},
+		{FullyTaxable: 4.2, Treasury: 4, StateTaxExempt: 3},
+		{Corporate: 6, AMTFree: 3.9, Balanced: BalancedFund{Yield: 4, OrdinaryFraction: 0.6, QualifiedFraction: 0.4}},
+		tax.Yields(),
+		{},
+	} {
+		got, want := c.Compute(y), Compute(tax.WithYields(y))
+		if len(got.Lines) != len(want.Lines) {
+			t.Fatalf("%+v: Computer gives %d lines, Compute %d", y, len(got.Lines), len(want.Lines))
+		}
+		for i, l := range want.Lines {
+			g := got.Lines[i]
+			if g.Kind != l.Kind || g.AfterTax != l.AfterTax || g.TEY != l.TEY || g.EffectiveTaxRate != l.EffectiveTaxRate {
+				t.Errorf("%+v: Computer gives %+v, Compute %+v", y, g, l)
+			}
+		}
+	}
+	if !reflect.DeepEqual(c.Tax, tax) {
+		t.Error("Compute changed the Computer's settings")
+	}
+
+	// only the Yields count, not any left in Tax
+	res := c.Compute(Yields{FullyTaxable: 5})
+	if !near(res.FullyTaxableAfterTax, 5*(1-0.24-0.093*0.76)) || res.NatlAfterTax != 0 || res.AMTFreeAfterTax != 0 {
+		t.Errorf("one yield gives %+v", res.Lines)
+	}
+	if !reflect.DeepEqual(tax.WithYields(tax.Yields()), tax) {
+		t.Error("WithYields(Yields()) doesn't round-trip")
+	}
+}
+
+func TestComputerPerHoldingYields(t *testing.T) {
+	// AMT shares and per-kind quotes belong to the holdings, so two yield
+	// sets can differ in them under one Computer
+	tax := Inputs{FedBracket: 24, AMT: true, NatlAmTPct: 50} // Compute's Yields replace the 50
+	c := Computer{Tax: tax}
+	plain := Yields{FullyTaxable: 5, NatlTaxExempt: 3.8}
+	amt := Yields{FullyTaxable: 5, NatlTaxExempt: 3.8, NatlAmTPct: 20,
+		CreditSpread: map[InstrumentKind]float64{FullyTaxableKind: 0.5}}
+
+	a, b := c.Compute(plain), c.Compute(amt)
+	if !near(a.NatlAfterTax, 3.8) {
+		t.Errorf("no AMT share: muni nets %v, want 3.8", a.NatlAfterTax)
+	}
+	if !near(b.NatlAfterTax, 3.8*(1-0.2*0.26)) {
+		t.Errorf("20%% AMT share: muni nets %v, want %v", b.NatlAfterTax, 3.8*(1-0.2*0.26))
+	}
+	if !near(b.FullyTaxableAfterTax, a.FullyTaxableAfterTax*4.5/5) {
+		t.Errorf("50 bps credit spread: taxable nets %v, want %v", b.FullyTaxableAfterTax, a.FullyTaxableAfterTax*4.5/5)
+	}
+	for _, y := range []Yields{plain, amt} {
+		if got, want := c.Compute(y), Compute(tax.WithYields(y)); got.Text != want.Text {
+			t.Errorf("%+v: Computer gives\n%s\nCompute\n%s", y, got.Text, want.Text)
+		}
+	}
+}
@@ -0,0 +1,76 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"time"
+)
+
+// LoadConfig reads Inputs from a JSON file (the same shape /compute takes).
+func LoadConfig(path string) (Inputs, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return Inputs{}, err
+	}
+	var in Inputs
+	if err := json.Unmarshal(b, &in); err != nil {
+		return Inputs{}, fmt.Errorf("%s: %w", path, err)
+	}
+	return in, nil
+}
+
+// printConfig loads path and prints its Result, or the error.
+func printConfig(w io.Writer, path string, color bool) {
+	in, err := LoadConfig(path)
+	if err != nil {
+		fmt.Fprintln(w, "error:", err)
+		return
+	}
+	fmt.Fprintln(w, computeText(in, color))
+}
+
+// watchConfig prints path's Result now and again whenever the file changes,
+// until ctx is done. It polls every interval and waits for the file to sit
+// unchanged for debounce before reloading, so a burst of saves prints once.
+// Bad configs print an error and watching carries on.
+func watchConfig(ctx context.Context, w io.Writer, path string, interval, debounce time.Duration, color bool) error {
+	type stamp struct {
+		mod  time.Time
+		size int64
+	}
+	current := func() stamp {
+		fi, err := os.Stat(path)
+		if err != nil {
+			return stamp{}
+		}
+		return stamp{fi.ModTime(), fi.Size()}
+	}
+
+	printConfig(w, path, color)
+	last := current()
+	var pending stamp
+	var pendingSince time.Time
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case now := <-ticker.C:
+			s := current()
+			switch {
+			case s == last:
+				pendingSince = time.Time{}
+			case s != pending || pendingSince.IsZero():
+				pending, pendingSince = s, now
+			case now.Sub(pendingSince) >= debounce:
+				last, pendingSince = s, time.Time{}
+				fmt.Fprintln(w)
+				printConfig(w, path, color)
+			}
+		}
+	}
+}
@@ -0,0 +1,82 @@
+package main
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// syncBuffer is a strings.Builder safe to read while another goroutine
+// writes.
+type syncBuffer struct {
+	mu sync.Mutex
+	b  strings.Builder
+}
+
+func (s *syncBuffer) Write(p []byte) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.b.Write(p)
+}
+
+func (s *syncBuffer) String() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.b.String()
+}
+
+func TestWatchConfig(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "in.json")
+	write := func(s string) {
+		t.Helper()
+		if err := os.WriteFile(path, []byte(s), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// waitFor waits until out has n results and errors in all.
+	var out syncBuffer
+	waitFor := func(n int) {
+		t.Helper()
+		deadline := time.Now().Add(5 * time.Second)
+		for strings.Count(out.String(), "AMT Free:")+strings.Count(out.String(), "error:") < n {
+			if time.Now().After(deadline) {
+				t.Fatalf("waiting for %d outputs, got:\n%s", n, out.String())
+			}
+			time.Sleep(5 * time.Millisecond)
+		}
+	}
+
+	write(`{"FullyTaxable": 5, "AMTFree": 3.7, "FedBracket": 24}`)
+	ctx, stop := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- watchConfig(ctx, &out, path, 5*time.Millisecond, 20*time.Millisecond, false) }()
+
+	waitFor(1)
+	write(`{"FullyTaxable": 5, "AMTFree": 3.7, "FedBracket": 32.5}`)
+	waitFor(2)
+	write(`{"FullyTaxable": 5, "AMTFree": 3.7, "FedBracket": 35.25}`)
+	waitFor(3)
+	// a bad config prints an error and watching carries on
+	write(`{"FedBracket": `)
+	waitFor(4)
+	write(`{"FullyTaxable": 6, "AMTFree": 3.7, "FedBracket": 24}`)
+	waitFor(5)
+	stop()
+	if err := <-done; err != nil {
+		t.Errorf("watchConfig: %v", err)
+	}
+
+	got := out.String()
+	if n := strings.Count(got, "AMT Free:"); n != 4 {
+		t.Errorf("%d computations, want the initial one and 3 reloads:\n%s", n, got)
+	}
+	for _, want := range []string{"3.800% after tax", "3.375% after tax", "3.237% after tax", "error:", "4.560% after tax"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("output has no %q:\n%s", want, got)
+		}
+	}
+}
@@ -0,0 +1,218 @@
+package main
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// CSVFieldMap maps a spreadsheet's own column headers ("Coupon", "Fed %") to
+// the Inputs fields they hold ("FullyTaxable", "FedBracket").
+type CSVFieldMap map[string]string
+
+// ReadInputsCSV reads one Inputs per row. The header row names Inputs fields
+// (FullyTaxable, FedBracket, Itemize, ...), directly or through fieldMap,
+// which may be nil; only number, bool and int fields can be set this way.
+// Unset fields, and empty cells, keep their zero value, except FullyTaxable,
+// which is NaN (unknown) as in JSON. Numbers go through ParsePercent, so
+// "4.5%" is fine. Errors give the CSV line number.
+func ReadInputsCSV(r io.Reader, fieldMap CSVFieldMap) ([]Inputs, error) {
+	cr := csv.NewReader(r)
+	cr.TrimLeadingSpace = true
+	header, err := cr.Read()
+	if err == io.EOF {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	fields := make([]reflect.StructField, len(header))
+	for i, name := range header {
+		name = strings.TrimSpace(name)
+		field, mapped := fieldMap[name]
+		if !mapped {
+			field = name
+		}
+		f, ok := reflect.TypeOf(Inputs{}).FieldByName(field)
+		switch {
+		case mapped && (!ok || !csvSettable(f.Type)):
+			return nil, fmt.Errorf("line 1: column %q maps to %q, which isn't a number, bool or int Inputs field", name, field)
+		case !ok || !csvSettable(f.Type):
+			return nil, fmt.Errorf("line 1: unknown column %q (not an Inputs field, and not in the field map)", name)
+		}
+		fields[i] = f
+	}
+
+	var out []Inputs
+	for {
+		rec, err := cr.Read()
+		if err == io.EOF {
+			return out, nil
+		}
+		if err != nil {
+			return nil, err // csv.ParseError already has the line
+		}
+		line, _ := cr.FieldPos(0)
+		in := Inputs{FullyTaxable: math.NaN()}
+		for i, cell := range rec {
+			if err := setCSVField(reflect.ValueOf(&in).Elem().FieldByIndex(fields[i].Index), cell); err != nil {
+				return nil, fmt.Errorf("line %d: column %s: %w", line, fields[i].Name, err)
+			}
+		}
+		out = append(out, in)
+	}
+}
+
+func csvSettable(t reflect.Type) bool {
+	switch t.Kind() {
+	case reflect.Float64, reflect.Bool, reflect.Int:
+		return true
+	}
+	return false
+}
+
+func setCSVField(v reflect.Value, cell string) error {
+	switch v.Kind() {
+	case reflect.Float64:
+		if strings.TrimSpace(cell) == "" {
+			return nil // not NaN: a blank bracket is 0, and FullyTaxable is already NaN
+		}
+		f, err := ParsePercent(cell)
+		if err != nil {
+			return err
+		}
+		v.SetFloat(f)
+	case reflect.Bool:
+		if strings.TrimSpace(cell) == "" {
+			return nil
+		}
+		b, err := strconv.ParseBool(strings.TrimSpace(cell))
+		if err != nil {
+			return errors.New("invalid boolean " + strconv.Quote(cell))
+		}
+		v.SetBool(b)
+	case reflect.Int:
+		if strings.TrimSpace(cell) == "" {
+			return nil
+		}
+		n, err := strconv.Atoi(strings.TrimSpace(cell))
+		if err != nil {
+			return errors.New("invalid integer " + strconv.Quote(cell))
+		}
+		v.SetInt(int64(n))
+	}
+	return nil
+}
+
+// resultColumn is a per-instrument number in results CSVs.
+type resultColumn struct {
+	suffix string
+	value  func(ResultLine) float64
+}
+
+// resultColumns are the columns results CSVs always have, in order.
+var resultColumns = []resultColumn{
+	{"after_tax", func(l ResultLine) float64 { return l.AfterTax }},
+	{"tey", func(l ResultLine) float64 { return l.TEY }},
+}
+
+// spreadColumn is added with Format.ShowSpread.
+var spreadColumn = resultColumn{"tey_spread_bps", func(l ResultLine) float64 { return l.TEYSpreadBps }}
+
+// bothTEYColumns are added with Format.ShowBothTEY, the treasury one only if
+// some result has a treasury yield.
+var bothTEYColumns = []resultColumn{
+	{"tey_vs_fully_taxable", func(l ResultLine) float64 { return l.TEYVsFullyTaxable }},
+	{"tey_vs_treasury", func(l ResultLine) float64 { return l.TEYVsTreasury }},
+}
+
+// WriteResultsCSV writes one row per scenario: its tax settings, then
+// <instrument>_after_tax and <instrument>_tey for each standard instrument
+// (e.g. treasury_after_tax), then gross_up. Numbers are plain, without %, and an
+// instrument that wasn't enabled (or a NaN) is an empty cell. If any input
+// has Format.ShowSpread, every instrument also gets <instrument>_tey_spread_bps,
+// and with Format.ShowBothTEY, <instrument>_tey_vs_fully_taxable and (given a
+// treasury yield) <instrument>_tey_vs_treasury.
+func WriteResultsCSV(w io.Writer, inputs []Inputs, results []Result) error {
+	return writeResults(csv.NewWriter(w), inputs, results, strconv.FormatBool)
+}
+
+// WriteResultsTSV is WriteResultsCSV's layout, tab-separated for pasting or
+// importing into a spreadsheet like Google Sheets: the same header row and
+// columns, with booleans as TRUE and FALSE so they import as booleans.
+// Every yield and rate (brackets included) is a bare number of percentage
+// points, 4.5 for 4.5%, so format those columns as numbers rather than
+// percents; gross_up is a plain multiplier.
+func WriteResultsTSV(w io.Writer, inputs []Inputs, results []Result) error {
+	cw := csv.NewWriter(w)
+	cw.Comma = '\t'
+	return writeResults(cw, inputs, results, func(b bool) string { return strings.ToUpper(strconv.FormatBool(b)) })
+}
+
+func writeResults(cw *csv.Writer, inputs []Inputs, results []Result, formatBool func(bool) string) error {
+	if len(inputs) != len(results) {
+		return fmt.Errorf("%d inputs but %d results", len(inputs), len(results))
+	}
+	columns := resultColumns
+	if slices.ContainsFunc(inputs, func(in Inputs) bool { return in.Format.ShowSpread }) {
+		columns = append(slices.Clip(columns), spreadColumn)
+	}
+	if slices.ContainsFunc(inputs, func(in Inputs) bool { return in.Format.ShowBothTEY }) {
+		columns = append(slices.Clip(columns), bothTEYColumns[0])
+		if slices.ContainsFunc(results, func(r Result) bool { return r.render.showTreasuryTEY }) {
+			columns = append(columns, bothTEYColumns[1])
+		}
+	}
+	if err := cw.Write(resultsHeader(columns)); err != nil {
+		return err
+	}
+	for i := range results {
+		if err := cw.Write(resultsRecord(inputs[i], results[i], columns, formatBool)); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+func resultsHeader(columns []resultColumn) []string {
+	h := []string{"FedBracket", "StateBracket", "Itemize", "AMT"}
+	for _, k := range standardKinds() {
+		for _, c := range columns {
+			h = append(h, instrumentKeys[k]+"_"+c.suffix)
+		}
+	}
+	return append(h, "gross_up")
+}
+
+func resultsRecord(in Inputs, res Result, columns []resultColumn, formatBool func(bool) string) []string {
+	rec := []string{
+		formatCSVFloat(in.FedBracket), formatCSVFloat(in.StateBracket),
+		formatBool(in.Itemize), formatBool(in.AMT),
+	}
+	for _, k := range standardKinds() {
+		l, ok := res.Line(k)
+		for _, c := range columns {
+			if !ok {
+				rec = append(rec, "")
+				continue
+			}
+			rec = append(rec, formatCSVFloat(c.value(l)))
+		}
+	}
+	return append(rec, formatCSVFloat(res.GrossUp))
+}
+
+func formatCSVFloat(f float64) string {
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		return ""
+	}
+	return strconv.FormatFloat(f, 'f', -1, 64)
+}
@@ -0,0 +1,144 @@
+package main
+
+import (
+	"encoding/csv"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestWriteResultsTSV(t *testing.T) {
+	a := exampleInputs()
+	b := Inputs{FedBracket: 32, StateBracket: 5, FullyTaxable: 5, NatlTaxExempt: 3.5}
+	var out strings.Builder
+	if err := WriteResultsTSV(&out, []Inputs{a, b}, []Result{Compute(a), Compute(b)}); err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(out.String(), "%") {
+		t.Errorf("percent sign in TSV:\n%s", out.String())
+	}
+	r := csv.NewReader(strings.NewReader(out.String()))
+	r.Comma = '\t'
+	rows, err := r.ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rows) != 3 {
+		t.Fatalf("%d rows, want a header and 2", len(rows))
+	}
+	cell := func(row int, name string) string {
+		t.Helper()
+		i := slices.Index(rows[0], name)
+		if i < 0 {
+			t.Fatalf("no %s column in %q", name, rows[0])
+		}
+		return rows[row][i]
+	}
+	number := func(row int, name string) float64 {
+		t.Helper()
+		v, err := strconv.ParseFloat(cell(row, name), 64)
+		if err != nil {
+			t.Fatalf("row %d %s: %v", row, name, err)
+		}
+		return v
+	}
+
+	if got := number(1, "fully_taxable_after_tax"); !near(got, 3.4466) {
+		t.Errorf("fully taxable after tax %v, want 3.4466", got)
+	}
+	if got := number(1, "FedBracket"); got != 24 {
+		t.Errorf("FedBracket %v, want 24", got)
+	}
+	if got := number(2, "natl_after_tax"); !near(got, 3.5*0.95) {
+		t.Errorf("row 2 natl after tax %v, want %v", got, 3.5*0.95)
+	}
+	if got, want := number(2, "natl_tey"), Compute(b).Lines[2].TEY; !near(got, want) {
+		t.Errorf("row 2 natl TEY %v, want %v", got, want)
+	}
+	if cell(1, "Itemize") != "TRUE" || cell(2, "Itemize") != "FALSE" {
+		t.Errorf("Itemize cells %q, %q: want TRUE, FALSE", cell(1, "Itemize"), cell(2, "Itemize"))
+	}
+
+	// the same layout as the CSV, bar the separator and booleans
+	var csvOut strings.Builder
+	if err := WriteResultsCSV(&csvOut, []Inputs{a, b}, []Result{Compute(a), Compute(b)}); err != nil {
+		t.Fatal(err)
+	}
+	csvRows, err := csv.NewReader(strings.NewReader(csvOut.String())).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(csvRows[0], rows[0]) {
+		t.Errorf("TSV header %q, CSV %q", rows[0], csvRows[0])
+	}
+	if err := WriteResultsTSV(&out, []Inputs{a}, nil); err == nil {
+		t.Error("no error for mismatched inputs and results")
+	}
+}
+
+func TestReadInputsCSVFieldMap(t *testing.T) {
+	fieldMap := CSVFieldMap{"Coupon": "FullyTaxable", "Fed %": "FedBracket", "State %": "StateBracket", "Itemize?": "Itemize"}
+	sheet := "Coupon,Fed %,State %,Itemize?,NatlTaxExempt\n5%,24,9.3,true,3.8\n4.5,32%,0,false,3.2\n"
+	got, err := ReadInputsCSV(strings.NewReader(sheet), fieldMap)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []Inputs{
+		{FullyTaxable: 5, FedBracket: 24, StateBracket: 9.3, Itemize: true, NatlTaxExempt: 3.8},
+		{FullyTaxable: 4.5, FedBracket: 32, NatlTaxExempt: 3.2},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("%d rows, want %d", len(got), len(want))
+	}
+	for i := range want {
+		g := got[i]
+		if g.FullyTaxable != want[i].FullyTaxable || g.FedBracket != want[i].FedBracket || g.StateBracket != want[i].StateBracket ||
+			g.Itemize != want[i].Itemize || g.NatlTaxExempt != want[i].NatlTaxExempt {
+			t.Errorf("row %d: %+v", i+1, g)
+		}
+	}
+	if res := Compute(got[0]); !near(res.FullyTaxableAfterTax, 3.4466) || !near(res.NatlAfterTax, 3.4466) {
+		t.Errorf("the mapped example nets %v, %v", res.FullyTaxableAfterTax, res.NatlAfterTax)
+	}
+
+	// without a map, field names still work and FullyTaxable stays unknown
+	got, err = ReadInputsCSV(strings.NewReader("FedBracket,Treasury\n24,4.5\n"), nil)
+	if err != nil || len(got) != 1 || got[0].FedBracket != 24 || got[0].Treasury != 4.5 || !math.IsNaN(got[0].FullyTaxable) {
+		t.Errorf("unmapped: %+v, %v", got, err)
+	}
+
+	for _, tt := range []struct {
+		name, sheet, err string
+	}{
+		{"unknown column", "Coupon,Yield to worst\n5,4.9\n", `unknown column "Yield to worst"`},
+		{"mapped to no field", "Coupon,Muni\n5,3\n", `column "Muni" maps to "NatlMuni"`},
+		{"mapped to a string field", "Coupon,Home\n5,CA\n", `column "Home" maps to "ResidentState"`},
+		{"bad cell", "Coupon,Fed %\n5,24\n5,high\n", "line 3: column FedBracket"},
+	} {
+		fieldMap := CSVFieldMap{"Coupon": "FullyTaxable", "Fed %": "FedBracket", "Muni": "NatlMuni", "Home": "ResidentState"}
+		_, err := ReadInputsCSV(strings.NewReader(tt.sheet), fieldMap)
+		if err == nil || !strings.Contains(err.Error(), tt.err) {
+			t.Errorf("%s: error %v, want one containing %q", tt.name, err, tt.err)
+		}
+	}
+}
+
+func TestReadInputsCSVEmptyCells(t *testing.T) {
+	got, err := ReadInputsCSV(strings.NewReader("FullyTaxable,FedBracket,StateBracket,NatlTaxExempt\n5,24,,3.8\n,24,9.3,3.8\n"), nil)
+	if err != nil || len(got) != 2 {
+		t.Fatalf("%+v, %v", got, err)
+	}
+	// an empty bracket is no tax, not NaN
+	if got[0].StateBracket != 0 {
+		t.Errorf("empty StateBracket read as %v, want 0", got[0].StateBracket)
+	}
+	if res := Compute(got[0]); !near(res.FullyTaxableAfterTax, 3.8) || !near(res.NatlAfterTax, 3.8) {
+		t.Errorf("row 1 nets %v, %v; want 3.8, 3.8", res.FullyTaxableAfterTax, res.NatlAfterTax)
+	}
+	// an empty FullyTaxable is unknown, as when the column is missing
+	if !math.IsNaN(got[1].FullyTaxable) {
+		t.Errorf("empty FullyTaxable read as %v, want NaN", got[1].FullyTaxable)
+	}
+}
@@ -0,0 +1,85 @@
+package main
+
+import (
+	"strconv"
+
+	"github.com/shopspring/decimal"
+)
+
+// exactBreakdown redoes b's composition (taxBreakdownAt's, then
+// taxRule.breakdown's in-state share) in decimal arithmetic, for
+// Inputs.UseDecimal. Each rate and the yield are read at their shortest
+// decimal form, so a quoted 9.3% is exactly 9.3, and only the results are
+// rounded back to float64. The one division, the state-deducts-federal
+// solve, is to divPlaces places; everything else is exact. b comes back
+// unchanged if anything is NaN or infinite, or that solve would divide by
+// zero.
+func exactBreakdown(b Breakdown, fedTaxable, stateTaxable bool, stateShare float64, in Inputs) Breakdown {
+	yield, ok1 := exactDecimal(b.Yield)
+	fed, ok2 := exactDecimal(b.FedRate)
+	state, ok3 := exactDecimal(b.StateRate)
+	deduction, ok4 := exactDecimal(b.DeductionRate)
+	amtPct, ok5 := exactDecimal(b.AMTPct)
+	niit, ok6 := exactDecimal(b.NIIT)
+	share, ok7 := exactDecimal(stateShare)
+	if !(ok1 && ok2 && ok3 && ok4 && ok5 && ok6 && ok7) {
+		return b
+	}
+	one := decimal.NewFromInt(1)
+	pct := func(x decimal.Decimal) decimal.Decimal { return x.Shift(-2) }
+
+	fedTax := decimal.Zero
+	switch {
+	case fedTaxable:
+		fedTax = fed
+	case b.AMT:
+		fedTax = pct(amtPct).Mul(fed)
+	}
+
+	stateTax, credit, stateDeduction := decimal.Zero, decimal.Zero, decimal.Zero
+	if stateTaxable {
+		stateTax = state
+		paid := state
+		if in.StateDeductsFederal {
+			den := one.Sub(pct(state).Mul(pct(deduction)))
+			if den.IsZero() {
+				return b
+			}
+			paid = state.Mul(one.Sub(pct(fedTax))).DivRound(den, divPlaces)
+		}
+		credit = pct(paid).Mul(deduction)
+		if credit.GreaterThan(fedTax) {
+			credit = fedTax
+			paid = state
+		}
+		if in.StateDeductsFederal {
+			stateDeduction = state.Sub(paid)
+		}
+		if stateShare != 1 {
+			stateTax = stateTax.Mul(share)
+			credit = credit.Mul(share)
+			stateDeduction = stateDeduction.Mul(share)
+		}
+	}
+
+	total := fedTax.Add(stateTax).Sub(credit).Sub(stateDeduction).Add(niit)
+	afterTax := yield.Mul(one.Sub(pct(total)))
+
+	b.FedTax = fedTax.InexactFloat64()
+	b.StateTax = stateTax.InexactFloat64()
+	b.DeductionCredit = credit.InexactFloat64()
+	b.StateDeduction = stateDeduction.InexactFloat64()
+	b.TotalTax = total.InexactFloat64()
+	b.AfterTax = afterTax.InexactFloat64()
+	return b
+}
+
+// divPlaces is how many decimal places exactBreakdown divides to, far past
+// float64's 17 significant digits.
+const divPlaces = 40
+
+// exactDecimal is f at its shortest decimal form.
+func exactDecimal(f float64) (decimal.Decimal, bool) {
+	d, err := decimal.NewFromString(strconv.FormatFloat(f, 'g', -1, 64))
+	return d, err == nil
+}
@@ -0,0 +1,54 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"testing"
+)
+
+func TestUseDecimal(t *testing.T) {
+	// 0.3 * (1 - 0.35 - 0.0685 * (1 - 0.35)) is 0.1816425 exactly; floats
+	// land a hair below it
+	in := Inputs{FullyTaxable: 0.3, NatlTaxExempt: 0.7, FedBracket: 35, StateBracket: 6.85, Itemize: true}
+	float := Compute(in)
+	in.UseDecimal = true
+	exact := Compute(in)
+
+	if float.FullyTaxableAfterTax == 0.1816425 {
+		t.Skip("no float artifact to avoid here; fused multiply-adds, say")
+	}
+	if exact.FullyTaxableAfterTax != 0.1816425 {
+		t.Errorf("decimal fully taxable nets %v, want 0.1816425", exact.FullyTaxableAfterTax)
+	}
+	if exact.NatlAfterTax != 0.65205 {
+		t.Errorf("decimal muni nets %v, want 0.65205", exact.NatlAfterTax)
+	}
+	if got := composeTax(in, true, true, 0); got != 39.4525 {
+		t.Errorf("decimal tax %v%%, want 39.4525%%", got)
+	}
+
+	// the paths agree to everything displayed
+	for i, l := range float.Lines {
+		e := exact.Lines[i]
+		if !near(l.AfterTax, e.AfterTax) || fmt.Sprintf("%.3f", l.AfterTax) != fmt.Sprintf("%.3f", e.AfterTax) {
+			t.Errorf("%s: float %v, decimal %v", l.Label, l.AfterTax, e.AfterTax)
+		}
+	}
+	if float.Text != exact.Text {
+		t.Errorf("float text\n%s\ndecimal text\n%s", float.Text, exact.Text)
+	}
+
+	// the state-deducts-federal solve divides, to divPlaces places
+	in.StateDeductsFederal = true
+	if got, want := composeTax(in, true, true, 0), func() float64 {
+		in.UseDecimal = false
+		return composeTax(in, true, true, 0)
+	}(); !near(got, want) {
+		t.Errorf("decimal tax deducting federal %v%%, float %v%%", got, want)
+	}
+
+	// NaN isn't a decimal, so it's left to the float path
+	if b := exactBreakdown(Breakdown{Yield: math.NaN()}, true, true, 1, in); !math.IsNaN(b.Yield) {
+		t.Errorf("NaN yield came back %v", b.Yield)
+	}
+}
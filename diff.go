@@ -0,0 +1,55 @@
+package main
+
+import "math"
+
+// LineDiff compares one line of two Results. Lines are matched by key, as
+// in Result.ToMap; a line found in only one Result has a zero ResultLine on
+// the other side and NaN deltas.
+type LineDiff struct {
+	Key   string
+	Label string
+	A, B  ResultLine
+
+	AfterTax float64 // B.AfterTax - A.AfterTax
+	TEY      float64 // B.TEY - A.TEY
+}
+
+// Diff lines up a and b's lines, in a's order with lines only b has after.
+func Diff(a, b Result) []LineDiff {
+	bKeys := lineKeys(b.Lines)
+	bIndex := map[string]int{}
+	for i, k := range bKeys {
+		bIndex[k] = i
+	}
+
+	var diffs []LineDiff
+	seen := map[string]bool{}
+	for i, k := range lineKeys(a.Lines) {
+		seen[k] = true
+		d := LineDiff{Key: k, Label: a.Lines[i].Label, A: a.Lines[i], AfterTax: math.NaN(), TEY: math.NaN()}
+		if j, ok := bIndex[k]; ok {
+			d.B = b.Lines[j]
+			d.AfterTax = d.B.AfterTax - d.A.AfterTax
+			d.TEY = d.B.TEY - d.A.TEY
+		}
+		diffs = append(diffs, d)
+	}
+	for j, k := range bKeys {
+		if !seen[k] {
+			diffs = append(diffs, LineDiff{Key: k, Label: b.Lines[j].Label, B: b.Lines[j], AfterTax: math.NaN(), TEY: math.NaN()})
+		}
+	}
+	return diffs
+}
+
+// CompareAMT is in computed without and with AMT (at in.AMTBracketIndex's
+// rate), and their Diff, for investors near the AMT line. A muni's AfterTax
+// delta is its AMT exposure; lines with no AMT-includable income still move
+// if they're fully taxable, since AMT replaces the bracket.
+func CompareAMT(in Inputs) (amtOff Result, amtOn Result, diff []LineDiff) {
+	in.AMT = false
+	amtOff = Compute(in)
+	in.AMT = true
+	amtOn = Compute(in)
+	return amtOff, amtOn, Diff(amtOff, amtOn)
+}
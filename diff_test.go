@@ -0,0 +1,57 @@
+package main
+
+import (
+	"math"
+	"testing"
+)
+
+func TestCompareAMT(t *testing.T) {
+	for _, tt := range []struct {
+		amtPct float64
+		want   float64 // national muni's after-tax change under AMT
+	}{
+		{0, 0},
+		{20, -3.8 * 0.2 * 0.26},
+		{100, -3.8 * 0.26},
+	} {
+		in := exampleInputs()
+		in.Itemize, in.NatlAmTPct = false, tt.amtPct
+		off, on, diff := CompareAMT(in)
+		if off.NatlAfterTax != Compute(in).NatlAfterTax {
+			t.Errorf("%v%%: AMT-off muni nets %v, Compute %v", tt.amtPct, off.NatlAfterTax, Compute(in).NatlAfterTax)
+		}
+		if want := 3.8 * (1 - 0.093); !near(off.NatlAfterTax, want) {
+			t.Errorf("%v%%: AMT-off muni nets %v, want %v", tt.amtPct, off.NatlAfterTax, want)
+		}
+		if !near(on.NatlAfterTax-off.NatlAfterTax, tt.want) {
+			t.Errorf("%v%%: AMT moves the muni %v, want %v", tt.amtPct, on.NatlAfterTax-off.NatlAfterTax, tt.want)
+		}
+		if len(diff) != len(off.Lines) {
+			t.Fatalf("%v%%: %d diffs for %d lines", tt.amtPct, len(diff), len(off.Lines))
+		}
+		for i, d := range diff {
+			if d.A.Kind != off.Lines[i].Kind || d.B.Kind != on.Lines[i].Kind || !near(d.AfterTax, on.Lines[i].AfterTax-off.Lines[i].AfterTax) {
+				t.Errorf("%v%%: diff %d is %+v", tt.amtPct, i, d)
+			}
+			if d.Key == "natl" && !near(d.AfterTax, tt.want) {
+				t.Errorf("%v%%: natl diff %v, want %v", tt.amtPct, d.AfterTax, tt.want)
+			}
+		}
+	}
+}
+
+func TestDiff(t *testing.T) {
+	a := Compute(Inputs{FedBracket: 24, FullyTaxable: 5, NatlTaxExempt: 3.5})
+	b := Compute(Inputs{FedBracket: 32, FullyTaxable: 5, NatlTaxExempt: 3.5, Corporate: 6})
+	diff := Diff(a, b)
+	if len(diff) != len(a.Lines)+1 {
+		t.Fatalf("%d diffs, want %d", len(diff), len(a.Lines)+1)
+	}
+	if d := diff[0]; d.Key != "fully_taxable" || !near(d.AfterTax, -0.4) || d.TEY != 0 {
+		t.Errorf("fully taxable diff %+v", d)
+	}
+	// a line only b has comes last, with no delta
+	if d := diff[len(diff)-1]; d.Key != "corporate" || d.A.Label != "" || !math.IsNaN(d.AfterTax) {
+		t.Errorf("corporate diff %+v", d)
+	}
+}
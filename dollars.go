@@ -0,0 +1,20 @@
+package main
+
+import "fmt"
+
+// AfterTaxDollars is each line's annual after-tax income, in dollars, on
+// amount invested, keyed like Result.ToMap: "<instrument>_after_tax", and
+// "<instrument>_tey" for the fully taxable income it's worth.
+func AfterTaxDollars(in Inputs, amount float64) (map[string]float64, error) {
+	if amount <= 0 || !isFinite(amount) {
+		return nil, fmt.Errorf("amount must be positive, got %v", amount)
+	}
+	res := Compute(in)
+	m := map[string]float64{}
+	for i, key := range lineKeys(res.Lines) {
+		l := res.Lines[i]
+		m[key+"_after_tax"] = amount * l.AfterTax / 100
+		m[key+"_tey"] = amount * l.TEY / 100
+	}
+	return m, nil
+}
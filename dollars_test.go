@@ -0,0 +1,38 @@
+package main
+
+import (
+	"math"
+	"testing"
+)
+
+func TestAfterTaxDollars(t *testing.T) {
+	in := exampleInputs()
+	got, err := AfterTaxDollars(in, 100000)
+	if err != nil {
+		t.Fatal(err)
+	}
+	res := Compute(in)
+	want := map[string]float64{
+		"fully_taxable_after_tax": 3446.6,
+		"treasury_after_tax":      3420,
+		"natl_after_tax":          3446.6,
+		"state_after_tax":         3400,
+		"amt_free_after_tax":      3700,
+		"fully_taxable_tey":       5000,
+		"natl_tey":                1000 * res.NatlTEY,
+	}
+	for k, w := range want {
+		if math.Abs(got[k]-w) > 1e-6 {
+			t.Errorf("%s: $%v, want $%v", k, got[k], w)
+		}
+	}
+	if len(got) != 2*len(res.Lines) {
+		t.Errorf("%d entries for %d lines", len(got), len(res.Lines))
+	}
+
+	for _, amount := range []float64{0, -1000, math.NaN(), math.Inf(1)} {
+		if m, err := AfterTaxDollars(in, amount); err == nil || m != nil {
+			t.Errorf("amount %v: %v, %v; want an error", amount, m, err)
+		}
+	}
+}
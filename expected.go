@@ -0,0 +1,58 @@
+package main
+
+import (
+	"math"
+	"sort"
+)
+
+// ExpectedAfterTax is line's after-tax yield averaged over possible federal
+// brackets, weighted by bracketProbs (bracket % -> probability). Everything
+// else comes from in. It's NaN if a probability is negative or they don't
+// sum to 1.
+func ExpectedAfterTax(line Instrument, bracketProbs map[float64]float64, in Inputs) float64 {
+	brackets := make([]float64, 0, len(bracketProbs))
+	sum := 0.0
+	for b, p := range bracketProbs {
+		if p < 0 || math.IsNaN(p) {
+			return math.NaN()
+		}
+		brackets = append(brackets, b)
+		sum += p
+	}
+	if math.Abs(sum-1) > weightEpsilon {
+		return math.NaN()
+	}
+	// sorted so the sum doesn't depend on map order
+	sort.Float64s(brackets)
+	expected := 0.0
+	for _, b := range brackets {
+		in.FedBracket = b
+		expected += bracketProbs[b] * line.AfterTax(in)
+	}
+	return expected
+}
+
+// AMTFreeDecision compares an AMT-free fund with a national muni that has
+// natlAmtPct% AMT exposure, for an investor who owes AMT with probability
+// amtProbability (in.AMT is ignored; in.AMTBracketIndex is the AMT rate if
+// they do). expectedAdvantage is the AMT-free fund's expected after-tax
+// yield minus the national muni's; on a tie, keep the national. It's
+// (false, NaN) if amtProbability isn't in [0, 1].
+func AMTFreeDecision(amtFreeYield, natlYield, natlAmtPct float64, amtProbability float64, in Inputs) (chooseAMTFree bool, expectedAdvantage float64) {
+	if !(amtProbability >= 0 && amtProbability <= 1) {
+		return false, math.NaN()
+	}
+	free := in.Instrument(AMTFreeKind)
+	free.Yield = amtFreeYield
+	natl := in.Instrument(NatlTaxExemptKind)
+	natl.Yield, natl.AMTPct = natlYield, natlAmtPct
+
+	expected := func(inst Instrument) float64 {
+		in.AMT = false
+		noAMT := inst.AfterTax(in)
+		in.AMT = true
+		return (1-amtProbability)*noAMT + amtProbability*inst.AfterTax(in)
+	}
+	expectedAdvantage = expected(free) - expected(natl)
+	return expectedAdvantage > 0, expectedAdvantage
+}
@@ -0,0 +1,70 @@
+package main
+
+import (
+	"math"
+	"testing"
+)
+
+func TestExpectedAfterTax(t *testing.T) {
+	in := Inputs{FedBracket: 24, StateBracket: 5}
+	line := Instrument{Kind: FullyTaxableKind, Yield: 5, FedTaxable: true, StateTaxable: true}
+	at := func(bracket float64) float64 {
+		in := in
+		in.FedBracket = bracket
+		return line.AfterTax(in)
+	}
+
+	got := ExpectedAfterTax(line, map[float64]float64{24: 0.6, 32: 0.4}, in)
+	if want := 0.6*at(24) + 0.4*at(32); !near(got, want) || !near(got, 5*(1-0.322)) {
+		t.Errorf("60/40 24%%/32%%: got %v, want %v", got, want)
+	}
+	if got := ExpectedAfterTax(line, map[float64]float64{32: 1}, in); !near(got, at(32)) {
+		t.Errorf("certain 32%%: got %v, want %v", got, at(32))
+	}
+
+	for name, probs := range map[string]map[float64]float64{
+		"sum under 1": {24: 0.6, 32: 0.3},
+		"sum over 1":  {24: 0.6, 32: 0.6},
+		"negative":    {24: 1.2, 32: -0.2},
+		"NaN":         {24: math.NaN(), 32: 1},
+		"no brackets": {},
+	} {
+		if got := ExpectedAfterTax(line, probs, in); !math.IsNaN(got) {
+			t.Errorf("%s: got %v, want NaN", name, got)
+		}
+	}
+}
+
+func TestAMTFreeDecision(t *testing.T) {
+	// AMT Free quotes are after tax; the national muni nets 3.42% without
+	// AMT and 3.1176% with 30% of it under the 28% AMT
+	in := Inputs{FedBracket: 32, StateBracket: 5, AMTBracketIndex: 4}
+	natl := func(p float64) float64 { return (1-p)*3.6*0.95 + p*3.6*(1-0.05-0.3*0.28) }
+	for _, tt := range []struct {
+		p       float64
+		chooseF bool
+	}{
+		{0, false},
+		{0.5, true},
+		{1, true},
+	} {
+		choose, adv := AMTFreeDecision(3.3, 3.6, 30, tt.p, in)
+		if want := 3.3 - natl(tt.p); !near(adv, want) || choose != tt.chooseF {
+			t.Errorf("p=%v: (%v, %v), want (%v, %v)", tt.p, choose, adv, tt.chooseF, want)
+		}
+	}
+	// in.AMT doesn't matter; the probability decides
+	in.AMT = true
+	if _, adv := AMTFreeDecision(3.3, 3.6, 30, 0, in); !near(adv, 3.3-natl(0)) {
+		t.Errorf("with in.AMT set, p=0 advantage %v", adv)
+	}
+	// a tie keeps the national
+	if choose, adv := AMTFreeDecision(3.42, 3.6, 30, 0, in); choose || !near(adv, 0) {
+		t.Errorf("tie: (%v, %v), want (false, 0)", choose, adv)
+	}
+	for _, p := range []float64{-0.1, 1.1, math.NaN()} {
+		if choose, adv := AMTFreeDecision(3.3, 3.6, 30, p, in); choose || !math.IsNaN(adv) {
+			t.Errorf("p=%v: (%v, %v), want (false, NaN)", p, choose, adv)
+		}
+	}
+}
@@ -0,0 +1,103 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// writeExplanation prints the after-tax math for inst step by step, with a
+// running after-tax subtotal.
+func writeExplanation(w io.Writer, inst Instrument, in Inputs) {
+	in = in.autoItemize()
+	y := inst.EffectiveYield()
+	fmt.Fprintf(w, "%s at %.3f%%\n", inst.Label(), y)
+	if inst.Rule != nil {
+		fmt.Fprintf(w, "  custom tax rule\n")
+		fmt.Fprintf(w, "  after tax: %.3f%%\n", inst.AfterTax(in))
+		return
+	}
+	if inst.AfterTaxQuoted {
+		fmt.Fprintf(w, "  quoted after tax; no tax applied\n")
+		fmt.Fprintf(w, "  after tax: %.3f%%\n", y)
+		return
+	}
+
+	b := inst.breakdown(in)
+	running := y
+	step := func(label string, rate float64) {
+		delta := 0.0 // not -0
+		if rate != 0 {
+			delta = -y * rate / 100
+		}
+		running += delta
+		fmt.Fprintf(w, "  %-48s %+7.3f = %6.3f%%\n", label, delta, running)
+	}
+
+	switch {
+	case inst.FedTaxable && b.AMT:
+		step(fmt.Sprintf("federal: AMT rate %.3g%% (AMT applies)", b.FedRate), b.FedTax)
+	case inst.FedTaxable:
+		step(fmt.Sprintf("federal: %.3g%% bracket", b.FedRate), b.FedTax)
+	case b.AMT:
+		step(fmt.Sprintf("federal: exempt, but AMT %.3g%% on %.3g%% of it", b.FedRate, b.AMTPct), b.FedTax)
+	default:
+		step("federal: exempt", 0)
+	}
+
+	if inst.StateTaxable && inst.InStateFraction != 0 {
+		step(fmt.Sprintf("state: %.3g%% on the %.3g%% out of state", b.StateRate, 100*(1-inst.InStateFraction)), b.StateTax)
+	} else if inst.StateTaxable {
+		step(fmt.Sprintf("state: %.3g%%", b.StateRate), b.StateTax)
+	} else {
+		step("state: exempt", 0)
+	}
+	if b.StateDeduction != 0 {
+		step("state deduction of federal tax", -b.StateDeduction)
+	}
+
+	if b.NIIT != 0 {
+		step(fmt.Sprintf("net investment income tax: %.3g%%", b.NIIT), b.NIIT)
+	}
+
+	switch {
+	case b.DeductionCredit != 0:
+		step(fmt.Sprintf("itemized deduction of state tax at %.3g%%", b.DeductionRate), -b.DeductionCredit)
+	case inst.StateTaxable && in.Itemize && in.StateRateIsEffective:
+		step("itemized deduction: in the effective state rate", 0)
+	case inst.StateTaxable && in.Itemize && b.AMT:
+		step("itemized deduction: none (disallowed under AMT)", 0)
+	case inst.StateTaxable && in.Itemize && b.StateTax != 0:
+		step("itemized deduction: none (no federal tax to offset)", 0)
+	}
+
+	fmt.Fprintf(w, "  after tax: %.3f%% (total tax %.3f%%)\n", b.AfterTax, b.TotalTax)
+	if inst.NAVDriftPct != 0 {
+		fmt.Fprintf(w, "  %-48s %+7.3f = %6.3f%%\n", "NAV drift (unrealized, untaxed)", inst.NAVDriftPct, b.AfterTax+inst.NAVDriftPct)
+	}
+}
+
+func runExplain(args []string, stdout io.Writer) error {
+	var in Inputs
+	fs := flag.NewFlagSet("explain", flag.ContinueOnError)
+	kind := fs.String("instrument", "natl", "instrument: "+strings.Join(instrumentKeyList(), ", "))
+	var yield float64
+	percentVar(fs, &yield, "yield", 3.8, "instrument yield (%)")
+	profile := taxFlags(fs, &in)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if done, err := profile.resolve(fs, &in, stdout); done || err != nil {
+		return err
+	}
+
+	var k InstrumentKind
+	if err := k.UnmarshalText([]byte(*kind)); err != nil {
+		return err
+	}
+	inst := in.Instrument(k)
+	inst.Yield = yield
+	writeExplanation(stdout, inst, in)
+	return nil
+}
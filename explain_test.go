@@ -0,0 +1,37 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestExplainPartialAMT(t *testing.T) {
+	var out strings.Builder
+	args := []string{"-instrument", "natl", "-yield", "3.8", "-fed", "24", "-state", "9.3",
+		"-amt", "-amt-bracket", "1", "-natl-amt-pct", "20", "-itemize"}
+	if err := runCommand("explain", args, &out); err != nil {
+		t.Fatal(err)
+	}
+	want := `Nat'l Tax-Exempt at 3.800%
+  federal: exempt, but AMT 26% on 20% of it         -0.198 =  3.602%
+  state: 9.3%                                       -0.353 =  3.249%
+  itemized deduction: none (disallowed under AMT)   +0.000 =  3.249%
+  after tax: 3.249% (total tax 14.500%)
+`
+	if out.String() != want {
+		t.Errorf("got\n%s\nwant\n%s", out.String(), want)
+	}
+}
+
+func TestExplainMatchesCompute(t *testing.T) {
+	in := exampleInputs()
+	for _, k := range standardKinds() {
+		var out strings.Builder
+		writeExplanation(&out, in.Instrument(k), in)
+		l, _ := Compute(in).Line(k)
+		if want := fmt.Sprintf("%.3f%%", l.AfterTax); !strings.Contains(out.String(), "after tax: "+want) {
+			t.Errorf("%s: explanation doesn't end at %s:\n%s", k, want, out.String())
+		}
+	}
+}
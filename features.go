@@ -0,0 +1,58 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// appliedFeatures lists the tax rules Compute applies for in, in the order
+// the math applies them, for Result.AppliedFeatures.
+func (in Inputs) appliedFeatures() []string {
+	if in.TaxSystem == UKTax {
+		return []string{fmt.Sprintf("UK tax (%g%% on interest)", ukInterestRates[in.UKBand])}
+	}
+	var f []string
+	if in.AMT {
+		f = append(f, fmt.Sprintf("AMT (%g%%)", amtRate(in)))
+	}
+	if in.NIIT {
+		f = append(f, "NIIT")
+	}
+	if in.SourceState != "" && in.ResidentState != "" && !strings.EqualFold(in.SourceState, in.ResidentState) {
+		f = append(f, "resident state credit")
+	}
+	if in.LocalBracket != 0 {
+		f = append(f, "local tax")
+	}
+	if in.AutoItemize {
+		f = append(f, in.autoItemizeFeature())
+	}
+	switch {
+	case !in.Itemize || in.stateRate() == 0:
+	case in.AMT:
+		f = append(f, "itemized state deduction (disallowed under AMT)")
+	case in.StateRateIsEffective:
+		f = append(f, "effective state rate (deduction included)")
+	case in.DeductionBenefitRate > 0:
+		f = append(f, fmt.Sprintf("itemized state deduction (at %g%%)", in.DeductionBenefitRate))
+	default:
+		f = append(f, "itemized state deduction")
+	}
+	if in.StateDeductsFederal && in.stateRate() != 0 {
+		f = append(f, "state deduction of federal tax")
+	}
+	if in.MarginFraction != 0 && in.MarginInterestRate != 0 {
+		f = append(f, "margin interest")
+	}
+	if in.TaxDragBps > 0 {
+		f = append(f, fmt.Sprintf("tax drag (%g bps)", in.TaxDragBps))
+	}
+	if in.AdvisoryFee != 0 {
+		if in.FeeIsDeductible && in.Itemize && !in.AMT {
+			f = append(f, "deductible advisory fee")
+		} else {
+			f = append(f, "advisory fee")
+		}
+	}
+	return f
+}
@@ -0,0 +1,37 @@
+package main
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestAppliedFeatures(t *testing.T) {
+	base := Inputs{FullyTaxable: 5, NatlTaxExempt: 3.5, FedBracket: 32, StateBracket: 6}
+	tests := []struct {
+		name string
+		edit func(*Inputs)
+		want []string
+	}{
+		{"plain", func(in *Inputs) {}, nil},
+		{"AMT and NIIT", func(in *Inputs) { in.AMT, in.AMTBracketIndex, in.NIIT, in.Itemize = true, 4, true, true },
+			[]string{"AMT (28%)", "NIIT", "itemized state deduction (disallowed under AMT)"}},
+		{"itemizing", func(in *Inputs) { in.Itemize = true }, []string{"itemized state deduction"}},
+		{"itemizing, no state tax", func(in *Inputs) { in.Itemize, in.StateBracket = true, 0 }, nil},
+		{"itemizing at a lower rate", func(in *Inputs) { in.Itemize, in.DeductionBenefitRate = true, 24 },
+			[]string{"itemized state deduction (at 24%)"}},
+		{"local tax, margin and a fee", func(in *Inputs) {
+			in.LocalBracket, in.MarginFraction, in.MarginInterestRate, in.AdvisoryFee = 3.876, 0.5, 6, 0.5
+		}, []string{"local tax", "margin interest", "advisory fee"}},
+		{"deductible fee", func(in *Inputs) { in.Itemize, in.AdvisoryFee, in.FeeIsDeductible = true, 0.5, true },
+			[]string{"itemized state deduction", "deductible advisory fee"}},
+		{"resident credit", func(in *Inputs) { in.SourceState, in.ResidentState = "NY", "ca" },
+			[]string{"resident state credit"}},
+	}
+	for _, tt := range tests {
+		in := base
+		tt.edit(&in)
+		if got := Compute(in).AppliedFeatures; !slices.Equal(got, tt.want) {
+			t.Errorf("%s: features %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,176 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding"
+	"encoding/hex"
+	"fmt"
+	"math"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Fingerprint is a hex SHA-256 of in's fields, stable across runs and
+// processes. Every NaN hashes the same, so the FullyTaxable fallback case has
+// one fingerprint. Use CanonicalizeForHash first to ignore fields that don't
+// affect the result.
+func (in Inputs) Fingerprint() string {
+	var b strings.Builder
+	writeCanonical(&b, reflect.ValueOf(in))
+	sum := sha256.Sum256([]byte(b.String()))
+	return hex.EncodeToString(sum[:])
+}
+
+// Equal reports whether a and b have the same fields, with NaN equal to
+// NaN, as Fingerprint sees them.
+func (a Inputs) Equal(b Inputs) bool {
+	return a.Fingerprint() == b.Fingerprint()
+}
+
+// DedupInputs is inputs without repeats (by Fingerprint), in first-seen
+// order, e.g. to trim a batch before ComputeBatch.
+func DedupInputs(inputs []Inputs) []Inputs {
+	seen := make(map[string]bool, len(inputs))
+	out := make([]Inputs, 0, len(inputs))
+	for _, in := range inputs {
+		fp := in.Fingerprint()
+		if seen[fp] {
+			continue
+		}
+		seen[fp] = true
+		out = append(out, in)
+	}
+	return out
+}
+
+// CanonicalizeForHash zeroes settings that Compute ignores, so Inputs that
+// differ only there get the same Fingerprint.
+func (in Inputs) CanonicalizeForHash() Inputs {
+	if !in.AMT {
+		in.AMTBracketIndex = 0
+		in.NatlAmTPct = 0
+		in.StateAmTPct = 0
+		in.TaxFreeMMFAMTPct = 0
+		in.AuthorityAmTPct = 0
+	}
+	if in.treasuryStateExempt() {
+		in.TreasuryStateExempt = nil
+	}
+	if in.AutoItemize {
+		in.Itemize = in.autoItemize().Itemize
+	} else {
+		in.StandardDeduction, in.OtherItemized, in.SALTCap = 0, 0, 0
+	}
+	if !in.Itemize {
+		in.StateRateIsEffective = false
+	}
+	if !in.NIIT || in.MAGI <= 0 {
+		in.MAGI = 0
+		in.NetInvestmentIncome = 0
+	}
+	if in.AdvisoryFee == 0 {
+		in.FeeIsDeductible = false
+	}
+	if in.Agency == 0 {
+		in.AgencyStateExempt = false
+	}
+	if in.AuthorityBond == 0 {
+		in.AuthorityIssuerState = ""
+	}
+	if in.TaxableMuni == 0 {
+		in.TaxableMuniIssuerState = ""
+	}
+	if in.ReinvestmentYears <= 0 || len(in.Reinvest) == 0 {
+		in.ReinvestmentRate, in.ReinvestmentYears, in.Reinvest = 0, 0, nil
+	}
+	if in.Enabled != nil {
+		enabled := map[InstrumentKind]bool{}
+		for k, on := range in.Enabled {
+			if on {
+				enabled[k] = true
+			}
+		}
+		in.Enabled = enabled
+	}
+	return in
+}
+
+// writeCanonical writes v as "Field=value" lines in declaration order, with
+// map entries sorted by key.
+func writeCanonical(b *strings.Builder, v reflect.Value) {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		b.WriteString(t.Field(i).Name)
+		b.WriteByte('=')
+		writeValue(b, v.Field(i))
+		b.WriteByte('\n')
+	}
+}
+
+func writeValue(b *strings.Builder, v reflect.Value) {
+	if m, ok := v.Interface().(encoding.TextMarshaler); ok && v.Kind() == reflect.Struct {
+		// opaque types like language.Tag
+		if text, err := m.MarshalText(); err == nil {
+			b.Write(text)
+			return
+		}
+	}
+	switch v.Kind() {
+	case reflect.Float32, reflect.Float64:
+		f := v.Float()
+		switch {
+		case math.IsNaN(f):
+			b.WriteString("NaN")
+		case f == 0:
+			b.WriteString("0") // fold -0 into 0
+		default:
+			b.WriteString(strconv.FormatFloat(f, 'g', -1, 64))
+		}
+	case reflect.Map:
+		if v.IsNil() {
+			b.WriteString("nil")
+			return
+		}
+		keys := make([]string, 0, v.Len())
+		vals := map[string]string{}
+		iter := v.MapRange()
+		for iter.Next() {
+			var kb, vb strings.Builder
+			writeValue(&kb, iter.Key())
+			writeValue(&vb, iter.Value())
+			keys = append(keys, kb.String())
+			vals[kb.String()] = vb.String()
+		}
+		sort.Strings(keys)
+		b.WriteByte('{')
+		for _, k := range keys {
+			fmt.Fprintf(b, "%s:%s,", k, vals[k])
+		}
+		b.WriteByte('}')
+	case reflect.Slice:
+		if v.IsNil() {
+			b.WriteString("nil")
+			return
+		}
+		b.WriteByte('[')
+		for i := 0; i < v.Len(); i++ {
+			writeValue(b, v.Index(i))
+			b.WriteByte(',')
+		}
+		b.WriteByte(']')
+	case reflect.Struct:
+		b.WriteByte('{')
+		writeCanonical(b, v)
+		b.WriteByte('}')
+	case reflect.Pointer:
+		if v.IsNil() {
+			b.WriteString("nil")
+			return
+		}
+		writeValue(b, v.Elem())
+	default:
+		fmt.Fprintf(b, "%v", v.Interface())
+	}
+}
@@ -0,0 +1,85 @@
+package main
+
+import (
+	"math"
+	"testing"
+)
+
+func TestFingerprintStable(t *testing.T) {
+	in := exampleInputs()
+	in.Enabled = map[InstrumentKind]bool{FullyTaxableKind: true, TreasuryKind: true, NatlTaxExemptKind: true, StateTaxExemptKind: true, AMTFreeKind: true}
+	fp := in.Fingerprint()
+	if len(fp) != 64 {
+		t.Fatalf("fingerprint %q isn't hex SHA-256", fp)
+	}
+	for range 20 {
+		// map iteration order changes from run to run
+		again := in
+		again.Enabled = map[InstrumentKind]bool{AMTFreeKind: true, StateTaxExemptKind: true, NatlTaxExemptKind: true, TreasuryKind: true, FullyTaxableKind: true}
+		if got := again.Fingerprint(); got != fp {
+			t.Fatalf("fingerprint changed: %s, then %s", fp, got)
+		}
+	}
+
+	a, b := exampleInputs(), exampleInputs()
+	a.FullyTaxable = math.NaN()
+	b.FullyTaxable = math.Float64frombits(math.Float64bits(math.NaN()) | 1) // another NaN
+	if a.Fingerprint() != b.Fingerprint() {
+		t.Error("NaN fallbacks hash differently")
+	}
+	if a.Fingerprint() == exampleInputs().Fingerprint() {
+		t.Error("NaN fallback hashes like the example")
+	}
+	a, b = exampleInputs(), exampleInputs()
+	a.LocalBracket, b.LocalBracket = 0, math.Copysign(0, -1)
+	if a.Fingerprint() != b.Fingerprint() {
+		t.Error("-0 and 0 hash differently")
+	}
+}
+
+func TestCanonicalizeForHash(t *testing.T) {
+	a, b := exampleInputs(), exampleInputs()
+	b.AMTBracketIndex = 3 // ignored without AMT
+	if a.Fingerprint() == b.Fingerprint() {
+		t.Fatal("raw fingerprints should see AMTBracketIndex")
+	}
+	if a.CanonicalizeForHash().Fingerprint() != b.CanonicalizeForHash().Fingerprint() {
+		t.Error("AMTBracketIndex without AMT changed the canonical fingerprint")
+	}
+	a.AMT, b.AMT = true, true
+	if a.CanonicalizeForHash().Fingerprint() == b.CanonicalizeForHash().Fingerprint() {
+		t.Error("AMTBracketIndex under AMT didn't change the canonical fingerprint")
+	}
+
+	c, d := exampleInputs(), exampleInputs()
+	c.Enabled = map[InstrumentKind]bool{NatlTaxExemptKind: true}
+	d.Enabled = map[InstrumentKind]bool{NatlTaxExemptKind: true, TreasuryKind: false}
+	if c.CanonicalizeForHash().Fingerprint() != d.CanonicalizeForHash().Fingerprint() {
+		t.Error("a false Enabled entry changed the canonical fingerprint")
+	}
+	if Compute(c).Text != Compute(d).Text {
+		t.Fatal("setup: the two should compute the same")
+	}
+}
+
+func TestDedupInputs(t *testing.T) {
+	nan1, nan2 := exampleInputs(), exampleInputs()
+	nan1.FullyTaxable = math.NaN()
+	nan2.FullyTaxable = math.Float64frombits(math.Float64bits(math.NaN()) | 1)
+	other := exampleInputs()
+	other.FedBracket = 32
+
+	got := DedupInputs([]Inputs{nan1, exampleInputs(), nan2, other, exampleInputs(), nan1})
+	if len(got) != 3 {
+		t.Fatalf("%d inputs after dedup, want 3", len(got))
+	}
+	if !math.IsNaN(got[0].FullyTaxable) || !got[1].Equal(exampleInputs()) || !got[2].Equal(other) {
+		t.Errorf("dedup kept %+v, want the NaN case, the example and the 32%% case in that order", got)
+	}
+	if !nan1.Equal(nan2) || nan1.Equal(exampleInputs()) || exampleInputs().Equal(other) {
+		t.Error("Equal disagrees with the fingerprints")
+	}
+	if got := DedupInputs(nil); len(got) != 0 {
+		t.Errorf("DedupInputs(nil) = %v", got)
+	}
+}
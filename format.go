@@ -0,0 +1,95 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+
+	"golang.org/x/text/language"
+	"golang.org/x/text/message"
+)
+
+// FormatOptions controls how Result.Text renders numbers. The float fields of
+// Result are never affected.
+type FormatOptions struct {
+	// Decimal places; 0 means 3.
+	Precision int
+	// Significant figures, when set, instead of fixed decimal places; it
+	// takes precedence over Precision.
+	SigFigs int
+	// Locale for separators, e.g. language.German renders 3,800 %. The zero
+	// value renders exactly as before (en-US style, no digit grouping).
+	Locale language.Tag
+	// Add a line with the gross-up factor.
+	ShowGrossUp bool
+	// Add a TEY spread (bps over the benchmark) column to the Markdown,
+	// HTML and CSV output.
+	ShowSpread bool
+	// Add TEY columns against both benchmarks (see
+	// ResultLine.TEYVsFullyTaxable) to every output; the treasury one is
+	// left out without a treasury yield.
+	ShowBothTEY bool
+}
+
+// spacedPercentLocales put a (no-break) space between the number and the
+// % sign.
+var spacedPercentLocales = map[language.Base]bool{}
+
+func init() {
+	for _, l := range []string{"cs", "da", "de", "es", "fi", "fr", "it", "nb", "pl", "ru", "sv"} {
+		spacedPercentLocales[language.MustParseBase(l)] = true
+	}
+}
+
+// numberFormatter renders percentages per a FormatOptions.
+type numberFormatter struct {
+	sprintf func(format string, a ...any) string
+	verb    string // e.g. "%6.3f"
+	suffix  string // "%", or "\u00a0%" (a no-break space)
+	sigFigs int    // if set, verb's precision varies per number
+}
+
+func (o FormatOptions) formatter() numberFormatter {
+	prec := o.Precision
+	if prec == 0 {
+		prec = 3
+	}
+	f := numberFormatter{
+		sprintf: fmt.Sprintf,
+		verb:    fmt.Sprintf("%%%d.%df", prec+3, prec),
+		suffix:  "%",
+		sigFigs: o.SigFigs,
+	}
+	if o.Locale != language.Und {
+		p := message.NewPrinter(o.Locale)
+		f.sprintf = func(format string, a ...any) string { return p.Sprintf(format, a...) }
+		if base, _ := o.Locale.Base(); spacedPercentLocales[base] {
+			f.suffix = "\u00a0%"
+		}
+	}
+	return f
+}
+
+// factor renders a multiplier, e.g. "1.451x".
+func (f numberFormatter) factor(v float64) string {
+	return f.num(v) + "x"
+}
+
+// pct renders v as a percentage, e.g. " 3.800%".
+func (f numberFormatter) pct(v float64) string {
+	return f.num(v) + f.suffix
+}
+
+func (f numberFormatter) num(v float64) string {
+	if f.sigFigs <= 0 || !isFinite(v) {
+		return f.sprintf(f.verb, v)
+	}
+	// Round to sigFigs with 'g', then show it without an exponent, keeping
+	// trailing zeros that are significant (3.80, 0.000420, 1230).
+	r, _ := strconv.ParseFloat(strconv.FormatFloat(v, 'g', f.sigFigs, 64), 64)
+	decimals := f.sigFigs - 1
+	if r != 0 {
+		decimals -= int(math.Floor(math.Log10(math.Abs(r))))
+	}
+	return f.sprintf(fmt.Sprintf("%%%d.%df", f.sigFigs+3, max(decimals, 0)), r)
+}
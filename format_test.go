@@ -0,0 +1,74 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"golang.org/x/text/language"
+)
+
+func TestLocaleText(t *testing.T) {
+	render := func(locale language.Tag) string {
+		in := exampleInputs()
+		in.Format.Locale = locale
+		return Compute(in).Text
+	}
+	if got, want := render(language.AmericanEnglish), Compute(exampleInputs()).Text; got != want {
+		t.Errorf("en-US differs from the default:\n%s\nwant\n%s", got, want)
+	}
+	tests := []struct {
+		locale language.Tag
+		want   string // the first line
+	}{
+		{language.AmericanEnglish, "Fully Taxable:      3.447% after tax,  5.000% tax equivalent"},
+		{language.MustParse("de-DE"), "Fully Taxable:      3,447\u00a0% after tax,  5,000\u00a0% tax equivalent"},
+		{language.MustParse("fr-FR"), "Fully Taxable:      3,447\u00a0% after tax,  5,000\u00a0% tax equivalent"},
+	}
+	for _, tt := range tests {
+		text := render(tt.locale)
+		if first, _, _ := strings.Cut(text, "\n"); first != tt.want {
+			t.Errorf("%v: got %q, want %q", tt.locale, first, tt.want)
+		}
+		if n := strings.Count(text, "\n") + 1; n != 5 {
+			t.Errorf("%v: %d lines", tt.locale, n)
+		}
+	}
+}
+
+func TestLocaleLeavesNumbers(t *testing.T) {
+	in := exampleInputs()
+	in.Format.Locale = language.German
+	got, want := Compute(in), Compute(exampleInputs())
+	if got.NatlAfterTax != want.NatlAfterTax || got.GrossUp != want.GrossUp {
+		t.Error("the locale changed the numbers, not just Text")
+	}
+}
+
+func TestSigFigs(t *testing.T) {
+	f := FormatOptions{SigFigs: 3}.formatter()
+	for _, tt := range []struct {
+		v    float64
+		want string
+	}{
+		{0.00042, "0.000420%"},
+		{1234.5, "  1230%"},
+		{3.8, "  3.80%"},
+		{99.96, "   100%"}, // rounding carries a digit
+		{0, "  0.00%"},
+	} {
+		if got := f.pct(tt.v); got != tt.want {
+			t.Errorf("%v at 3 sig figs: %q, want %q", tt.v, got, tt.want)
+		}
+	}
+
+	// SigFigs wins over Precision
+	in := exampleInputs()
+	in.Format = FormatOptions{SigFigs: 3, Precision: 5}
+	if first, _, _ := strings.Cut(Compute(in).Text, "\n"); first != "Fully Taxable:       3.45% after tax,   5.00% tax equivalent" {
+		t.Errorf("first line %q", first)
+	}
+	in.Format.SigFigs = 0
+	if first, _, _ := strings.Cut(Compute(in).Text, "\n"); !strings.Contains(first, "3.44660%") {
+		t.Errorf("Precision 5 without SigFigs: %q", first)
+	}
+}
@@ -0,0 +1,52 @@
+package main
+
+import (
+	"math"
+	"testing"
+)
+
+// inRange maps any float onto [0, hi], so the fuzzer's raw values become
+// plausible rates and yields.
+func inRange(v, hi float64) float64 {
+	if !isFinite(v) {
+		return 0
+	}
+	return math.Mod(math.Abs(v), hi)
+}
+
+func FuzzCalcAfterTaxYield(f *testing.F) {
+	// yield, fed, state, local, amtPct, deductionRate, amtIndex, flags
+	f.Add(5.0, 24.0, 9.3, 0.0, 0.0, 0.0, 0, uint8(0b00001))
+	f.Add(5.0, 10.0, 13.3, 0.0, 0.0, 0.0, 0, uint8(0b00001))    // deduction worth more than the federal tax
+	f.Add(5.0, 10.0, 14.99, 4.99, 0.0, 49.0, 0, uint8(0b01001)) // deduction above the bracket, state deducting federal
+	f.Add(5.0, 49.99, 14.99, 4.99, 100.0, 0.0, 3, uint8(0b11111))
+	f.Add(0.0, 0.0, 0.0, 0.0, 0.0, 0.0, 0, uint8(0))
+	f.Add(19.99, 37.0, 0.0, 0.0, 100.0, 0.0, 4, uint8(0b00010))
+	f.Fuzz(func(t *testing.T, yield, fed, state, local, amtPct, deductionRate float64, amtIndex int, flags uint8) {
+		in := Inputs{
+			FedBracket:           inRange(fed, 50),
+			StateBracket:         inRange(state, 15),
+			LocalBracket:         inRange(local, 5),
+			DeductionBenefitRate: inRange(deductionRate, 50),
+			AMTBracketIndex:      int(uint(amtIndex) % 5),
+			Itemize:              flags&1 != 0,
+			AMT:                  flags&2 != 0,
+			NIIT:                 flags&4 != 0,
+			StateDeductsFederal:  flags&8 != 0,
+			UseDecimal:           flags&16 != 0,
+		}
+		y, pct := inRange(yield, 20), inRange(amtPct, 100)
+		for _, fedTaxable := range []bool{false, true} {
+			for _, stateTaxable := range []bool{false, true} {
+				tax := composeTax(in, fedTaxable, stateTaxable, pct)
+				if !(tax >= -1e-9 && tax <= 100) {
+					t.Fatalf("%+v, fed %v, state %v, AMT %v%%: total tax %v%% outside [0, 100]", in, fedTaxable, stateTaxable, pct, tax)
+				}
+				got := calcAfterTaxYield(y, fedTaxable, stateTaxable, pct, in)
+				if !isFinite(got) || got < -1e-9 || got > y+1e-9 {
+					t.Fatalf("%+v, fed %v, state %v, AMT %v%%: %v%% nets %v", in, fedTaxable, stateTaxable, pct, y, got)
+				}
+			}
+		}
+	})
+}
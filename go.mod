@@ -1,3 +1,9 @@
 module github.com/kybouw/taxableyield
 
 go 1.23.4
+
+require (
+	github.com/shopspring/decimal v1.4.0
+	golang.org/x/text v0.21.0
+	google.golang.org/protobuf v1.36.5
+)
@@ -0,0 +1,139 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"sort"
+)
+
+// goldenEpsilon is how far a recomputed number may drift from the corpus.
+const goldenEpsilon = 1e-9
+
+// goldenCase is one line of the golden corpus: inputs and the Result they
+// produced when the corpus was last updated.
+type goldenCase struct {
+	Name   string
+	Inputs Inputs
+	Result Result
+}
+
+// runGolden checks Compute against a JSON Lines corpus of (Inputs, Result)
+// pairs, failing on any drift past goldenEpsilon. With -update it rewrites
+// the expected results from the current code instead. TestGoldenCorpus runs
+// the same check under go test.
+func runGolden(args []string, stdout io.Writer) error {
+	fs := flag.NewFlagSet("golden", flag.ContinueOnError)
+	file := fs.String("file", "testdata/golden.jsonl", "corpus to check")
+	update := fs.Bool("update", false, "regenerate the expected results")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	cases, err := readGolden(*file)
+	if err != nil {
+		return err
+	}
+
+	if *update {
+		if err := updateGolden(*file, cases); err != nil {
+			return err
+		}
+		fmt.Fprintf(stdout, "updated %d cases\n", len(cases))
+		return nil
+	}
+
+	failed := 0
+	for _, c := range cases {
+		for _, d := range goldenDiff(c.Result, Compute(c.Inputs)) {
+			fmt.Fprintf(stdout, "%s: %s\n", c.Name, d)
+			failed++
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d golden mismatches", failed)
+	}
+	fmt.Fprintf(stdout, "ok: %d cases\n", len(cases))
+	return nil
+}
+
+// updateGolden rewrites the corpus at path with cases' results recomputed
+// by the current code.
+func updateGolden(path string, cases []goldenCase) error {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	for _, c := range cases {
+		c.Result = Compute(c.Inputs)
+		if err := enc.Encode(c); err != nil {
+			return fmt.Errorf("%s: %w", c.Name, err)
+		}
+	}
+	return os.WriteFile(path, buf.Bytes(), 0o644)
+}
+
+func readGolden(path string) ([]goldenCase, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	var cases []goldenCase
+	sc := bufio.NewScanner(f)
+	sc.Buffer(nil, 1<<20)
+	for line := 1; sc.Scan(); line++ {
+		if len(bytes.TrimSpace(sc.Bytes())) == 0 {
+			continue
+		}
+		var c goldenCase
+		if err := json.Unmarshal(sc.Bytes(), &c); err != nil {
+			return nil, fmt.Errorf("%s:%d: %w", path, line, err)
+		}
+		cases = append(cases, c)
+	}
+	return cases, sc.Err()
+}
+
+// goldenDiff describes each way got differs from want.
+func goldenDiff(want, got Result) []string {
+	var diffs []string
+	wm, gm := want.ToMap(), got.ToMap()
+	keys := make([]string, 0, len(wm))
+	for k := range wm {
+		keys = append(keys, k)
+	}
+	for k := range gm {
+		if _, ok := wm[k]; !ok {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		w, inWant := wm[k]
+		g, inGot := gm[k]
+		switch {
+		case !inWant:
+			diffs = append(diffs, fmt.Sprintf("%s: unexpected, got %v", k, g))
+		case !inGot:
+			diffs = append(diffs, fmt.Sprintf("%s: missing, want %v", k, w))
+		case !goldenClose(w, g):
+			diffs = append(diffs, fmt.Sprintf("%s: got %v, want %v", k, g, w))
+		}
+	}
+	if want.Text != got.Text {
+		diffs = append(diffs, fmt.Sprintf("Text: got %q, want %q", got.Text, want.Text))
+	}
+	return diffs
+}
+
+// goldenClose compares within goldenEpsilon. NaN matches NaN, and JSON
+// stores an infinite gross-up as null, so that matches NaN too.
+func goldenClose(want, got float64) bool {
+	if !isFinite(want) || !isFinite(got) {
+		return !isFinite(want) && !isFinite(got)
+	}
+	return math.Abs(want-got) <= goldenEpsilon
+}
@@ -0,0 +1,35 @@
+package main
+
+import (
+	"flag"
+	"testing"
+)
+
+var update = flag.Bool("update", false, "rewrite testdata/golden.jsonl from the current code")
+
+// TestGoldenCorpus is the golden subcommand under go test: go test -run
+// GoldenCorpus -update regenerates the corpus.
+func TestGoldenCorpus(t *testing.T) {
+	const file = "testdata/golden.jsonl"
+	cases, err := readGolden(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(cases) == 0 {
+		t.Fatalf("%s has no cases", file)
+	}
+	if *update {
+		if err := updateGolden(file, cases); err != nil {
+			t.Fatal(err)
+		}
+		t.Logf("updated %d cases", len(cases))
+		return
+	}
+	for _, c := range cases {
+		t.Run(c.Name, func(t *testing.T) {
+			for _, d := range goldenDiff(c.Result, Compute(c.Inputs)) {
+				t.Error(d)
+			}
+		})
+	}
+}
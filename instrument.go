@@ -0,0 +1,396 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// InstrumentKind identifies one of the instruments in a Result.
+type InstrumentKind int
+
+const (
+	FullyTaxableKind InstrumentKind = iota
+	TreasuryKind
+	NatlTaxExemptKind
+	StateTaxExemptKind
+	AMTFreeKind
+	TBillKind
+	CorporateKind
+	AgencyKind
+	BondFundKind
+	TotalReturnKind
+	TaxFreeMMFKind
+	BalancedFundKind
+	AuthorityBondKind
+	TaxableMuniKind
+)
+
+// standardKinds are the built-in instruments, in Result order.
+func standardKinds() []InstrumentKind {
+	return []InstrumentKind{FullyTaxableKind, CorporateKind, TreasuryKind, AgencyKind, TaxableMuniKind, BondFundKind, TBillKind, NatlTaxExemptKind, StateTaxExemptKind, AuthorityBondKind, TaxFreeMMFKind, AMTFreeKind, BalancedFundKind, TotalReturnKind}
+}
+
+// String returns the label used in Result.Text.
+func (k InstrumentKind) String() string {
+	switch k {
+	case FullyTaxableKind:
+		return "Fully Taxable"
+	case TreasuryKind:
+		return "Treasury"
+	case NatlTaxExemptKind:
+		return "Nat'l Tax-Exempt"
+	case StateTaxExemptKind:
+		return "State Tax-Exempt"
+	case AMTFreeKind:
+		return "AMT Free"
+	case TBillKind:
+		return "T-Bill"
+	case CorporateKind:
+		return "Corporate"
+	case AgencyKind:
+		return "Agency"
+	case BondFundKind:
+		return "Bond Fund"
+	case TotalReturnKind:
+		return "Total Return"
+	case TaxFreeMMFKind:
+		return "Tax-Free MMF"
+	case BalancedFundKind:
+		return "Balanced Fund"
+	case AuthorityBondKind:
+		return "Authority Bond"
+	case TaxableMuniKind:
+		return "Taxable Muni"
+	default:
+		return "Unknown"
+	}
+}
+
+// Instrument is one yield and how it's taxed.
+type Instrument struct {
+	Kind  InstrumentKind
+	Name  string // label override; Kind.String() if empty
+	Yield float64
+	Basis YieldType
+
+	// Compounding of Yield; non-annual quotes are annualized before tax
+	Compounding Compounding
+
+	FedTaxable   bool
+	StateTaxable bool
+	AMTPct       float64 // AMT-affected portion (%) when not FedTaxable
+	Dividend     bool    // taxed at dividend rates under UKTax
+
+	// AMTRateOverride (%), if set, is the AMT rate on this instrument's
+	// AMTPct instead of Inputs.AMTBracketIndex's, for holdings with their
+	// own marginal AMT exposure. It's ignored on FedTaxable instruments.
+	AMTRateOverride float64
+
+	// InStateFraction (0..1) of a StateTaxable instrument's income is exempt
+	// from state tax anyway, like the in-state slice of a national muni fund.
+	InStateFraction float64
+
+	// CreditSpread (yield points) is taken off Yield before tax to normalize
+	// to a common credit quality; negative adds to it.
+	CreditSpread float64
+
+	// With ReinvestmentYears set, the yield is the total yield from
+	// reinvesting coupons at ReinvestmentRate (%) for that many years,
+	// rather than the current yield. It's applied after CreditSpread.
+	ReinvestmentRate  float64
+	ReinvestmentYears int
+
+	// AfterTaxQuoted means Yield is already after tax (the AMT Free
+	// convention from the original JS), so no tax is applied.
+	AfterTaxQuoted bool
+
+	// Notes on how Yield was arrived at, shown on the Result line
+	Notes []string
+
+	// Optional instruments only get a Result line when their yield is set
+	// (or Inputs.Enabled asks for them), so older callers see no change.
+	Optional bool
+
+	// NAVDriftPct is a fund's expected annual NAV change (%), added to the
+	// after-tax yield untaxed: it's an unrealized gain (or loss) until the
+	// fund is sold, so this is a buy-and-hold estimate that ignores the
+	// eventual capital gains tax and any loss deduction.
+	NAVDriftPct float64
+
+	// Rule, if set, replaces the built-in tax treatment above. An instrument
+	// with its own Rule is never the benchmark and doesn't fill Result's
+	// per-kind fields, so Kind can be left unset.
+	Rule InstrumentRule
+
+	// Order places the instrument's line; lower comes first, and ties keep
+	// their given order. The built-in instruments all use 0.
+	Order int
+}
+
+// Label is the instrument's display name.
+func (i Instrument) Label() string {
+	if i.Name != "" {
+		return i.Name
+	}
+	return i.Kind.String()
+}
+
+// EffectiveYield is Yield annualized, after the credit spread adjustment
+// and any reinvestment assumption.
+func (i Instrument) EffectiveYield() float64 {
+	y := Annualize(i.Yield, i.Compounding) - i.CreditSpread
+	if i.ReinvestmentYears > 0 {
+		y = EffectiveYieldWithReinvestment(y, i.ReinvestmentRate, i.ReinvestmentYears)
+	}
+	return y
+}
+
+// AfterTax is the instrument's after-tax yield under in's tax settings.
+func (i Instrument) AfterTax(in Inputs) float64 {
+	return i.rule().AfterTax(i.EffectiveYield(), in) + i.NAVDriftPct
+}
+
+// rule is the InstrumentRule that taxes i: its own Rule if set, otherwise
+// the built-in one its fields describe.
+func (i Instrument) rule() InstrumentRule {
+	switch {
+	case i.Rule != nil:
+		return i.Rule
+	case i.AfterTaxQuoted:
+		return quotedRule{}
+	default:
+		return i.taxRule()
+	}
+}
+
+// customRule reports whether i is taxed by a caller's Rule rather than a
+// built-in treatment (the package's own rules only wrap or blend those).
+func (i Instrument) customRule() bool {
+	if i.Rule == nil {
+		return false
+	}
+	_, builtin := i.Rule.(builtinRule)
+	return !builtin
+}
+
+func (i Instrument) taxRule() taxRule {
+	return taxRule{FedTaxable: i.FedTaxable, StateTaxable: i.StateTaxable, AMTPct: i.AMTPct,
+		InStateFraction: i.InStateFraction, AMTRate: i.AMTRateOverride, Dividend: i.Dividend}
+}
+
+// breakdown is the US tax math for the instrument's effective yield.
+func (i Instrument) breakdown(in Inputs) Breakdown {
+	return i.taxRule().breakdown(i.EffectiveYield(), in)
+}
+
+// tey grosses up the instrument's after-tax yield. A zero yield stays exactly
+// 0 even if grossup is Inf (no 0*Inf = NaN), and the benchmark (fully
+// taxable, as in the original) is its own TEY.
+func (i Instrument) tey(afterTax, grossup float64, in Inputs) float64 {
+	switch {
+	case i.EffectiveYield() == 0:
+		return 0
+	case i.Kind == in.Benchmark && !i.customRule():
+		return i.EffectiveYield()
+	default:
+		return afterTax * grossup
+	}
+}
+
+// effectiveTaxRate is the share (%) of the yield that afterTax gives up.
+func (i Instrument) effectiveTaxRate(afterTax float64, in Inputs) float64 {
+	if i.AfterTaxQuoted {
+		return 0
+	}
+	if y := i.EffectiveYield(); y != 0 {
+		return 100 * (1 - (afterTax-i.NAVDriftPct)/y)
+	}
+	unit := i
+	unit.Yield, unit.CreditSpread, unit.Compounding, unit.NAVDriftPct = 1, 0, Annual, 0
+	return 100 * (1 - unit.AfterTax(in))
+}
+
+// ReportedInstruments is Instruments less those Compute leaves out (see
+// Inputs.Enabled). Append custom instruments to it for ComputeInstruments.
+func (in Inputs) ReportedInstruments() []Instrument {
+	var insts []Instrument
+	for _, inst := range in.Instruments() {
+		if in.reports(inst) {
+			insts = append(insts, inst)
+		}
+	}
+	return insts
+}
+
+// Instruments returns the standard instruments described by in, in Result
+// order, whether or not they're enabled.
+func (in Inputs) Instruments() []Instrument {
+	insts := []Instrument{
+		{Kind: FullyTaxableKind, Yield: in.FullyTaxable, Basis: in.FullyTaxableType, FedTaxable: true, StateTaxable: true},
+		{Kind: CorporateKind, Yield: in.Corporate, FedTaxable: true, StateTaxable: true, Optional: true},
+		{Kind: TreasuryKind, Yield: in.Treasury, Basis: in.TreasuryType, FedTaxable: true, StateTaxable: !in.treasuryStateExempt()},
+		{Kind: AgencyKind, Yield: in.Agency, FedTaxable: true, StateTaxable: !in.AgencyStateExempt, Optional: true},
+		{Kind: TaxableMuniKind, Yield: in.TaxableMuni, FedTaxable: true, StateTaxable: !in.residentOf(in.TaxableMuniIssuerState), Optional: true},
+		{Kind: BondFundKind, Yield: in.BondFund, FedTaxable: true, StateTaxable: true, NAVDriftPct: in.BondFundNAVDrift, Optional: true},
+		in.tbillInstrument(),
+		{Kind: NatlTaxExemptKind, Yield: in.NatlTaxExempt, Basis: in.NatlTaxExemptType, StateTaxable: true, AMTPct: in.NatlAmTPct, InStateFraction: in.NatlInStateFraction},
+		{Kind: StateTaxExemptKind, Yield: in.StateTaxExempt, Basis: in.StateTaxExemptType, AMTPct: in.StateAmTPct},
+		{Kind: AuthorityBondKind, Yield: in.AuthorityBond, StateTaxable: !in.residentOf(in.AuthorityIssuerState), AMTPct: in.AuthorityAmTPct, Optional: true},
+		{Kind: TaxFreeMMFKind, Yield: in.TaxFreeMMF, StateTaxable: !in.TaxFreeMMFSingleState, AMTPct: in.TaxFreeMMFAMTPct, Optional: true},
+	}
+	insts = append(insts, in.amtFreeInstruments()...)
+	insts = append(insts, in.Balanced.Instrument(), in.TotalReturn.Instrument())
+	for i := range insts {
+		insts[i] = in.quote(insts[i])
+	}
+	return insts
+}
+
+// amtFreeInstruments is one instrument per AMTFreeFunds entry, or the
+// single AMTFree yield if there are none.
+func (in Inputs) amtFreeInstruments() []Instrument {
+	if len(in.AMTFreeFunds) == 0 {
+		return []Instrument{{Kind: AMTFreeKind, Yield: in.AMTFree, Basis: in.AMTFreeType, AfterTaxQuoted: true}}
+	}
+	insts := make([]Instrument, len(in.AMTFreeFunds))
+	for i, f := range in.AMTFreeFunds {
+		insts[i] = Instrument{Kind: AMTFreeKind, Name: f.Name, Yield: f.Yield, Basis: in.AMTFreeType, AfterTaxQuoted: true}
+	}
+	return insts
+}
+
+// quote picks the yield to use for inst (YTW or YTM over the stated yield,
+// per UseYTW), applies any credit spread, and notes anything other than a
+// stated SEC yield.
+func (in Inputs) quote(inst Instrument) Instrument {
+	if inst.Basis != SECYield {
+		inst.Notes = append(inst.Notes, inst.Basis.String())
+	}
+	if y, ok := in.YieldToWorst[inst.Kind]; ok && in.UseYTW {
+		inst.Yield = y
+		inst.Notes = append(inst.Notes, "YTW")
+	} else if y, ok := in.YieldToMaturity[inst.Kind]; ok && !in.UseYTW {
+		inst.Yield = y
+		inst.Notes = append(inst.Notes, "YTM")
+	}
+	if c := in.Compounding[inst.Kind]; c != Annual {
+		inst.Compounding = c
+		inst.Notes = append(inst.Notes, c.String()+", annualized")
+	}
+	if spread := in.CreditSpread[inst.Kind]; spread != 0 {
+		inst.CreditSpread = spread
+		inst.Notes = append(inst.Notes, fmt.Sprintf("credit adj %+.2f", -spread))
+	}
+	if in.Reinvest[inst.Kind] && in.ReinvestmentYears > 0 {
+		inst.ReinvestmentRate, inst.ReinvestmentYears = in.ReinvestmentRate, in.ReinvestmentYears
+		inst.Notes = append(inst.Notes, fmt.Sprintf("reinvested at %g%% for %dy", in.ReinvestmentRate, in.ReinvestmentYears))
+	}
+	return inst
+}
+
+// Instrument returns the standard instrument of kind k (the first, for
+// AMT Free funds).
+func (in Inputs) Instrument(k InstrumentKind) Instrument {
+	for _, inst := range in.Instruments() {
+		if inst.Kind == k {
+			return inst
+		}
+	}
+	return Instrument{Kind: k}
+}
+
+// NamedYield is a yield with a display name, e.g. one fund among several.
+type NamedYield struct {
+	Name  string
+	Yield float64
+}
+
+// ResultLine is one instrument's row in a Result.
+type ResultLine struct {
+	Kind     InstrumentKind
+	Label    string
+	Yield    float64 // pretax yield used, after any credit spread
+	AfterTax float64
+	TEY      float64
+	Basis    YieldType
+
+	// Share of Yield lost to tax (%); 0 for after-tax quotes
+	EffectiveTaxRate float64
+
+	// AfterTax less the net advisory fee
+	AfterTaxAfterFee float64
+
+	// TEY over the benchmark's yield (Inputs.Benchmark), in basis points;
+	// negative when the line trails it. NaN with the synthetic benchmark.
+	TEYSpreadBps float64
+
+	// TEY grossed up to each benchmark with its own gross-up, whatever
+	// Inputs.Benchmark is; TEYVsTreasury is NaN without a treasury yield.
+	// Unlike TEY, neither follows AMTFreeTEYMode.
+	TEYVsFullyTaxable float64
+	TEYVsTreasury     float64
+
+	// Basis and adjustment notes, from Instrument.Notes
+	Notes []string
+}
+
+// TaxCostRatio is the yield lost to tax, in points (Morningstar's tax-cost
+// ratio): pretax less after-tax, before any margin cost or fee. It's 0 for
+// after-tax quotes like AMT Free.
+func (l ResultLine) TaxCostRatio() float64 {
+	return l.Yield * l.EffectiveTaxRate / 100
+}
+
+func (in Inputs) treasuryStateExempt() bool {
+	return in.TreasuryStateExempt == nil || *in.TreasuryStateExempt
+}
+
+// residentOf says whether the holder lives in issuer's state, so its bonds
+// are in-state. An unset state on either side counts as out of state.
+func (in Inputs) residentOf(issuer string) bool {
+	return issuer != "" && strings.EqualFold(issuer, in.ResidentState)
+}
+
+func (in Inputs) enabled(k InstrumentKind) bool {
+	return in.Enabled == nil || in.Enabled[k]
+}
+
+// reports says whether inst gets a Result line.
+func (in Inputs) reports(inst Instrument) bool {
+	if in.Enabled == nil && inst.Optional {
+		return inst.Yield != 0
+	}
+	return in.enabled(inst.Kind)
+}
+
+// Line returns the Result line for kind k, if there is one.
+func (r Result) Line(k InstrumentKind) (ResultLine, bool) {
+	for _, l := range r.Lines {
+		if l.Kind == k {
+			return l, true
+		}
+	}
+	return ResultLine{}, false
+}
+
+// addLine appends l and fills in the matching flat fields, unless an earlier
+// line of the same kind already did.
+func (r *Result) addLine(l ResultLine) {
+	_, seen := r.Line(l.Kind)
+	r.Lines = append(r.Lines, l)
+	if seen {
+		return
+	}
+	switch l.Kind {
+	case FullyTaxableKind:
+		r.FullyTaxableAfterTax, r.FullyTaxableTEY, r.FullyTaxableType = l.AfterTax, l.TEY, l.Basis
+	case TreasuryKind:
+		r.TreasuryAfterTax, r.TreasuryTEY, r.TreasuryType = l.AfterTax, l.TEY, l.Basis
+	case NatlTaxExemptKind:
+		r.NatlAfterTax, r.NatlTEY, r.NatlTaxExemptType = l.AfterTax, l.TEY, l.Basis
+	case StateTaxExemptKind:
+		r.StateAfterTax, r.StateTEY, r.StateTaxExemptType = l.AfterTax, l.TEY, l.Basis
+	case AMTFreeKind:
+		r.AMTFreeAfterTax, r.AMTFreeTEY, r.AMTFreeType = l.AfterTax, l.TEY, l.Basis
+	}
+}
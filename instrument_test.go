@@ -0,0 +1,401 @@
+package main
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestTreasuryFundStateTaxable(t *testing.T) {
+	direct := exampleInputs()
+	direct.Itemize = false
+	fund := direct
+	stateExempt := false
+	fund.TreasuryStateExempt = &stateExempt
+
+	if got, want := Compute(direct).TreasuryAfterTax, 4.5*(1-0.24); !near(got, want) {
+		t.Errorf("direct treasuries net %v, want %v (federal tax only)", got, want)
+	}
+	if got, want := Compute(fund).TreasuryAfterTax, 4.5*(1-0.24-0.093); !near(got, want) {
+		t.Errorf("a fund below the threshold nets %v, want %v (state-taxed too)", got, want)
+	}
+	if got := Compute(fund).TreasuryAfterTax; got != calcAfterTaxYield(4.5, true, true, 0, fund) {
+		t.Errorf("fund treasury nets %v, not calcAfterTaxYield's fully taxable figure", got)
+	}
+}
+
+func TestYieldToWorst(t *testing.T) {
+	in := exampleInputs()
+	in.YieldToWorst = map[InstrumentKind]float64{NatlTaxExemptKind: 3.2}
+	in.YieldToMaturity = map[InstrumentKind]float64{NatlTaxExemptKind: 3.9}
+
+	in.UseYTW = true
+	ytw := Compute(in)
+	natl, _ := ytw.Line(NatlTaxExemptKind)
+	if natl.Yield != 3.2 || !slices.Contains(natl.Notes, "YTW") {
+		t.Errorf("with UseYTW the muni is at %v, notes %v; want 3.2, YTW", natl.Yield, natl.Notes)
+	}
+	if !strings.Contains(ytw.Text, "YTW") {
+		t.Errorf("Text doesn't label the YTW basis:\n%s", ytw.Text)
+	}
+	if treasury, _ := ytw.Line(TreasuryKind); treasury.Yield != 4.5 || len(treasury.Notes) != 0 {
+		t.Errorf("treasury without a YTW is at %v, notes %v; want the stated 4.5", treasury.Yield, treasury.Notes)
+	}
+
+	in.UseYTW = false
+	ytm := Compute(in)
+	natl, _ = ytm.Line(NatlTaxExemptKind)
+	if natl.Yield != 3.9 || !slices.Contains(natl.Notes, "YTM") {
+		t.Errorf("without UseYTW the muni is at %v, notes %v; want 3.9, YTM", natl.Yield, natl.Notes)
+	}
+	if !(ytw.NatlAfterTax < ytm.NatlAfterTax) {
+		t.Errorf("YTW nets %v, not below YTM's %v", ytw.NatlAfterTax, ytm.NatlAfterTax)
+	}
+}
+
+func TestCreditSpread(t *testing.T) {
+	// a 4.6% high-yield muni, normalized to investment grade by taking
+	// 80bp off
+	in := exampleInputs()
+	in.NatlTaxExempt = 4.6
+	in.CreditSpread = map[InstrumentKind]float64{NatlTaxExemptKind: 0.8}
+	res := Compute(in)
+	natl, _ := res.Line(NatlTaxExemptKind)
+	if !near(natl.Yield, 3.8) {
+		t.Errorf("adjusted yield %v, want 3.8", natl.Yield)
+	}
+	// the same after tax as a 3.8% investment-grade muni
+	if want := Compute(exampleInputs()).NatlAfterTax; !near(natl.AfterTax, want) {
+		t.Errorf("after tax %v, want %v", natl.AfterTax, want)
+	}
+	if !slices.Contains(natl.Notes, "credit adj -0.80") {
+		t.Errorf("notes %v, want the adjustment noted", natl.Notes)
+	}
+	if state, _ := res.Line(StateTaxExemptKind); len(state.Notes) != 0 || state.Yield != 3.4 {
+		t.Errorf("unadjusted muni: yield %v, notes %v", state.Yield, state.Notes)
+	}
+}
+
+func TestAMTFreeFunds(t *testing.T) {
+	in := exampleInputs()
+	in.AMTFreeFunds = []NamedYield{{Name: "Fund A", Yield: 3.7}, {Name: "Fund B", Yield: 3.2}}
+	res := Compute(in)
+	var funds []ResultLine
+	for _, l := range res.Lines {
+		if l.Kind == AMTFreeKind {
+			funds = append(funds, l)
+		}
+	}
+	if len(funds) != 2 {
+		t.Fatalf("%d AMT Free lines, want 2", len(funds))
+	}
+	for i, want := range in.AMTFreeFunds {
+		l := funds[i]
+		if !strings.Contains(l.Label, want.Name) || l.AfterTax != want.Yield || !near(l.TEY, want.Yield*res.GrossUp) {
+			t.Errorf("fund %d: %q after tax %v TEY %v; want %q, %v, %v", i, l.Label, l.AfterTax, l.TEY, want.Name, want.Yield, want.Yield*res.GrossUp)
+		}
+	}
+	if !strings.Contains(res.Text, "Fund A") || !strings.Contains(res.Text, "Fund B") {
+		t.Errorf("Text:\n%s", res.Text)
+	}
+	m := res.ToMap()
+	if _, ok := m["amt_free_2_tey"]; !ok {
+		t.Errorf("no amt_free_2_tey in ToMap")
+	}
+
+	// the scalar still works alone
+	if l, _ := Compute(exampleInputs()).Line(AMTFreeKind); l.Label != "AMT Free" || l.AfterTax != 3.7 {
+		t.Errorf("scalar AMTFree line %q at %v", l.Label, l.AfterTax)
+	}
+}
+
+func TestCorporateLine(t *testing.T) {
+	in := exampleInputs()
+	in.Corporate = 5
+	res := Compute(in)
+	corp, ok := res.Line(CorporateKind)
+	if !ok {
+		t.Fatal("no corporate line")
+	}
+	taxable, _ := res.Line(FullyTaxableKind)
+	if corp.Label == taxable.Label || corp.AfterTax != taxable.AfterTax || corp.TEY != taxable.TEY {
+		t.Errorf("corporate %q %v/%v vs fully taxable %q %v/%v; want the same math on its own line",
+			corp.Label, corp.AfterTax, corp.TEY, taxable.Label, taxable.AfterTax, taxable.TEY)
+	}
+	if _, ok := Compute(exampleInputs()).Line(CorporateKind); ok {
+		t.Error("a corporate line without a corporate yield")
+	}
+
+	// a spread normalizes the corporate alone
+	in.CreditSpread = map[InstrumentKind]float64{CorporateKind: 0.5}
+	res = Compute(in)
+	corp, _ = res.Line(CorporateKind)
+	if want := calcAfterTaxYield(4.5, true, true, 0, in); !near(corp.AfterTax, want) || res.FullyTaxableAfterTax != taxable.AfterTax {
+		t.Errorf("with a 50bp spread the corporate nets %v, want %v", corp.AfterTax, want)
+	}
+}
+
+func TestNatlInStateFraction(t *testing.T) {
+	for _, tt := range []struct {
+		fraction float64
+		want     float64 // 5% national muni, 5% state tax on the rest
+	}{
+		{0, 4.75},
+		{0.1, 4.775},
+		{1, 5},
+	} {
+		for _, decimal := range []bool{false, true} {
+			in := Inputs{NatlTaxExempt: 5, FedBracket: 24, StateBracket: 5, NatlInStateFraction: tt.fraction, UseDecimal: decimal}
+			if got := Compute(in).NatlAfterTax; !near(got, tt.want) {
+				t.Errorf("in-state %v (decimal %v): got %v, want %v", tt.fraction, decimal, got, tt.want)
+			}
+			// itemizing changes nothing: there's no federal tax on the
+			// muni to deduct its state tax from
+			in.Itemize = true
+			if got := Compute(in).NatlAfterTax; !near(got, tt.want) {
+				t.Errorf("in-state %v (decimal %v), itemizing: got %v, want %v", tt.fraction, decimal, got, tt.want)
+			}
+		}
+	}
+}
+
+func TestInstrumentOrder(t *testing.T) {
+	insts := []Instrument{
+		{Kind: FullyTaxableKind, Name: "CD", Yield: 5, FedTaxable: true, StateTaxable: true, Order: 2},
+		{Kind: TreasuryKind, Yield: 4.5, FedTaxable: true, Order: 1},
+		{Kind: FullyTaxableKind, Name: "Savings", Yield: 4, FedTaxable: true, StateTaxable: true, Order: 2},
+		{Kind: NatlTaxExemptKind, Yield: 3.5, StateTaxable: true, Order: -1},
+	}
+	want := []string{"Nat'l Tax-Exempt", "Treasury", "CD", "Savings"}
+	res := ComputeInstruments(insts, Inputs{FedBracket: 24, StateBracket: 5})
+
+	var got []string
+	for _, l := range res.Lines {
+		got = append(got, l.Label)
+	}
+	if !slices.Equal(got, want) {
+		t.Fatalf("lines %q, want %q", got, want)
+	}
+	if insts[0].Name != "CD" {
+		t.Error("ComputeInstruments reordered its argument")
+	}
+
+	var md strings.Builder
+	if err := res.WriteMarkdown(&md); err != nil {
+		t.Fatal(err)
+	}
+	for name, out := range map[string]string{"text": res.Text, "markdown": md.String()} {
+		last := -1
+		for _, label := range want {
+			i := strings.Index(out, label)
+			if i < last {
+				t.Errorf("%s renders %q out of order:\n%s", name, label, out)
+			}
+			last = i
+		}
+	}
+}
+
+func TestAgencyStateExempt(t *testing.T) {
+	in := Inputs{Agency: 5, Treasury: 5, FedBracket: 24, StateBracket: 5}
+	afterTax := func(exempt bool) float64 {
+		t.Helper()
+		in := in
+		in.AgencyStateExempt = exempt
+		line, ok := Compute(in).Line(AgencyKind)
+		if !ok || line.Label != "Agency" {
+			t.Fatalf("exempt %v: agency line %+v, %v", exempt, line, ok)
+		}
+		return line.AfterTax
+	}
+	exempt, taxed := afterTax(true), afterTax(false)
+	if !near(exempt, 5*0.76) || !near(taxed, 5*0.71) || exempt <= taxed {
+		t.Errorf("state-exempt agency %v, non-exempt %v; want 3.8 and 3.55", exempt, taxed)
+	}
+	// an exempt agency is taxed like a Treasury
+	if res := Compute(in); !near(exempt, res.TreasuryAfterTax) {
+		t.Errorf("exempt agency %v, Treasury %v", exempt, res.TreasuryAfterTax)
+	}
+	if _, ok := Compute(Inputs{FedBracket: 24}).Line(AgencyKind); ok {
+		t.Error("an agency line without an agency yield")
+	}
+}
+
+func TestTaxCostRatio(t *testing.T) {
+	for _, in := range []Inputs{
+		{FullyTaxable: 5, FedBracket: 24, StateBracket: 5},
+		{FullyTaxable: 5, FedBracket: 37, StateBracket: 13.3, NIIT: true, Itemize: true},
+		// the fee and margin come after tax, so they don't count
+		{FullyTaxable: 5, FedBracket: 24, AdvisoryFee: 1, MarginFraction: 0.5, MarginInterestRate: 6},
+	} {
+		in.AMTFree = 3.7
+		res := Compute(in)
+		taxable, _ := res.Line(FullyTaxableKind)
+		if want := 5 * CombinedTopRate(in) / 100; !near(taxable.TaxCostRatio(), want) {
+			t.Errorf("%+v: fully taxable tax cost %v, want %v", in, taxable.TaxCostRatio(), want)
+		}
+		if got := res.ToMap()["fully_taxable_tax_cost"]; got != taxable.TaxCostRatio() {
+			t.Errorf("%+v: ToMap tax cost %v, line's %v", in, got, taxable.TaxCostRatio())
+		}
+		if amtFree, _ := res.Line(AMTFreeKind); amtFree.TaxCostRatio() != 0 {
+			t.Errorf("%+v: AMT Free tax cost %v, want 0", in, amtFree.TaxCostRatio())
+		}
+	}
+}
+
+func TestBondFundNAVDrift(t *testing.T) {
+	for _, drift := range []float64{0, 0.5, -1} {
+		in := Inputs{BondFund: 5, FullyTaxable: 5, FedBracket: 24, StateBracket: 5, BondFundNAVDrift: drift}
+		res := Compute(in)
+		fund, ok := res.Line(BondFundKind)
+		if !ok {
+			t.Fatalf("drift %v: no bond fund line", drift)
+		}
+		// the coupon is taxed, the drift isn't
+		if want := 5*0.71 + drift; !near(fund.AfterTax, want) {
+			t.Errorf("drift %v: after tax %v, want %v", drift, fund.AfterTax, want)
+		}
+		if !near(fund.TEY, fund.AfterTax*res.GrossUp) {
+			t.Errorf("drift %v: TEY %v, want %v", drift, fund.TEY, fund.AfterTax*res.GrossUp)
+		}
+		if !near(fund.EffectiveTaxRate, 29) || !near(fund.TaxCostRatio(), 5*0.29) {
+			t.Errorf("drift %v: tax rate %v, tax cost %v; want the coupon's 29%%, 1.45", drift, fund.EffectiveTaxRate, fund.TaxCostRatio())
+		}
+	}
+	if _, ok := Compute(Inputs{FedBracket: 24, BondFundNAVDrift: 1}).Line(BondFundKind); ok {
+		t.Error("a bond fund line without a bond fund yield")
+	}
+}
+
+func TestTaxFreeMMF(t *testing.T) {
+	in := Inputs{TaxFreeMMF: 3, NatlTaxExempt: 3, StateTaxExempt: 3, FedBracket: 32, StateBracket: 6}
+	mmf := func(in Inputs) ResultLine {
+		t.Helper()
+		res := Compute(in)
+		line, ok := res.Line(TaxFreeMMFKind)
+		if !ok || line.Label != "Tax-Free MMF" {
+			t.Fatalf("MMF line %+v, %v", line, ok)
+		}
+		return line
+	}
+
+	// national: state-taxed like the national muni
+	res := Compute(in)
+	if got := mmf(in).AfterTax; !near(got, 3*0.94) || !near(got, res.NatlAfterTax) {
+		t.Errorf("national MMF nets %v, want %v like the national muni", got, 3*0.94)
+	}
+	in.TaxFreeMMFSingleState = true
+	if got := mmf(in).AfterTax; !near(got, 3) || !near(got, res.StateAfterTax) {
+		t.Errorf("single-state MMF nets %v, want 3 like the state muni", got)
+	}
+
+	// its AMT exposure is its own
+	in.AMT, in.AMTBracketIndex, in.TaxFreeMMFAMTPct = true, 4, 50
+	if got := mmf(in).AfterTax; !near(got, 3*(1-0.5*0.28)) {
+		t.Errorf("single-state MMF half under 28%% AMT nets %v, want %v", got, 3*(1-0.5*0.28))
+	}
+	if got := Compute(in).StateAfterTax; !near(got, 3) {
+		t.Errorf("the MMF's AMT exposure moved the state muni to %v", got)
+	}
+	if _, ok := Compute(Inputs{FedBracket: 24}).Line(TaxFreeMMFKind); ok {
+		t.Error("an MMF line without an MMF yield")
+	}
+}
+
+func TestAuthorityBond(t *testing.T) {
+	in := Inputs{AuthorityBond: 4, NatlTaxExempt: 4, StateTaxExempt: 4, FedBracket: 24, StateBracket: 6, ResidentState: "NY", AuthorityIssuerState: "NY"}
+	authority := func(in Inputs) float64 {
+		t.Helper()
+		line, ok := Compute(in).Line(AuthorityBondKind)
+		if !ok {
+			t.Fatal("no authority bond line")
+		}
+		return line.AfterTax
+	}
+	for _, amt := range []bool{false, true} {
+		in := in
+		if amt {
+			in.AMT, in.AMTBracketIndex = true, 4
+			in.AuthorityAmTPct, in.NatlAmTPct, in.StateAmTPct = 50, 50, 50
+		}
+		// a resident holds it double exempt, like the state muni
+		res := Compute(in)
+		if got := authority(in); !near(got, res.StateAfterTax) {
+			t.Errorf("AMT %v, resident: nets %v, want %v like the state muni", amt, got, res.StateAfterTax)
+		}
+		in.ResidentState = "ny"
+		if got := authority(in); !near(got, res.StateAfterTax) {
+			t.Errorf("AMT %v, resident in lower case: nets %v, want %v", amt, got, res.StateAfterTax)
+		}
+		// anyone else pays state tax, like on the national muni
+		for _, resident := range []string{"NJ", ""} {
+			in.ResidentState = resident
+			if got := authority(in); !near(got, res.NatlAfterTax) {
+				t.Errorf("AMT %v, resident of %q: nets %v, want %v like the national muni", amt, resident, got, res.NatlAfterTax)
+			}
+		}
+	}
+	if got := authority(in); !near(got, 4) {
+		t.Errorf("resident nets %v, want 4", got)
+	}
+	in.AuthorityIssuerState = "CT"
+	if got := authority(in); !near(got, 4*0.94) {
+		t.Errorf("out-of-state issuer nets %v, want %v", got, 4*0.94)
+	}
+}
+
+func TestAMTRateOverride(t *testing.T) {
+	in := Inputs{FedBracket: 24, AMT: true, AMTBracketIndex: 1}
+	insts := []Instrument{
+		{Kind: NatlTaxExemptKind, Yield: 4, AMTPct: 50, AMTRateOverride: 20},
+		{Kind: StateTaxExemptKind, Yield: 4, AMTPct: 50, AMTRateOverride: 35},
+		{Kind: AuthorityBondKind, Yield: 4, AMTPct: 50},
+		{Kind: FullyTaxableKind, Yield: 5, FedTaxable: true, AMTRateOverride: 35},
+	}
+	// half of each muni at its own rate, or the global 26%; the override
+	// doesn't touch fully taxable income
+	want := []float64{4 * (1 - 0.5*0.20), 4 * (1 - 0.5*0.35), 4 * (1 - 0.5*0.26), 5 * (1 - 0.26)}
+	for i, l := range ComputeInstruments(insts, in).Lines {
+		if !near(l.AfterTax, want[i]) {
+			t.Errorf("%s: nets %v, want %v", l.Label, l.AfterTax, want[i])
+		}
+	}
+	// without AMT the overrides are unused
+	in.AMT = false
+	for _, l := range ComputeInstruments(insts[:3], in).Lines {
+		if !near(l.AfterTax, 4) {
+			t.Errorf("%s without AMT: nets %v, want 4", l.Label, l.AfterTax)
+		}
+	}
+}
+
+func TestTaxableMuni(t *testing.T) {
+	in := Inputs{TaxableMuni: 5, FullyTaxable: 5, Treasury: 5, FedBracket: 32, StateBracket: 6, ResidentState: "CA", TaxableMuniIssuerState: "CA"}
+	muni := func(in Inputs) ResultLine {
+		t.Helper()
+		line, ok := Compute(in).Line(TaxableMuniKind)
+		if !ok || line.Label != "Taxable Muni" {
+			t.Fatalf("taxable muni line %+v, %v", line, ok)
+		}
+		return line
+	}
+	// in state: federally taxed, state exempt, like a Treasury
+	if got := muni(in).AfterTax; !near(got, 5*(1-0.32)) {
+		t.Errorf("in-state holder nets %v, want %v", got, 5*(1-0.32))
+	}
+	in.AMT, in.AMTBracketIndex = true, 4
+	if got, want := muni(in).AfterTax, Compute(in).TreasuryAfterTax; !near(got, want) {
+		t.Errorf("in-state holder under AMT nets %v, want %v like the Treasury", got, want)
+	}
+	in.AMT = false
+	// out of state: fully taxable
+	for _, issuer := range []string{"NV", ""} {
+		in.TaxableMuniIssuerState = issuer
+		if got, want := muni(in).AfterTax, Compute(in).FullyTaxableAfterTax; !near(got, want) || !near(got, 5*(1-0.38)) {
+			t.Errorf("issuer %q: nets %v, want %v like fully taxable", issuer, got, want)
+		}
+	}
+	if _, ok := Compute(Inputs{FedBracket: 24}).Line(TaxableMuniKind); ok {
+		t.Error("a taxable muni line without a yield")
+	}
+}
@@ -0,0 +1,33 @@
+package main
+
+import (
+	"fmt"
+	"math"
+)
+
+// itemizedDeductions is what itemizing would deduct ($) under AutoItemize.
+func (in Inputs) itemizedDeductions() float64 {
+	salt := in.stateRate() / 100 * math.Max(in.TaxableIncome, 0)
+	if in.SALTCap > 0 {
+		salt = math.Min(salt, in.SALTCap)
+	}
+	return in.OtherItemized + salt
+}
+
+// autoItemize is in with Itemize decided by the deductions, under
+// AutoItemize; a tie takes the standard deduction.
+func (in Inputs) autoItemize() Inputs {
+	if in.AutoItemize {
+		in.Itemize = in.itemizedDeductions() > in.StandardDeduction
+	}
+	return in
+}
+
+// autoItemizeFeature describes autoItemize's choice for AppliedFeatures.
+func (in Inputs) autoItemizeFeature() string {
+	itemized := in.itemizedDeductions()
+	if in.Itemize {
+		return fmt.Sprintf("auto-itemize: itemized ($%.0f over $%.0f standard)", itemized, in.StandardDeduction)
+	}
+	return fmt.Sprintf("auto-itemize: standard deduction ($%.0f over $%.0f itemized)", in.StandardDeduction, itemized)
+}
@@ -0,0 +1,43 @@
+package main
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestAutoItemize(t *testing.T) {
+	base := Inputs{FullyTaxable: 5, NatlTaxExempt: 3.5, FedBracket: 24, StateBracket: 5,
+		AutoItemize: true, TaxableIncome: 200000, StandardDeduction: 29200}
+	standard, itemized := 5*(1-0.24-0.05), 5*(1-0.24-0.05*0.76)
+	for _, tt := range []struct {
+		name     string
+		edit     func(*Inputs)
+		afterTax float64
+		feature  string
+	}{
+		// $10,000 of state tax on $200,000
+		{"standard", func(in *Inputs) { in.OtherItemized = 10000 }, standard,
+			"auto-itemize: standard deduction ($29200 over $20000 itemized)"},
+		{"itemized", func(in *Inputs) { in.OtherItemized = 25000 }, itemized,
+			"auto-itemize: itemized ($35000 over $29200 standard)"},
+		{"a tie takes the standard deduction", func(in *Inputs) { in.OtherItemized = 19200 }, standard,
+			"auto-itemize: standard deduction ($29200 over $29200 itemized)"},
+		{"standard overrides Itemize", func(in *Inputs) { in.OtherItemized, in.Itemize = 10000, true }, standard,
+			"auto-itemize: standard deduction ($29200 over $20000 itemized)"},
+		// $20,000 of state tax on $400,000, capped at $10,000
+		{"SALT cap tips it to standard", func(in *Inputs) { in.OtherItemized, in.TaxableIncome, in.SALTCap = 15000, 400000, 10000 }, standard,
+			"auto-itemize: standard deduction ($29200 over $25000 itemized)"},
+		{"itemized despite the cap", func(in *Inputs) { in.OtherItemized, in.TaxableIncome, in.SALTCap = 25000, 400000, 10000 }, itemized,
+			"auto-itemize: itemized ($35000 over $29200 standard)"},
+	} {
+		in := base
+		tt.edit(&in)
+		res := Compute(in)
+		if !near(res.FullyTaxableAfterTax, tt.afterTax) {
+			t.Errorf("%s: fully taxable nets %v, want %v", tt.name, res.FullyTaxableAfterTax, tt.afterTax)
+		}
+		if !slices.Contains(res.AppliedFeatures, tt.feature) {
+			t.Errorf("%s: applied features %q, want %q", tt.name, res.AppliedFeatures, tt.feature)
+		}
+	}
+}
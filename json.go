@@ -0,0 +1,244 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+)
+
+// JSON has no NaN, so a NaN FullyTaxable (the "use a synthetic 1%" fallback)
+// travels as null, both in Inputs and in the fully-taxable Result fields.
+// Result numbers that aren't finite (TEYs at a 100% tax rate, say) are null
+// too, and come back as NaN.
+
+func (in Inputs) MarshalJSON() ([]byte, error) {
+	type plain Inputs
+	return json.Marshal(struct {
+		plain
+		FullyTaxable *float64
+	}{plain(in), nullIfNaN(in.FullyTaxable)})
+}
+
+func (in *Inputs) UnmarshalJSON(b []byte) error {
+	type plain Inputs
+	aux := struct {
+		*plain
+		FullyTaxable *float64
+	}{plain: (*plain)(in)}
+	if err := json.Unmarshal(b, &aux); err != nil {
+		return err
+	}
+	in.FullyTaxable = math.NaN()
+	if aux.FullyTaxable != nil {
+		in.FullyTaxable = *aux.FullyTaxable
+	}
+	return nil
+}
+
+func (r Result) MarshalJSON() ([]byte, error) {
+	type plain Result
+	return json.Marshal(struct {
+		plain
+		FullyTaxableAfterTax *float64
+		FullyTaxableTEY      *float64
+		TreasuryAfterTax     *float64
+		TreasuryTEY          *float64
+		NatlAfterTax         *float64
+		NatlTEY              *float64
+		StateAfterTax        *float64
+		StateTEY             *float64
+		AMTFreeAfterTax      *float64
+		AMTFreeTEY           *float64
+		GrossUp              *float64
+	}{plain(r), nullIfNonFinite(r.FullyTaxableAfterTax), nullIfNonFinite(r.FullyTaxableTEY),
+		nullIfNonFinite(r.TreasuryAfterTax), nullIfNonFinite(r.TreasuryTEY),
+		nullIfNonFinite(r.NatlAfterTax), nullIfNonFinite(r.NatlTEY),
+		nullIfNonFinite(r.StateAfterTax), nullIfNonFinite(r.StateTEY),
+		nullIfNonFinite(r.AMTFreeAfterTax), nullIfNonFinite(r.AMTFreeTEY),
+		nullIfNonFinite(r.GrossUp)})
+}
+
+func (r *Result) UnmarshalJSON(b []byte) error {
+	type plain Result
+	aux := struct {
+		*plain
+		FullyTaxableAfterTax *float64
+		FullyTaxableTEY      *float64
+		TreasuryAfterTax     *float64
+		TreasuryTEY          *float64
+		NatlAfterTax         *float64
+		NatlTEY              *float64
+		StateAfterTax        *float64
+		StateTEY             *float64
+		AMTFreeAfterTax      *float64
+		AMTFreeTEY           *float64
+		GrossUp              *float64
+	}{plain: (*plain)(r)}
+	if err := json.Unmarshal(b, &aux); err != nil {
+		return err
+	}
+	r.GrossUp = nanIfNull(aux.GrossUp)
+	r.FullyTaxableAfterTax = nanIfNull(aux.FullyTaxableAfterTax)
+	r.FullyTaxableTEY = nanIfNull(aux.FullyTaxableTEY)
+	r.TreasuryAfterTax = nanIfNull(aux.TreasuryAfterTax)
+	r.TreasuryTEY = nanIfNull(aux.TreasuryTEY)
+	r.NatlAfterTax = nanIfNull(aux.NatlAfterTax)
+	r.NatlTEY = nanIfNull(aux.NatlTEY)
+	r.StateAfterTax = nanIfNull(aux.StateAfterTax)
+	r.StateTEY = nanIfNull(aux.StateTEY)
+	r.AMTFreeAfterTax = nanIfNull(aux.AMTFreeAfterTax)
+	r.AMTFreeTEY = nanIfNull(aux.AMTFreeTEY)
+	return nil
+}
+
+func (l ResultLine) MarshalJSON() ([]byte, error) {
+	type plain ResultLine
+	return json.Marshal(struct {
+		plain
+		Yield             *float64
+		AfterTax          *float64
+		TEY               *float64
+		EffectiveTaxRate  *float64
+		AfterTaxAfterFee  *float64
+		TEYSpreadBps      *float64
+		TEYVsFullyTaxable *float64
+		TEYVsTreasury     *float64
+	}{plain(l), nullIfNonFinite(l.Yield), nullIfNonFinite(l.AfterTax), nullIfNonFinite(l.TEY),
+		nullIfNonFinite(l.EffectiveTaxRate), nullIfNonFinite(l.AfterTaxAfterFee), nullIfNonFinite(l.TEYSpreadBps),
+		nullIfNonFinite(l.TEYVsFullyTaxable), nullIfNonFinite(l.TEYVsTreasury)})
+}
+
+func (l *ResultLine) UnmarshalJSON(b []byte) error {
+	type plain ResultLine
+	aux := struct {
+		*plain
+		Yield             *float64
+		AfterTax          *float64
+		TEY               *float64
+		EffectiveTaxRate  *float64
+		AfterTaxAfterFee  *float64
+		TEYSpreadBps      *float64
+		TEYVsFullyTaxable *float64
+		TEYVsTreasury     *float64
+	}{plain: (*plain)(l)}
+	if err := json.Unmarshal(b, &aux); err != nil {
+		return err
+	}
+	l.Yield = nanIfNull(aux.Yield)
+	l.EffectiveTaxRate = nanIfNull(aux.EffectiveTaxRate)
+	l.AfterTax = nanIfNull(aux.AfterTax)
+	l.TEY = nanIfNull(aux.TEY)
+	l.AfterTaxAfterFee = nanIfNull(aux.AfterTaxAfterFee)
+	l.TEYSpreadBps = nanIfNull(aux.TEYSpreadBps)
+	l.TEYVsFullyTaxable = nanIfNull(aux.TEYVsFullyTaxable)
+	l.TEYVsTreasury = nanIfNull(aux.TEYVsTreasury)
+	return nil
+}
+
+func nullIfNaN(f float64) *float64 {
+	if math.IsNaN(f) {
+		return nil
+	}
+	return &f
+}
+
+func nullIfNonFinite(f float64) *float64 {
+	if !isFinite(f) {
+		return nil
+	}
+	return &f
+}
+
+func nanIfNull(f *float64) float64 {
+	if f == nil {
+		return math.NaN()
+	}
+	return *f
+}
+
+// instrumentKeys are the stable JSON names of each InstrumentKind.
+var instrumentKeys = map[InstrumentKind]string{
+	FullyTaxableKind:   "fully_taxable",
+	TreasuryKind:       "treasury",
+	NatlTaxExemptKind:  "natl",
+	StateTaxExemptKind: "state",
+	AMTFreeKind:        "amt_free",
+	TBillKind:          "tbill",
+	CorporateKind:      "corporate",
+	AgencyKind:         "agency",
+	BondFundKind:       "bond_fund",
+	TotalReturnKind:    "total_return",
+	BalancedFundKind:   "balanced_fund",
+	TaxFreeMMFKind:     "tax_free_mmf",
+	AuthorityBondKind:  "authority_bond",
+	TaxableMuniKind:    "taxable_muni",
+}
+
+// instrumentKeyList is the instrument keys in Result order.
+func instrumentKeyList() []string {
+	var keys []string
+	for _, k := range standardKinds() {
+		keys = append(keys, instrumentKeys[k])
+	}
+	return keys
+}
+
+func (k InstrumentKind) MarshalText() ([]byte, error) {
+	s, ok := instrumentKeys[k]
+	if !ok {
+		return nil, fmt.Errorf("unknown instrument kind %d", int(k))
+	}
+	return []byte(s), nil
+}
+
+func (k *InstrumentKind) UnmarshalText(b []byte) error {
+	for kind, s := range instrumentKeys {
+		if s == string(b) {
+			*k = kind
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown instrument %q", b)
+}
+
+func (t YieldType) MarshalText() ([]byte, error) {
+	switch t {
+	case SECYield:
+		return []byte("sec"), nil
+	case DistributionYield:
+		return []byte("distribution"), nil
+	default:
+		return nil, fmt.Errorf("unknown yield type %d", int(t))
+	}
+}
+
+func (t *YieldType) UnmarshalText(b []byte) error {
+	switch string(b) {
+	case "sec":
+		*t = SECYield
+	case "distribution":
+		*t = DistributionYield
+	default:
+		return fmt.Errorf("unknown yield type %q", b)
+	}
+	return nil
+}
+
+func (c Compounding) MarshalText() ([]byte, error) {
+	switch c {
+	case Annual, SemiAnnual, Monthly:
+		return []byte(c.String()), nil
+	default:
+		return nil, fmt.Errorf("unknown compounding %d", int(c))
+	}
+}
+
+func (c *Compounding) UnmarshalText(b []byte) error {
+	for _, v := range []Compounding{Annual, SemiAnnual, Monthly} {
+		if v.String() == string(b) {
+			*c = v
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown compounding %q", b)
+}
@@ -0,0 +1,64 @@
+package main
+
+import (
+	"fmt"
+	"io"
+)
+
+// ladderYear is the year whose statutory brackets BracketLadder uses.
+const ladderYear = 2024
+
+// LadderRow is one statutory bracket of a BracketLadder.
+type LadderRow struct {
+	Bracket float64 // federal rate (%)
+	Result  Result
+}
+
+// BracketLadder is in computed at each of 2024's statutory federal brackets
+// (10, 12, 22, 24, 32, 35 and 37%), lowest first: the "where do I stand"
+// view, without picking a sweep. Only FedBracket changes from row to row.
+// It fails if DefaultRateTables has no 2024 tables.
+func BracketLadder(in Inputs) ([]LadderRow, error) {
+	t, ok := DefaultRateTables.Lookup(ladderYear)
+	if !ok {
+		return nil, fmt.Errorf("no rate tables for %d", ladderYear)
+	}
+	rows := make([]LadderRow, 0, len(t.Federal))
+	for _, b := range t.Federal {
+		in.FedBracket = b.Rate
+		rows = append(rows, LadderRow{Bracket: b.Rate, Result: Compute(in)})
+	}
+	return rows, nil
+}
+
+// WriteBracketLadder writes rows as a text table, one row per bracket and an
+// after-tax column per line (labeled from the first row), e.g.
+//
+//	Bracket   Fully Taxable  Treasury  ...
+//	    10%         4.082%    4.050%  ...
+func WriteBracketLadder(w io.Writer, rows []LadderRow) error {
+	ew := &errWriter{w: w}
+	if len(rows) == 0 {
+		return nil
+	}
+	labels := rows[0].Result.Lines
+	widths := make([]int, len(labels))
+	fmt.Fprintf(ew, "%-8s", "Bracket")
+	for i, l := range labels {
+		widths[i] = max(len(l.Label), 7)
+		fmt.Fprintf(ew, "  %*s", widths[i], l.Label)
+	}
+	io.WriteString(ew, "\n")
+	for _, r := range rows {
+		fmt.Fprintf(ew, "%7g%%", r.Bracket)
+		for i := range labels {
+			cell := ""
+			if i < len(r.Result.Lines) {
+				cell = fmt.Sprintf("%.3f%%", r.Result.Lines[i].AfterTax)
+			}
+			fmt.Fprintf(ew, "  %*s", widths[i], cell)
+		}
+		io.WriteString(ew, "\n")
+	}
+	return ew.err
+}
@@ -0,0 +1,54 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestBracketLadder(t *testing.T) {
+	rows, err := BracketLadder(exampleInputs())
+	if err != nil {
+		t.Fatal(err)
+	}
+	brackets := []float64{10, 12, 22, 24, 32, 35, 37}
+	if len(rows) != len(brackets) {
+		t.Fatalf("%d rows, want %d", len(rows), len(brackets))
+	}
+	for i, r := range rows {
+		if r.Bracket != brackets[i] {
+			t.Errorf("row %d: bracket %v, want %v", i, r.Bracket, brackets[i])
+		}
+		if i == 0 {
+			continue
+		}
+		// federally taxable lines lose after-tax yield at every step up
+		prev := rows[i-1].Result
+		if !(r.Result.FullyTaxableAfterTax < prev.FullyTaxableAfterTax) {
+			t.Errorf("%v%%: fully taxable %v, not below %v", r.Bracket, r.Result.FullyTaxableAfterTax, prev.FullyTaxableAfterTax)
+		}
+		if !(r.Result.TreasuryAfterTax < prev.TreasuryAfterTax) {
+			t.Errorf("%v%%: treasury %v, not below %v", r.Bracket, r.Result.TreasuryAfterTax, prev.TreasuryAfterTax)
+		}
+	}
+}
+
+func TestWriteBracketLadder(t *testing.T) {
+	rows, err := BracketLadder(exampleInputs())
+	if err != nil {
+		t.Fatal(err)
+	}
+	var b strings.Builder
+	if err := WriteBracketLadder(&b, rows); err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSuffix(b.String(), "\n"), "\n")
+	if len(lines) != 1+len(rows) {
+		t.Fatalf("%d lines, want a header and %d rows:\n%s", len(lines), len(rows), b.String())
+	}
+	if !strings.HasPrefix(lines[0], "Bracket") || !strings.Contains(lines[0], "Fully Taxable") {
+		t.Errorf("header %q", lines[0])
+	}
+	if !strings.HasPrefix(strings.TrimSpace(lines[1]), "10%") || !strings.HasPrefix(strings.TrimSpace(lines[7]), "37%") {
+		t.Errorf("rows don't run 10%% to 37%%:\n%s", b.String())
+	}
+}
@@ -0,0 +1,73 @@
+package main
+
+import (
+	"fmt"
+	"math"
+)
+
+// FilingStatus is a federal filing status; it picks the LTCG breakpoints.
+type FilingStatus int
+
+const (
+	Single FilingStatus = iota // the default
+	MarriedJoint
+	MarriedSeparate
+	HeadOfHousehold
+)
+
+func (s FilingStatus) String() string {
+	switch s {
+	case Single:
+		return "single"
+	case MarriedJoint:
+		return "married-joint"
+	case MarriedSeparate:
+		return "married-separate"
+	case HeadOfHousehold:
+		return "head-of-household"
+	default:
+		return "unknown"
+	}
+}
+
+func (s FilingStatus) MarshalText() ([]byte, error) {
+	switch s {
+	case Single, MarriedJoint, MarriedSeparate, HeadOfHousehold:
+		return []byte(s.String()), nil
+	default:
+		return nil, fmt.Errorf("unknown filing status %d", int(s))
+	}
+}
+
+func (s *FilingStatus) UnmarshalText(b []byte) error {
+	for _, v := range []FilingStatus{Single, MarriedJoint, MarriedSeparate, HeadOfHousehold} {
+		if v.String() == string(b) {
+			*s = v
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown filing status %q", b)
+}
+
+// LTCGRate is the federal rate (%) on qualified dividends and long-term
+// gains, 0, 15 or 20, for taxableIncome in year under status, from
+// DefaultRateTables. It's the rate at the top of taxableIncome, which should
+// include the qualified income itself. NaN if year or status has no table.
+func LTCGRate(taxableIncome float64, status FilingStatus, year int) float64 {
+	t, ok := DefaultRateTables.Lookup(year)
+	if !ok {
+		return math.NaN()
+	}
+	bp, ok := t.LTCG[status]
+	if !ok {
+		return math.NaN()
+	}
+	switch {
+	case taxableIncome > bp[1]:
+		return 20
+	case taxableIncome > bp[0]:
+		return 15
+	default:
+		return 0
+	}
+}
@@ -0,0 +1,60 @@
+package main
+
+import (
+	"math"
+	"testing"
+)
+
+func TestLTCGRate(t *testing.T) {
+	for _, tt := range []struct {
+		income float64
+		status FilingStatus
+		want   float64
+	}{
+		{20000, Single, 0},
+		{47025, Single, 0}, // top of the 0% bracket
+		{47026, Single, 15},
+		{518900, Single, 15},
+		{518901, Single, 20},
+		{94050, MarriedJoint, 0},
+		{94051, MarriedJoint, 15},
+		{63000, HeadOfHousehold, 0},
+		{64000, HeadOfHousehold, 15},
+	} {
+		if got := LTCGRate(tt.income, tt.status, 2024); got != tt.want {
+			t.Errorf("LTCGRate(%v, %v, 2024) = %v, want %v", tt.income, tt.status, got, tt.want)
+		}
+	}
+	if got := LTCGRate(50000, Single, 1900); !math.IsNaN(got) {
+		t.Errorf("1900: %v, want NaN", got)
+	}
+	if got := LTCGRate(50000, FilingStatus(9), 2024); !math.IsNaN(got) {
+		t.Errorf("unknown status: %v, want NaN", got)
+	}
+}
+
+func TestZeroLTCGBracket(t *testing.T) {
+	stock := TotalReturnInstrument{Name: "Dividend Fund", DividendYield: 4, QualifiedFraction: 1}
+	run := func(income float64) (Inputs, float64) {
+		t.Helper()
+		in, err := Inputs{TaxableIncome: income, StateBracket: 5, TotalReturn: stock}.ForYear(2024)
+		if err != nil {
+			t.Fatal(err)
+		}
+		line, _ := Compute(in).Line(TotalReturnKind)
+		return in, line.AfterTax
+	}
+
+	// a low earner owes only state tax on qualified dividends
+	in, afterTax := run(40000)
+	if in.QDIRate != 0 || in.FedBracket != 12 {
+		t.Errorf("$40,000: QDIRate %v, bracket %v; want 0 and 12", in.QDIRate, in.FedBracket)
+	}
+	if !near(afterTax, 4*0.95) {
+		t.Errorf("$40,000: dividends net %v, want %v", afterTax, 4*0.95)
+	}
+	in, afterTax = run(60000)
+	if in.QDIRate != 15 || !near(afterTax, 4*0.80) {
+		t.Errorf("$60,000: QDIRate %v, dividends net %v; want 15 and %v", in.QDIRate, afterTax, 4*0.80)
+	}
+}
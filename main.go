@@ -3,6 +3,10 @@ package main
 import (
 	"fmt"
 	"math"
+	"os"
+	"slices"
+	"sort"
+	"strings"
 )
 
 // Inputs that in JS came from the form
@@ -15,6 +19,53 @@ type Inputs struct {
 	StateTaxExempt float64
 	StateAmTPct    float64 // AMT-affected portion (%) for state tax-exempt
 	AMTFree        float64 // already "after-tax" yield in the original JS
+	Corporate      float64 // corporate bond, taxed like FullyTaxable but reported apart
+	Agency         float64 // GSE agency bond; federally taxable, see AgencyStateExempt
+	BondFund       float64 // taxable bond fund's income yield
+
+	// Expected annual NAV change (%) of the bond fund, counted in its
+	// after-tax total return but not taxed (see Instrument.NAVDriftPct)
+	BondFundNAVDrift float64
+
+	// Share (0..1) of the national muni's income from in-state bonds, which
+	// the state doesn't tax. The rest is state-taxed as usual.
+	NatlInStateFraction float64
+
+	// Tax-exempt money market fund: federally exempt but for TaxFreeMMFAMTPct
+	// (%) under AMT, and state-taxable unless it's a single-state fund
+	TaxFreeMMF            float64
+	TaxFreeMMFAMTPct      float64
+	TaxFreeMMFSingleState bool
+
+	// Bond from a public authority (turnpike, water district): federally
+	// exempt but for AuthorityAmTPct (%) under AMT, and state-exempt only
+	// to residents of AuthorityIssuerState (see ResidentState)
+	AuthorityBond        float64
+	AuthorityAmTPct      float64
+	AuthorityIssuerState string
+
+	// Taxable muni (e.g. a Build America Bond): federally taxable, with no
+	// AMT to consider, and state-exempt only to residents of
+	// TaxableMuniIssuerState
+	TaxableMuni            float64
+	TaxableMuniIssuerState string
+
+	// Dividend-plus-appreciation holding, e.g. a stock
+	TotalReturn TotalReturnInstrument
+
+	// Fund paying both ordinary interest and qualified dividends
+	Balanced BalancedFund
+
+	// Several AMT Free funds to compare, each with its own line. When set,
+	// these replace the single AMTFree yield.
+	AMTFreeFunds []NamedYield
+
+	// Basis each yield above was quoted on (zero value is SEC 30-day)
+	FullyTaxableType   YieldType
+	TreasuryType       YieldType
+	NatlTaxExemptType  YieldType
+	StateTaxExemptType YieldType
+	AMTFreeType        YieldType
 
 	// Tax settings
 	FedBracket   float64 // e.g., 24 for 24%
@@ -23,53 +74,356 @@ type Inputs struct {
 	AMT          bool    // subject to AMT?
 
 	// AMT bracket (radio group in JS). Use 0..4 to match original logic:
-	// 0 or 1 => 26%; 2 => 32.5%; 3 => 35%; 4 => 28%
+	// 0 or 1 => 26%; 2 => 32.5%; 3 => 35%; 4 => 28%. 0 is the zero value,
+	// for when no button was picked, and means the same 26% as 1 so
+	// existing indexes keep working. Anything else is an error to
+	// Validate.
 	AMTBracketIndex int
+
+	// Federal rate (%) on qualified dividends and long-term gains, e.g. 15;
+	// 0 for those in the 0% LTCG bracket
+	QDIRate float64
+
+	// Local (city/county) income tax rate (%), on state-taxable income and
+	// deducted with the state tax, like NYC's
+	LocalBracket float64
+
+	// Whether the 3.8% net investment income tax applies (federally
+	// taxable interest only; munis are exempt)
+	NIIT bool
+
+	// MAGI and net investment income ($), to apply NIIT only to the
+	// investment income over FilingStatus's threshold. No MAGI means the
+	// flat 3.8%.
+	MAGI                float64
+	NetInvestmentIncome float64
+
+	// Taxable income ($) and filing status, for looking up FedBracket and
+	// QDIRate in a year's rate tables with ForYear. Compute itself only uses
+	// the rates.
+	TaxableIncome float64
+	FilingStatus  FilingStatus
+
+	// Let Compute choose Itemize: itemize if OtherItemized ($, everything
+	// but state and local income tax) plus the state and local tax on
+	// TaxableIncome, capped at SALTCap ($, 0 for no cap), beats
+	// StandardDeduction ($). The choice is in Result.AppliedFeatures.
+	AutoItemize       bool
+	StandardDeduction float64
+	OtherItemized     float64
+	SALTCap           float64
+
+	// StateBracket is already net of the federal deduction, so don't
+	// apply it again
+	StateRateIsEffective bool
+
+	// The state lets federal income tax be deducted (as AL, IA, MO, MT and
+	// OR do, in part or whole), so state tax is on income net of it
+	StateDeductsFederal bool
+
+	// Federal rate (%) at which the itemized state-tax deduction is actually
+	// realized, if the deduction straddles a lower bracket. 0 means FedBracket.
+	DeductionBenefitRate float64
+
+	// Whether the treasury line is state-exempt. nil means true, as for
+	// direct treasuries; set false for a treasury fund that misses a state's
+	// direct-obligation threshold (e.g. CA/NY/CT's 50% rule).
+	TreasuryStateExempt *bool
+
+	// Whether the agency bond is state-exempt, as FHLB and Farmer Mac
+	// bonds are. Fannie Mae and Freddie Mac bonds aren't.
+	AgencyStateExempt bool
+
+	// Cross-border income: when SourceState differs from ResidentState,
+	// the source state's tax (StateTaxCredit, %) is paid and the resident
+	// state (at StateBracket) credits it, up to its own rate.
+	ResidentState  string
+	SourceState    string
+	StateTaxCredit float64
+
+	// Which instruments to report. nil means all of them (optional ones like
+	// the T-bill only once their yield is set); otherwise only kinds mapped
+	// to true get a Result line.
+	Enabled map[InstrumentKind]bool
+
+	// Advisory (AUM) fee in yield points, taken off every line after tax.
+	// It's only deducted for tax purposes if FeeIsDeductible is set.
+	AdvisoryFee     float64
+	FeeIsDeductible bool
+
+	// Share (0..1) of each taxable position bought on margin, and the margin
+	// loan's rate (%). The after-tax interest comes off those lines'
+	// after-tax yields; see marginCost for the deduction rules.
+	MarginFraction     float64
+	MarginInterestRate float64
+
+	// Empirical haircut (basis points) off taxable lines' after-tax yields,
+	// for drag like turnover that isn't modeled as tax. It stops at 0 and
+	// doesn't count in EffectiveTaxRate.
+	TaxDragBps float64
+
+	// Yield-to-worst and yield-to-maturity quotes for callable instruments.
+	// With UseYTW, an instrument's YTW (if given) replaces its stated yield;
+	// otherwise its YTM does.
+	YieldToWorst    map[InstrumentKind]float64
+	YieldToMaturity map[InstrumentKind]float64
+	UseYTW          bool
+
+	// Credit spread (yield points) to take off each instrument's yield before
+	// tax, e.g. to compare a high-yield muni with an investment-grade one.
+	CreditSpread map[InstrumentKind]float64
+
+	// How often each instrument's quoted yield compounds, for yields quoted
+	// per month or half-year. Missing kinds are Annual.
+	Compounding map[InstrumentKind]Compounding
+
+	// Reinvestment assumption for the kinds in Reinvest: their coupons are
+	// reinvested at ReinvestmentRate (%) for ReinvestmentYears, and the
+	// resulting total yield (see EffectiveYieldWithReinvestment) is what's
+	// taxed. Kinds not in Reinvest, the default, keep their current yield.
+	ReinvestmentRate  float64
+	ReinvestmentYears int
+	Reinvest          map[InstrumentKind]bool
+
+	// T-bill quoted on a discount basis (%), and its days to maturity.
+	// It's converted to a bond equivalent yield before tax.
+	TBillDiscount float64
+	TBillDays     int
+
+	// How the AMT Free line's TEY is derived. The default grosses it up like
+	// every other line.
+	AMTFreeTEYMode TEYMode
+
+	// The instrument every TEY is grossed up to: FullyTaxableKind (the
+	// default) or TreasuryKind, for treasury-equivalent yields. The
+	// benchmark's own TEY is its yield.
+	Benchmark InstrumentKind
+
+	// Which rules to apply. Under UKTax the US settings above are ignored and
+	// interest (or dividends) is taxed at UKBand's rate.
+	TaxSystem           TaxSystem
+	UKBand              UKBand
+	UKSavingsAllowance  float64 // £ of interest tax-free
+	UKDividendAllowance float64 // £ of dividends tax-free
+	UKHoldingAmount     float64 // £ held per instrument; 0 ignores the allowances
+
+	// Do the tax composition in exact decimal arithmetic instead of float64,
+	// for reconciling to the quoted digits; see exactBreakdown. It's slower.
+	UseDecimal bool
+
+	// How Result.Text renders numbers
+	Format FormatOptions
 }
 
 // calcAfterTaxYield replicates JS calcAfterTaxYield(yield, fedtaxable, statetaxable, amtpct)
 func calcAfterTaxYield(yield float64, fedTaxable, stateTaxable bool, amtPct float64, in Inputs) float64 {
+	if in.TaxSystem == UKTax {
+		// no muni exemptions in the UK; it's all savings interest
+		return calcAfterTaxYieldUK(yield, false, in)
+	}
+	return yield * (1.0 - composeTax(in, fedTaxable, stateTaxable, amtPct)/100.0)
+}
+
+// composeTax is the total US tax (% of yield) on income taxed as given, in
+// this order:
+//
+//  1. federal: the bracket (or AMT rate) on FedTaxable income, or on the
+//     AMT-includable portion of exempt income under AMT
+//  2. state (and local) on StateTaxable income
+//  3. the deductions between them: the federal deduction of state tax when
+//     itemizing (not under AMT, and never more than step 1's tax), and the
+//     state's deduction of federal tax with StateDeductsFederal. Each
+//     depends on the other, so with both they're solved together rather
+//     than one applied first.
+//  4. NIIT on FedTaxable income, which neither deduction touches
+//
+// See taxBreakdown for each step's amount.
+func composeTax(in Inputs, fedTaxable, stateTaxable bool, amtPct float64) float64 {
+	return taxBreakdown(100, fedTaxable, stateTaxable, amtPct, in).TotalTax
+}
+
+// Breakdown is each step of calcAfterTaxYield's US tax math. Rates and
+// tax components are in percent of yield.
+type Breakdown struct {
+	Yield float64
+
+	FedRate   float64 // bracket, or the AMT rate when AMT applies
+	StateRate float64 // after any cross-border credit
+	AMT       bool
+	AMTPct    float64 // AMT-includable portion, when not FedTaxable
+
+	FedTax          float64 // federal (or AMT) component
+	StateTax        float64
+	DeductionRate   float64 // federal rate the deduction is worth
+	DeductionCredit float64 // federal deduction for state taxes, when itemizing
+	StateDeduction  float64 // state deduction for federal tax, with StateDeductsFederal
+	NIIT            float64 // net investment income tax, on FedTaxable income
+	TotalTax        float64
+
+	AfterTax float64
+}
+
+// taxBreakdown is the US side of calcAfterTaxYield, step by step.
+func taxBreakdown(yield float64, fedTaxable, stateTaxable bool, amtPct float64, in Inputs) Breakdown {
+	return taxBreakdownAt(yield, fedTaxable, stateTaxable, amtPct, amtRate(in), in)
+}
+
+// taxBreakdownAt is taxBreakdown with amtRate as the AMT rate (%) instead of
+// in.AMTBracketIndex's.
+func taxBreakdownAt(yield float64, fedTaxable, stateTaxable bool, amtPct, amtRate float64, in Inputs) Breakdown {
 	fed := in.FedBracket
-	state := in.StateBracket
-	itemize := in.Itemize
+	state := in.stateRate()
+	itemize := in.autoItemize().Itemize
 	amt := in.AMT
 
 	// AMT logic from the JS
 	if amt {
 		itemize = false
-		switch in.AMTBracketIndex {
-		case 0, 1:
-			fed = 26
-		case 2:
-			fed = 32.5
-		case 3:
-			fed = 35
-		case 4:
-			fed = 28
-		default:
-			// fall back to 26 if out of range
-			fed = 26
-		}
+		fed = amtRate
 	}
 
-	tax := 0.0
+	b := Breakdown{Yield: yield, FedRate: fed, StateRate: state, AMT: amt}
 
 	if fedTaxable {
-		tax += fed
+		b.FedTax = fed
 	} else if amt {
 		// not federally taxable, but a portion is AMT-includable
-		tax += (amtPct / 100.0) * fed
+		b.AMTPct = amtPct
+		b.FedTax = (amtPct / 100.0) * fed
 	}
 
 	if stateTaxable {
-		tax += state
-		if itemize {
+		b.StateTax = state
+		if itemize && !in.StateRateIsEffective {
 			// federal deduction for state taxes (reduce fed by state * fed)
-			tax -= (state / 100.0) * fed
+			b.DeductionRate = fed
+			if in.DeductionBenefitRate > 0 {
+				b.DeductionRate = in.DeductionBenefitRate
+			}
+		}
+		// state tax actually paid, after any deduction of federal tax
+		paid := state
+		if in.StateDeductsFederal {
+			// fed tax f = FedTax - D*paid, paid = state*(1 - f), solved
+			paid = state * (1 - b.FedTax/100) / (1 - state*b.DeductionRate/1e4)
+		}
+		b.DeductionCredit = (paid / 100.0) * b.DeductionRate
+		// The deduction only offsets federal tax on this income, so it
+		// can't make the federal component negative (no federal refund
+		// on muni income).
+		if b.DeductionCredit > b.FedTax {
+			b.DeductionCredit = b.FedTax
+			paid = state
 		}
+		if in.StateDeductsFederal {
+			b.StateDeduction = state - paid
+		}
+	}
+
+	if fedTaxable && in.NIIT {
+		b.NIIT = in.niitRate()
 	}
 
-	return yield * (1.0 - tax/100.0)
+	b.TotalTax = b.FedTax + b.StateTax - b.DeductionCredit - b.StateDeduction + b.NIIT
+	b.AfterTax = yield * (1.0 - b.TotalTax/100.0)
+	if in.UseDecimal {
+		return exactBreakdown(b, fedTaxable, stateTaxable, 1, in)
+	}
+	return b
+}
+
+// stateRate is the combined state (and local) rate (%) on state-taxable
+// income. With a different source state, that's the source state's tax plus
+// whatever the resident state still collects after crediting it.
+func (in Inputs) stateRate() float64 {
+	if in.SourceState == "" || in.ResidentState == "" || strings.EqualFold(in.SourceState, in.ResidentState) {
+		return in.StateBracket + in.LocalBracket
+	}
+	credit := math.Min(in.StateTaxCredit, in.StateBracket)
+	return in.StateTaxCredit + in.StateBracket - credit + in.LocalBracket
+}
+
+// niitRate is the net investment income tax rate (%).
+const niitRate = 3.8
+
+// niitThresholds are the MAGI ($) over which NIIT applies. They're set in
+// the statute and not indexed for inflation.
+var niitThresholds = map[FilingStatus]float64{
+	Single:          200000,
+	MarriedJoint:    250000,
+	MarriedSeparate: 125000,
+	HeadOfHousehold: 200000,
+}
+
+// niitRate is the NIIT rate (%) in effect on investment income: NIIT is
+// owed on the lesser of NetInvestmentIncome and MAGI's excess over the
+// threshold, so that's niitRate blended over all of it. Without MAGI it's the
+// flat rate; without NetInvestmentIncome, all or nothing on the threshold.
+func (in Inputs) niitRate() float64 {
+	if in.MAGI <= 0 {
+		return niitRate
+	}
+	excess := in.MAGI - niitThresholds[in.FilingStatus]
+	switch {
+	case excess <= 0:
+		return 0
+	case in.NetInvestmentIncome <= 0:
+		return niitRate
+	default:
+		return niitRate * math.Min(excess, in.NetInvestmentIncome) / in.NetInvestmentIncome
+	}
+}
+
+// amtRate is the AMT rate (%) picked by in.AMTBracketIndex. Compute falls
+// back to 26% for an out-of-range index; ComputeChecked rejects it.
+func amtRate(in Inputs) float64 {
+	switch in.AMTBracketIndex {
+	case 0, 1:
+		return 26
+	case 2:
+		return 32.5
+	case 3:
+		return 35
+	case 4:
+		return 28
+	default:
+		// fall back to 26 if out of range
+		return 26
+	}
+}
+
+// grossUpFactor turns an after-tax yield into a tax equivalent one, using
+// in.Benchmark (the fully-taxable instrument by default).
+func grossUpFactor(in Inputs) float64 {
+	bench := benchmarkInstrument(in)
+	return bench.EffectiveYield() / bench.AfterTax(in)
+}
+
+// benchmarkInstrument is what grossUpFactor grosses up to: the benchmark's
+// line, or without a usable yield, a 1% yield taxed like it.
+func benchmarkInstrument(in Inputs) Instrument {
+	if _, ok := benchmarkYield(in); ok {
+		return in.Instrument(in.Benchmark)
+	}
+	return Instrument{Kind: in.Benchmark, Yield: 1, FedTaxable: true, StateTaxable: !in.benchmarkStateExempt()}
+}
+
+// benchmarkYield is the benchmark's pretax yield, if there's a usable one.
+// If FullyTaxable is NaN in JS, they used 1.0% as a temp; so do we, and also
+// when the benchmark line is switched off, and to avoid divide-by-zero if
+// someone passes a case with an after-tax yield of 0.
+func benchmarkYield(in Inputs) (float64, bool) {
+	bench := in.Instrument(in.Benchmark)
+	y := bench.EffectiveYield()
+	if math.IsNaN(y) || !in.enabled(in.Benchmark) || y == 0 || bench.AfterTax(in) == 0 {
+		return 0, false
+	}
+	return y, true
+}
+
+// benchmarkStateExempt is whether the benchmark's interest is state-exempt.
+func (in Inputs) benchmarkStateExempt() bool {
+	return in.Benchmark == TreasuryKind && in.treasuryStateExempt()
 }
 
 type Result struct {
@@ -84,67 +438,129 @@ type Result struct {
 	AMTFreeAfterTax      float64
 	AMTFreeTEY           float64
 
+	// Yield basis of each line, carried over from Inputs
+	FullyTaxableType   YieldType
+	TreasuryType       YieldType
+	NatlTaxExemptType  YieldType
+	StateTaxExemptType YieldType
+	AMTFreeType        YieldType
+
+	// One entry per enabled instrument, in the order above. Instruments
+	// added since (like the T-bill) only appear here.
+	Lines []ResultLine
+
+	// GrossUp is the factor every TEY above is after-tax yield times:
+	// pretaxBenchmark / afterTaxBenchmark for Inputs.Benchmark (or a
+	// synthetic 1% one). It's Inf or NaN when the benchmark's
+	// effective tax is 100% or more; ComputeStrict rejects those.
+	GrossUp float64
+
+	// Each other line's after-tax yield minus the treasury's, in basis
+	// points, keyed like ToMap; nil without a treasury yield
+	SpreadToTreasury map[string]float64
+
+	// Tax rules that went into the numbers, e.g. "AMT (26%)", "NIIT",
+	// "itemized state deduction"
+	AppliedFeatures []string
+
 	// Pretty, multiline string like the original .result.value
 	Text string
+
+	render  renderOptions // how Text was rendered, for the Write methods
+	untaxed bool          // taxable interest bears no tax, for MunisPointless
 }
 
-// Compute does what the JS compute() did
+// Compute does what the JS compute() did. Instruments switched off in
+// in.Enabled get no Result line and leave their fields zero. It's a
+// Computer with in's tax settings, run on in's yields.
 func Compute(in Inputs) Result {
-	// After-tax yields
-	fullyAT := calcAfterTaxYield(in.FullyTaxable, true, true, 0, in)
-	treasuryAT := calcAfterTaxYield(in.Treasury, true, false, 0, in)
-	natlAT := calcAfterTaxYield(in.NatlTaxExempt, false, true, in.NatlAmTPct, in)
-	stateAT := calcAfterTaxYield(in.StateTaxExempt, false, false, in.StateAmTPct, in)
-
-	// Gross-up factor:
-	// If FullyTaxable is NaN in JS, they used 1.0% as a temp; replicate that.
-	var grossup float64
-	if math.IsNaN(in.FullyTaxable) {
-		tmp := 1.0
-		tmpAT := calcAfterTaxYield(tmp, true, true, 0, in)
-		grossup = tmp / tmpAT
-	} else {
-		// Avoid divide-by-zero if someone passes a case with fullyAT==0
-		if fullyAT == 0 {
-			// Fall back to tmp method if needed
-			tmp := 1.0
-			tmpAT := calcAfterTaxYield(tmp, true, true, 0, in)
-			grossup = tmp / tmpAT
+	c := Computer{Tax: in}
+	return c.Compute(in.Yields())
+}
+
+// ComputeInstruments is Compute for a caller's own instruments, with tax
+// settings (and the fully-taxable benchmark for the gross-up) from in. Lines
+// come out by ascending Order, ties in the order given.
+func ComputeInstruments(insts []Instrument, in Inputs) Result {
+	in = in.autoItemize()
+	insts = slices.Clone(insts)
+	sort.SliceStable(insts, func(i, j int) bool { return insts[i].Order < insts[j].Order })
+
+	grossup := grossUpFactor(in)
+	bench, haveBench := benchmarkYield(in)
+
+	// each benchmark's own gross-up, for TEYVsFullyTaxable and TEYVsTreasury
+	vsTaxable, vsTreasury := in, in
+	vsTaxable.Benchmark, vsTreasury.Benchmark = FullyTaxableKind, TreasuryKind
+	grossupTaxable := grossUpFactor(vsTaxable)
+	_, haveTreasury := benchmarkYield(vsTreasury)
+	grossupTreasury := grossUpFactor(vsTreasury)
+
+	// grossup above is pre-fee, so TEYs stay comparable; the fee only
+	// shows up in AfterTaxAfterFee.
+	fee := netAdvisoryFee(in)
+
+	var res Result
+	for _, inst := range insts {
+		taxed := inst.AfterTax(in)
+		afterTax := taxed - taxDrag(inst, taxed, in) - marginCost(inst, in)
+		tey := inst.tey(afterTax, grossup, in)
+		if inst.Kind == AMTFreeKind && in.AMTFreeTEYMode == FederalTEY {
+			tey = RequiredPretaxYield(afterTax, true, false, in)
+		}
+		spread := math.NaN()
+		if haveBench {
+			spread = (tey - bench) * 100
+		}
+		teyVsTreasury := math.NaN()
+		if haveTreasury {
+			teyVsTreasury = inst.tey(afterTax, grossupTreasury, vsTreasury)
+		}
+		line := ResultLine{Kind: inst.Kind, Label: inst.Label(), Yield: inst.EffectiveYield(),
+			AfterTax: afterTax, TEY: tey, Basis: inst.Basis, EffectiveTaxRate: inst.effectiveTaxRate(taxed, in),
+			AfterTaxAfterFee: afterTax - fee, TEYSpreadBps: spread, TEYVsFullyTaxable: inst.tey(afterTax, grossupTaxable, vsTaxable),
+			TEYVsTreasury: teyVsTreasury, Notes: inst.Notes}
+		if inst.customRule() {
+			res.Lines = append(res.Lines, line)
 		} else {
-			grossup = in.FullyTaxable / fullyAT
+			res.addLine(line)
 		}
 	}
 
-	// Build display text (3 decimals, with %)
-	line := func(label string, afterTax, tey float64) string {
-		return fmt.Sprintf("%-18s %6.3f%% after tax, %6.3f%% tax equivalent", label+":", afterTax, tey)
-	}
+	res.GrossUp = grossup
+	res.SpreadToTreasury = spreadToTreasury(res.Lines)
+	res.AppliedFeatures = in.appliedFeatures()
+	res.untaxed = CombinedTopRate(in) < 1e-9
+	res.render = renderOptions{showFee: in.AdvisoryFee != 0, showTreasuryTEY: haveTreasury, format: in.Format}
+	res.Text = res.render.text(res)
+	return res
+}
 
-	res := Result{
-		FullyTaxableAfterTax: fullyAT,
-		FullyTaxableTEY:      in.FullyTaxable, // same as original
-		TreasuryAfterTax:     treasuryAT,
-		TreasuryTEY:          treasuryAT * grossup,
-		NatlAfterTax:         natlAT,
-		NatlTEY:              natlAT * grossup,
-		StateAfterTax:        stateAT,
-		StateTEY:             stateAT * grossup,
-		AMTFreeAfterTax:      in.AMTFree, // original JS treated AMT Free as already after-tax
-		AMTFreeTEY:           in.AMTFree * grossup,
+// netAdvisoryFee is the fee's after-tax cost. A deductible fee is written off
+// at the federal bracket, but only when itemizing; AMT disallows it.
+func netAdvisoryFee(in Inputs) float64 {
+	if in.FeeIsDeductible && in.Itemize && !in.AMT {
+		return in.AdvisoryFee * (1 - in.FedBracket/100)
 	}
-
-	res.Text = line("Fully Taxable", res.FullyTaxableAfterTax, res.FullyTaxableTEY) + "\n" +
-		line("Treasury", res.TreasuryAfterTax, res.TreasuryTEY) + "\n" +
-		line("Nat'l Tax-Exempt", res.NatlAfterTax, res.NatlTEY) + "\n" +
-		line("State Tax-Exempt", res.StateAfterTax, res.StateTEY) + "\n" +
-		line("AMT Free", res.AMTFreeAfterTax, res.AMTFreeTEY)
-
-	return res
+	return in.AdvisoryFee
 }
 
 func main() {
-	// Example usage
-	in := Inputs{
+	var err error
+	if len(os.Args) > 1 && !strings.HasPrefix(os.Args[1], "-") {
+		err = runCommand(os.Args[1], os.Args[2:], os.Stdout)
+	} else {
+		err = runRoot(os.Args[1:], os.Stdout)
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+// exampleInputs is the example the tool prints when given nothing else.
+func exampleInputs() Inputs {
+	return Inputs{
 		FullyTaxable:   5.000,
 		Treasury:       4.500,
 		NatlTaxExempt:  3.800,
@@ -159,7 +575,4 @@ func main() {
 		AMT:             false,
 		AMTBracketIndex: 0, // ignored unless AMT=true
 	}
-
-	res := Compute(in)
-	fmt.Println(res.Text)
 }
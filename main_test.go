@@ -0,0 +1,328 @@
+package main
+
+import (
+	"math"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestDeductionCreditClamp(t *testing.T) {
+	tests := []struct {
+		name                     string
+		in                       Inputs
+		fedTaxable               bool
+		wantCredit, wantTotalTax float64
+	}{
+		// the 1.33 deduction of a muni's 13.3% state tax has no federal
+		// tax to come off, so it would be a federal refund
+		{"muni, state 13.3, fed 10", Inputs{FedBracket: 10, StateBracket: 13.3, Itemize: true}, false, 0, 13.3},
+		{"taxable, state 13.3, fed 10", Inputs{FedBracket: 10, StateBracket: 13.3, Itemize: true}, true, 1.33, 10 + 13.3 - 1.33},
+		// a 50% state deduction worth 37% would take 18.5% off a 10%
+		// federal tax
+		{"taxable, deduction worth more than the federal tax", Inputs{FedBracket: 10, StateBracket: 50, Itemize: true, DeductionBenefitRate: 37}, true, 10, 50},
+		{"muni, deduction worth more than the federal tax", Inputs{FedBracket: 10, StateBracket: 50, Itemize: true, DeductionBenefitRate: 37}, false, 0, 50},
+	}
+	for _, tt := range tests {
+		for _, decimal := range []bool{false, true} {
+			in := tt.in
+			in.UseDecimal = decimal
+			b := taxBreakdown(5, tt.fedTaxable, true, 0, in)
+			if fed := b.FedTax - b.DeductionCredit; fed < 0 {
+				t.Errorf("%s (decimal %v): federal component %v", tt.name, decimal, fed)
+			}
+			if !near(b.DeductionCredit, tt.wantCredit) || !near(b.TotalTax, tt.wantTotalTax) {
+				t.Errorf("%s (decimal %v): credit %v, total %v; want %v, %v",
+					tt.name, decimal, b.DeductionCredit, b.TotalTax, tt.wantCredit, tt.wantTotalTax)
+			}
+			if want := 5 * (1 - tt.wantTotalTax/100); !near(b.AfterTax, want) {
+				t.Errorf("%s (decimal %v): nets %v, want %v", tt.name, decimal, b.AfterTax, want)
+			}
+		}
+	}
+}
+
+func TestDeductionCreditClampStateDeductsFederal(t *testing.T) {
+	in := Inputs{FedBracket: 10, StateBracket: 50, Itemize: true, DeductionBenefitRate: 37, StateDeductsFederal: true}
+	b := taxBreakdown(5, true, true, 0, in)
+	if b.DeductionCredit != b.FedTax || b.StateDeduction != 0 {
+		t.Errorf("credit %v, state deduction %v; want the credit capped at %v and no state deduction",
+			b.DeductionCredit, b.StateDeduction, b.FedTax)
+	}
+}
+
+func TestExampleBaseline(t *testing.T) {
+	// the numbers the original JS calculator gives for its example, bar the
+	// national muni: it deducted the muni's state tax from federal tax the
+	// muni doesn't owe
+	res := Compute(exampleInputs())
+	for _, c := range []struct {
+		name      string
+		got, want float64
+	}{
+		{"fully taxable after tax", res.FullyTaxableAfterTax, 3.4466},
+		{"treasury after tax", res.TreasuryAfterTax, 3.42},
+		{"natl after tax", res.NatlAfterTax, 3.4466},
+		{"state after tax", res.StateAfterTax, 3.4},
+		{"amt free after tax", res.AMTFreeAfterTax, 3.7},
+	} {
+		if !near(c.got, c.want) {
+			t.Errorf("%s: got %v, want %v", c.name, c.got, c.want)
+		}
+	}
+}
+
+func near(a, b float64) bool { return math.Abs(a-b) < 1e-9 }
+
+func TestComputeOnlyMunisEnabled(t *testing.T) {
+	in := exampleInputs()
+	in.Enabled = map[InstrumentKind]bool{NatlTaxExemptKind: true, StateTaxExemptKind: true}
+	res := Compute(in)
+	if len(res.Lines) != 2 || res.Lines[0].Kind != NatlTaxExemptKind || res.Lines[1].Kind != StateTaxExemptKind {
+		t.Fatalf("lines %+v, want just the two munis", res.Lines)
+	}
+	if res.FullyTaxableAfterTax != 0 || res.TreasuryAfterTax != 0 || res.AMTFreeAfterTax != 0 {
+		t.Errorf("disabled lines have numbers: %v, %v, %v", res.FullyTaxableAfterTax, res.TreasuryAfterTax, res.AMTFreeAfterTax)
+	}
+	// Without the fully-taxable line the gross-up falls back to the
+	// synthetic 1% yield, which grosses up the same (after-tax yield is
+	// linear in the yield).
+	all := Compute(exampleInputs())
+	if !near(res.GrossUp, all.GrossUp) || !near(res.NatlTEY, all.NatlTEY) || !near(res.StateTEY, all.StateTEY) {
+		t.Errorf("gross-up %v, TEYs %v, %v; want %v, %v, %v", res.GrossUp, res.NatlTEY, res.StateTEY, all.GrossUp, all.NatlTEY, all.StateTEY)
+	}
+	if strings.Contains(res.Text, "Treasury") || strings.Contains(res.Text, "Fully Taxable") {
+		t.Errorf("Text shows disabled lines:\n%s", res.Text)
+	}
+}
+
+func TestAdvisoryFee(t *testing.T) {
+	tests := []struct {
+		name    string
+		edit    func(*Inputs)
+		wantFee float64
+	}{
+		{"1% fee", func(in *Inputs) { in.AdvisoryFee = 1 }, 1},
+		{"deductible, itemizing", func(in *Inputs) { in.AdvisoryFee, in.FeeIsDeductible = 1, true }, 0.76},
+		{"deductible, not itemizing", func(in *Inputs) { in.AdvisoryFee, in.FeeIsDeductible, in.Itemize = 1, true, false }, 1},
+	}
+	for _, tt := range tests {
+		in := exampleInputs()
+		tt.edit(&in)
+		res := Compute(in)
+		noFee := in
+		noFee.AdvisoryFee = 0
+		base := Compute(noFee)
+		if res.GrossUp != base.GrossUp {
+			t.Errorf("%s: gross-up %v, want the pre-fee %v", tt.name, res.GrossUp, base.GrossUp)
+		}
+		for i, l := range res.Lines {
+			if l.AfterTax != base.Lines[i].AfterTax || l.TEY != base.Lines[i].TEY {
+				t.Errorf("%s: %s after-tax/TEY changed by the fee", tt.name, l.Label)
+			}
+			if !near(l.AfterTax-l.AfterTaxAfterFee, tt.wantFee) {
+				t.Errorf("%s: %s nets %v after the fee, want %v less than %v", tt.name, l.Label, l.AfterTaxAfterFee, tt.wantFee, l.AfterTax)
+			}
+		}
+		if !strings.Contains(res.Text, "after fee") {
+			t.Errorf("%s: Text has no after-fee column:\n%s", tt.name, res.Text)
+		}
+	}
+}
+
+func TestZeroYieldTEY(t *testing.T) {
+	in := exampleInputs()
+	in.NatlTaxExempt = 0
+	if res := Compute(in); res.NatlTEY != 0 {
+		t.Errorf("0%% muni TEY %v, want 0", res.NatlTEY)
+	}
+	// at a 100% tax on the benchmark the gross-up is infinite, and
+	// 0 x Inf would be NaN
+	in.FedBracket, in.StateBracket, in.Itemize = 100, 0, false
+	res := Compute(in)
+	if !math.IsInf(res.GrossUp, 1) {
+		t.Fatalf("setup: gross-up %v, want +Inf", res.GrossUp)
+	}
+	if res.NatlTEY != 0 {
+		t.Errorf("0%% muni TEY %v under an infinite gross-up, want 0", res.NatlTEY)
+	}
+	if l, _ := res.Line(NatlTaxExemptKind); l.TEY != 0 {
+		t.Errorf("line TEY %v, want 0", l.TEY)
+	}
+}
+
+func TestGrossUpExposed(t *testing.T) {
+	nan := exampleInputs()
+	nan.FullyTaxable = math.NaN()
+	for name, in := range map[string]Inputs{"example": exampleInputs(), "NaN fallback": nan} {
+		res := Compute(in)
+		if !near(res.TreasuryTEY, res.TreasuryAfterTax*res.GrossUp) {
+			t.Errorf("%s: TreasuryTEY %v, want TreasuryAfterTax * GrossUp = %v", name, res.TreasuryTEY, res.TreasuryAfterTax*res.GrossUp)
+		}
+		// pretax over after-tax benchmark, 1% synthetic or not
+		if want := 1 / (1 - 0.31068); !near(res.GrossUp, want) {
+			t.Errorf("%s: GrossUp %v, want %v", name, res.GrossUp, want)
+		}
+	}
+	in := exampleInputs()
+	in.Format.ShowGrossUp = true
+	if text := Compute(in).Text; !strings.Contains(text, "1.451x") {
+		t.Errorf("ShowGrossUp Text has no factor:\n%s", text)
+	}
+}
+
+func TestStateReciprocity(t *testing.T) {
+	tests := []struct {
+		name             string
+		resident, source string
+		sourceTax        float64
+		want             float64 // combined state rate (%)
+	}{
+		// the resident state credits the source tax up to its own 5%
+		{"higher source state", "OR", "CA", 9.3, 9.3},
+		{"lower source state", "OR", "AZ", 2.5, 5},
+		{"same state", "OR", "or", 9.3, 5},
+		{"no source state", "OR", "", 9.3, 5},
+	}
+	for _, tt := range tests {
+		in := Inputs{FedBracket: 24, StateBracket: 5, ResidentState: tt.resident, SourceState: tt.source, StateTaxCredit: tt.sourceTax}
+		if got := in.stateRate(); !near(got, tt.want) {
+			t.Errorf("%s: state rate %v, want %v", tt.name, got, tt.want)
+		}
+		if got, want := calcAfterTaxYield(5, true, true, 0, in), 5*(1-0.24-tt.want/100); !near(got, want) {
+			t.Errorf("%s: 5%% taxable nets %v, want %v", tt.name, got, want)
+		}
+	}
+}
+
+func TestDeductionBenefitRate(t *testing.T) {
+	in := Inputs{FedBracket: 24, StateBracket: 9.3, Itemize: true}
+	marginal := taxBreakdown(5, true, true, 0, in)
+	in.DeductionBenefitRate = 12 // the deduction lands in a lower bracket
+	lower := taxBreakdown(5, true, true, 0, in)
+
+	if !near(marginal.DeductionCredit, 9.3*0.24) || !near(lower.DeductionCredit, 9.3*0.12) {
+		t.Errorf("credits %v, %v; want %v, %v", marginal.DeductionCredit, lower.DeductionCredit, 9.3*0.24, 9.3*0.12)
+	}
+	if !(lower.AfterTax < marginal.AfterTax) {
+		t.Errorf("a smaller benefit nets %v, not below %v", lower.AfterTax, marginal.AfterTax)
+	}
+	if lower.FedTax != marginal.FedTax {
+		t.Error("the benefit rate changed the federal tax itself")
+	}
+	// 0 is FedBracket, as before the override
+	in.DeductionBenefitRate = 0
+	if got := taxBreakdown(5, true, true, 0, in); got != marginal {
+		t.Errorf("0 override: %+v, want %+v", got, marginal)
+	}
+}
+
+func TestStateRateIsEffective(t *testing.T) {
+	// 9.3% state, already net of the 24% deduction: 7.068% effective
+	for _, decimal := range []bool{false, true} {
+		in := Inputs{FullyTaxable: 5, NatlTaxExempt: 3.5, FedBracket: 24, StateBracket: 7.068, Itemize: true,
+			StateRateIsEffective: true, UseDecimal: decimal}
+		b := taxBreakdown(5, true, true, 0, in)
+		if b.DeductionRate != 0 || b.DeductionCredit != 0 || !near(b.TotalTax, 24+7.068) {
+			t.Errorf("decimal %v: deduction %v at %v, total %v; want no deduction step", decimal, b.DeductionCredit, b.DeductionRate, b.TotalTax)
+		}
+		res := Compute(in)
+		if !near(res.FullyTaxableAfterTax, 5*(1-0.24-0.07068)) || !near(res.NatlAfterTax, 3.5*(1-0.07068)) {
+			t.Errorf("decimal %v: after tax %v, %v", decimal, res.FullyTaxableAfterTax, res.NatlAfterTax)
+		}
+		if !slices.Contains(res.AppliedFeatures, "effective state rate (deduction included)") {
+			t.Errorf("decimal %v: features %q", decimal, res.AppliedFeatures)
+		}
+
+		// the same as the nominal rate with the deduction applied
+		nominal := in
+		nominal.StateBracket, nominal.StateRateIsEffective = 9.3, false
+		if got := Compute(nominal).FullyTaxableAfterTax; !near(got, res.FullyTaxableAfterTax) {
+			t.Errorf("decimal %v: nominal 9.3%% nets %v, effective 7.068%% %v", decimal, got, res.FullyTaxableAfterTax)
+		}
+		// without itemizing the flag changes nothing
+		in.Itemize = false
+		plain := in
+		plain.StateRateIsEffective = false
+		if Compute(in).FullyTaxableAfterTax != Compute(plain).FullyTaxableAfterTax {
+			t.Errorf("decimal %v: the flag matters without itemizing", decimal)
+		}
+	}
+}
+
+func TestNIITThreshold(t *testing.T) {
+	for _, tt := range []struct {
+		name string
+		in   Inputs
+		niit float64
+	}{
+		{"no MAGI: flat", Inputs{NIIT: true}, 3.8},
+		{"below", Inputs{NIIT: true, MAGI: 150000, NetInvestmentIncome: 40000}, 0},
+		{"at", Inputs{NIIT: true, MAGI: 200000, NetInvestmentIncome: 40000}, 0},
+		{"half the income over", Inputs{NIIT: true, MAGI: 220000, NetInvestmentIncome: 40000}, 1.9},
+		{"all the income over", Inputs{NIIT: true, MAGI: 300000, NetInvestmentIncome: 40000}, 3.8},
+		{"married, below theirs", Inputs{NIIT: true, MAGI: 240000, NetInvestmentIncome: 40000, FilingStatus: MarriedJoint}, 0},
+		{"married, over", Inputs{NIIT: true, MAGI: 260000, NetInvestmentIncome: 40000, FilingStatus: MarriedJoint}, 0.95},
+		{"off", Inputs{MAGI: 300000, NetInvestmentIncome: 40000}, 0},
+	} {
+		in := tt.in
+		in.FullyTaxable, in.NatlTaxExempt, in.FedBracket = 5, 3.5, 35
+		res := Compute(in)
+		if want := 5 * (1 - (35+tt.niit)/100); !near(res.FullyTaxableAfterTax, want) {
+			t.Errorf("%s: taxable nets %v, want %v (NIIT %v%%)", tt.name, res.FullyTaxableAfterTax, want, tt.niit)
+		}
+		if res.NatlAfterTax != 3.5 {
+			t.Errorf("%s: NIIT on a muni: %v", tt.name, res.NatlAfterTax)
+		}
+	}
+}
+
+func TestComposeTax(t *testing.T) {
+	// 24% federal, 6% state, 28% AMT, half the exempt income AMT-includable
+	base := Inputs{FedBracket: 24, StateBracket: 6, AMTBracketIndex: 4}
+	with := func(f func(*Inputs)) Inputs {
+		in := base
+		f(&in)
+		return in
+	}
+	itemize := func(in *Inputs) { in.Itemize = true }
+	deductsFed := func(in *Inputs) { in.StateDeductsFederal = true }
+	both := func(in *Inputs) { in.Itemize, in.StateDeductsFederal = true, true }
+	amt := func(in *Inputs) { in.AMT, in.Itemize = true, true }
+	for _, tt := range []struct {
+		name                     string
+		in                       Inputs
+		fedTaxable, stateTaxable bool
+		want                     float64
+	}{
+		{"fed and state", base, true, true, 30},
+		{"fed and state, itemizing", with(itemize), true, true, 24 + 6*(1-0.24)},
+		// the state deducts federal tax: 6% of what's left after 24%
+		{"fed and state, state deducts fed", with(deductsFed), true, true, 24 + 6*(1-0.24)},
+		// each deduction shrinks the other's base, solved together
+		{"fed and state, both deductions", with(both), true, true, 24 + 6*0.76*0.76/(1-0.06*0.24)},
+		{"fed and state, NIIT", with(func(in *Inputs) { itemize(in); in.NIIT = true }), true, true, 24 + 6*(1-0.24) + 3.8},
+		{"fed and state, AMT", with(amt), true, true, 28 + 6},
+		{"fed only", base, true, false, 24},
+		{"fed only, itemizing", with(itemize), true, false, 24},
+		{"fed only, NIIT", with(func(in *Inputs) { in.NIIT = true }), true, false, 27.8},
+		{"state only", base, false, true, 6},
+		// an exempt line has no federal tax for either deduction to touch
+		{"state only, itemizing", with(itemize), false, true, 6},
+		{"state only, state deducts fed", with(deductsFed), false, true, 6},
+		{"state only, both deductions", with(both), false, true, 6},
+		{"state only, NIIT", with(func(in *Inputs) { in.NIIT = true }), false, true, 6},
+		{"state only, AMT", with(amt), false, true, 0.5*28 + 6},
+		{"state only, AMT, state deducts fed", with(func(in *Inputs) { amt(in); deductsFed(in) }), false, true, 14 + 6*(1-0.14)},
+		{"neither", with(itemize), false, false, 0},
+		{"neither, AMT", with(amt), false, false, 14},
+	} {
+		got := composeTax(tt.in, tt.fedTaxable, tt.stateTaxable, 50)
+		if !near(got, tt.want) {
+			t.Errorf("%s: tax %v%%, want %v%%", tt.name, got, tt.want)
+		}
+		if at := calcAfterTaxYield(5, tt.fedTaxable, tt.stateTaxable, 50, tt.in); !near(at, 5*(1-got/100)) {
+			t.Errorf("%s: 5%% nets %v, but composeTax says %v%% tax", tt.name, at, got)
+		}
+	}
+}
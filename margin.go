@@ -0,0 +1,28 @@
+package main
+
+import "math"
+
+// marginCost is the after-tax cost (yield points of the position) of
+// financing in.MarginFraction of inst on margin at in.MarginInterestRate.
+//
+// Only federally taxable instruments are leveraged here: interest to carry
+// munis isn't deductible anyway. The interest is deductible when itemizing,
+// at the federal bracket (or the AMT rate, which also allows it), up to net
+// investment income per Section 163(d). That limit is simplified to the
+// position's own taxable income; other investment income and the carryforward
+// of disallowed interest are ignored.
+func marginCost(inst Instrument, in Inputs) float64 {
+	if in.MarginFraction == 0 || in.MarginInterestRate == 0 || !inst.FedTaxable || inst.AfterTaxQuoted {
+		return 0
+	}
+	interest := in.MarginFraction * in.MarginInterestRate
+	if !in.Itemize {
+		return interest
+	}
+	rate := in.FedBracket
+	if in.AMT {
+		rate = amtRate(in)
+	}
+	deductible := math.Min(interest, math.Max(inst.EffectiveYield(), 0))
+	return interest - deductible*rate/100
+}
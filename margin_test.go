@@ -0,0 +1,33 @@
+package main
+
+import "testing"
+
+func TestMarginCost(t *testing.T) {
+	tests := []struct {
+		name     string
+		edit     func(*Inputs)
+		treasury float64 // 4.5% Treasury, 3.42% after tax unleveraged
+	}{
+		{"no margin", func(in *Inputs) { in.MarginFraction = 0 }, 3.42},
+		// 3% interest on half the position, 24% of it deducted
+		{"50% at 6%, itemizing", func(in *Inputs) {}, 3.42 - 3*(1-0.24)},
+		{"50% at 6%, not itemizing", func(in *Inputs) { in.Itemize = false }, 3.42 - 3},
+		// 6% interest, but only the position's 4.5% income is deductible
+		{"50% at 12%, capped", func(in *Inputs) { in.MarginInterestRate = 12 }, 3.42 - (6 - 4.5*0.24)},
+	}
+	for _, tt := range tests {
+		in := Inputs{Treasury: 4.5, NatlTaxExempt: 3, FedBracket: 24, StateBracket: 5, Itemize: true,
+			MarginFraction: 0.5, MarginInterestRate: 6}
+		tt.edit(&in)
+		res := Compute(in)
+		if !near(res.TreasuryAfterTax, tt.treasury) {
+			t.Errorf("%s: treasury after tax %v, want %v", tt.name, res.TreasuryAfterTax, tt.treasury)
+		}
+		// munis aren't leveraged
+		unleveraged := in
+		unleveraged.MarginFraction = 0
+		if want := Compute(unleveraged).NatlAfterTax; !near(res.NatlAfterTax, want) {
+			t.Errorf("%s: natl after tax %v, want %v without margin", tt.name, res.NatlAfterTax, want)
+		}
+	}
+}
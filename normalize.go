@@ -0,0 +1,65 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// NormalizeInputs converts fields that look like they were entered as
+// decimal fractions (0.045 for 4.5%) to percentages, returning what it
+// changed. A yield is suspect when it's in (0, 1); a bracket when it's in
+// (0, 0.6), since a few real local and state rates are under 1%. Zero, NaN and
+// anything else are left alone, so don't run this on inputs you know are
+// right: a genuine 0.4% money market yield would become 40%.
+func NormalizeInputs(in Inputs) (Inputs, []string) {
+	var warnings []string
+	fix := func(name string, p *float64, limit float64) {
+		if v := *p; v > 0 && v < limit {
+			*p = timesHundred(v)
+			warnings = append(warnings, fmt.Sprintf("%s: read %g as %g%%", name, v, *p))
+		}
+	}
+	for _, f := range []struct {
+		name string
+		p    *float64
+	}{
+		{"FullyTaxable", &in.FullyTaxable},
+		{"Treasury", &in.Treasury},
+		{"NatlTaxExempt", &in.NatlTaxExempt},
+		{"StateTaxExempt", &in.StateTaxExempt},
+		{"AMTFree", &in.AMTFree},
+		{"Corporate", &in.Corporate},
+		{"Agency", &in.Agency},
+		{"BondFund", &in.BondFund},
+		{"TaxFreeMMF", &in.TaxFreeMMF},
+		{"AuthorityBond", &in.AuthorityBond},
+		{"TaxableMuni", &in.TaxableMuni},
+		{"TBillDiscount", &in.TBillDiscount},
+	} {
+		fix(f.name, f.p, 1)
+	}
+	for _, f := range []struct {
+		name string
+		p    *float64
+	}{
+		{"FedBracket", &in.FedBracket},
+		{"StateBracket", &in.StateBracket},
+		{"LocalBracket", &in.LocalBracket},
+		{"DeductionBenefitRate", &in.DeductionBenefitRate},
+		{"QDIRate", &in.QDIRate},
+	} {
+		fix(f.name, f.p, 0.6)
+	}
+	return in, warnings
+}
+
+// timesHundred is 100*v as the user would write it: 0.035 becomes 3.5, not
+// the 3.5000000000000004 that multiplying gives. It shifts the decimal point
+// of v's shortest representation instead.
+func timesHundred(v float64) float64 {
+	r, err := strconv.ParseFloat(strconv.FormatFloat(v, 'g', -1, 64)+"e2", 64)
+	if err != nil {
+		return v * 100
+	}
+	return r
+}
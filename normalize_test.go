@@ -0,0 +1,63 @@
+package main
+
+import (
+	"slices"
+	"strconv"
+	"testing"
+)
+
+func TestNormalizeInputs(t *testing.T) {
+	in := Inputs{
+		FullyTaxable:   0.05, // fraction
+		Treasury:       4.5,  // percent
+		NatlTaxExempt:  0.035,
+		StateTaxExempt: 3.4,
+		FedBracket:     0.24,
+		StateBracket:   9.3,
+		LocalBracket:   0.75, // a real sub-1% local rate stays
+		QDIRate:        0.15,
+	}
+	got, warnings := NormalizeInputs(in)
+	want := in
+	want.FullyTaxable, want.NatlTaxExempt, want.FedBracket, want.QDIRate = 5, 3.5, 24, 15
+	for _, f := range []struct {
+		name      string
+		got, want float64
+	}{
+		{"FullyTaxable", got.FullyTaxable, want.FullyTaxable},
+		{"Treasury", got.Treasury, want.Treasury},
+		{"NatlTaxExempt", got.NatlTaxExempt, want.NatlTaxExempt},
+		{"StateTaxExempt", got.StateTaxExempt, want.StateTaxExempt},
+		{"FedBracket", got.FedBracket, want.FedBracket},
+		{"StateBracket", got.StateBracket, want.StateBracket},
+		{"LocalBracket", got.LocalBracket, want.LocalBracket},
+		{"QDIRate", got.QDIRate, want.QDIRate},
+	} {
+		if !near(f.got, f.want) {
+			t.Errorf("%s: %v, want %v", f.name, f.got, f.want)
+		}
+	}
+	wantWarnings := []string{
+		"FullyTaxable: read 0.05 as 5%",
+		"NatlTaxExempt: read 0.035 as 3.5%",
+		"FedBracket: read 0.24 as 24%",
+		"QDIRate: read 0.15 as 15%",
+	}
+	if !slices.Equal(warnings, wantWarnings) {
+		t.Errorf("warnings %q, want %q", warnings, wantWarnings)
+	}
+
+	// all-percent inputs are left alone
+	if got, warnings := NormalizeInputs(exampleInputs()); got.Fingerprint() != exampleInputs().Fingerprint() || warnings != nil {
+		t.Errorf("the example changed: %q", warnings)
+	}
+}
+
+func TestTimesHundred(t *testing.T) {
+	for _, v := range []float64{0.035, 0.045, 0.07, 0.0123, 0.29, 1e-5} {
+		got := timesHundred(v)
+		if want, _ := strconv.ParseFloat(strconv.FormatFloat(v*100, 'g', 12, 64), 64); got != want {
+			t.Errorf("timesHundred(%v) = %v, want %v", v, got, want)
+		}
+	}
+}
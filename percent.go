@@ -0,0 +1,44 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// ParsePercent parses a yield or bracket as typed by a person or exported by
+// a spreadsheet: "4.5", "4.5%", " +2.0% ". An empty string is NaN, which
+// Compute treats as "unknown" (for FullyTaxable, the gross-up fallback).
+func ParsePercent(s string) (float64, error) {
+	t := strings.TrimSpace(s)
+	t = strings.TrimSpace(strings.TrimSuffix(t, "%"))
+	if t == "" {
+		return math.NaN(), nil
+	}
+	t = strings.TrimPrefix(t, "+")
+	v, err := strconv.ParseFloat(t, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid percentage %q", s)
+	}
+	return v, nil
+}
+
+// percentValue is a flag.Value parsed with ParsePercent.
+type percentValue float64
+
+func (p *percentValue) Set(s string) error {
+	v, err := ParsePercent(s)
+	if err != nil {
+		return err
+	}
+	*p = percentValue(v)
+	return nil
+}
+
+func (p *percentValue) String() string {
+	if p == nil {
+		return ""
+	}
+	return strconv.FormatFloat(float64(*p), 'g', -1, 64)
+}
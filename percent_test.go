@@ -0,0 +1,51 @@
+package main
+
+import (
+	"math"
+	"net/url"
+	"testing"
+)
+
+func TestParsePercent(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    float64 // NaN for NaN
+		wantErr bool
+	}{
+		{"4.5%", 4.5, false},
+		{" 3 ", 3, false},
+		{"", math.NaN(), false},
+		{"  ", math.NaN(), false},
+		{"+2.0%", 2, false},
+		{" 4.5 % ", 4.5, false},
+		{"-1%", -1, false},
+		{"abc", 0, true},
+		{"4.5%%", 0, true},
+	}
+	for _, tt := range tests {
+		got, err := ParsePercent(tt.in)
+		switch {
+		case tt.wantErr:
+			if err == nil {
+				t.Errorf("ParsePercent(%q) = %v, want an error", tt.in, got)
+			}
+		case err != nil:
+			t.Errorf("ParsePercent(%q): %v", tt.in, err)
+		case math.IsNaN(tt.want) != math.IsNaN(got) || !math.IsNaN(got) && got != tt.want:
+			t.Errorf("ParsePercent(%q) = %v, want %v", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestQueryFloatPercent(t *testing.T) {
+	q := url.Values{"from": {"10%"}, "bad": {"ten"}}
+	if v, err := queryFloat(q, "from", 0); err != nil || v != 10 {
+		t.Errorf("from: %v, %v", v, err)
+	}
+	if v, err := queryFloat(q, "to", 37); err != nil || v != 37 {
+		t.Errorf("missing to: %v, %v; want the default", v, err)
+	}
+	if _, err := queryFloat(q, "bad", 0); err == nil {
+		t.Error("bad: no error")
+	}
+}
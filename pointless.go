@@ -0,0 +1,14 @@
+package main
+
+// pointlessWarning is appended to Result.Text when MunisPointless.
+const pointlessWarning = "Warning: no tax on taxable interest, so tax-exempt bonds can't come out ahead"
+
+// MunisPointless reports whether taxable interest bears no tax at all (0%
+// federal and state, or a tax-free account). Then a muni's exemption is
+// worth nothing and it can only match, never beat, a taxable bond of the
+// same yield. It goes by the fully-taxable rate, not GrossUp, which is
+// the benchmark's: a Treasury benchmark is untaxed with no federal tax even
+// when state tax makes munis worthwhile.
+func (r Result) MunisPointless() bool {
+	return r.untaxed
+}
@@ -0,0 +1,33 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestMunisPointless(t *testing.T) {
+	for _, tt := range []struct {
+		name string
+		in   Inputs
+		want bool
+	}{
+		{"0% federal and state", Inputs{FullyTaxable: 5, NatlTaxExempt: 4, StateTaxExempt: 4}, true},
+		{"0% with no taxable yield", Inputs{NatlTaxExempt: 4}, true},
+		{"0% federal, some state", Inputs{FullyTaxable: 5, NatlTaxExempt: 4, StateBracket: 5}, false},
+		{"the example", exampleInputs(), false},
+		{"0% federal, some state, Treasury benchmark", Inputs{FullyTaxable: 5, Treasury: 4.5, StateTaxExempt: 4, StateBracket: 9.3, Benchmark: TreasuryKind}, false},
+	} {
+		res := Compute(tt.in)
+		if got := res.MunisPointless(); got != tt.want {
+			t.Errorf("%s: MunisPointless() = %v, want %v", tt.name, got, tt.want)
+		}
+		if warned := strings.Contains(res.Text, pointlessWarning); warned != tt.want {
+			t.Errorf("%s: warning in Text %v, want %v:\n%s", tt.name, warned, tt.want, res.Text)
+		}
+	}
+	// a muni can only tie at 0%
+	res := Compute(Inputs{FullyTaxable: 5, NatlTaxExempt: 5})
+	if res.FullyTaxableAfterTax != res.NatlAfterTax {
+		t.Errorf("at 0%% a 5%% taxable nets %v, a 5%% muni %v", res.FullyTaxableAfterTax, res.NatlAfterTax)
+	}
+}
@@ -0,0 +1,57 @@
+package main
+
+import (
+	"fmt"
+	"math"
+)
+
+// Holding is one position in a portfolio.
+type Holding struct {
+	Weight     float64 // fraction of the portfolio, 0..1
+	Instrument Instrument
+}
+
+// weightEpsilon is how far holding weights may sum from 1.
+const weightEpsilon = 1e-6
+
+// PortfolioAfterTax is the weight-averaged after-tax yield of holdings, and
+// the weight-averaged TEY using in's gross-up. Weights must sum to 1.
+func PortfolioAfterTax(holdings []Holding, in Inputs) (afterTax, tey float64, err error) {
+	if err := checkWeights(holdings); err != nil {
+		return 0, 0, err
+	}
+	grossup := grossUpFactor(in)
+	for _, h := range holdings {
+		at := h.Instrument.AfterTax(in)
+		afterTax += h.Weight * at
+		tey += h.Weight * h.Instrument.tey(at, grossup, in)
+	}
+	return afterTax, tey, nil
+}
+
+func checkWeights(holdings []Holding) error {
+	sum := 0.0
+	for _, h := range holdings {
+		if h.Weight < 0 {
+			return fmt.Errorf("holding %s has negative weight %g", h.Instrument.Label(), h.Weight)
+		}
+		sum += h.Weight
+	}
+	if math.Abs(sum-1) > weightEpsilon {
+		return fmt.Errorf("holding weights sum to %g, want 1", sum)
+	}
+	return nil
+}
+
+// PortfolioTEY is the fully taxable yield that nets the portfolio's blended
+// after-tax yield: blend first, then gross up once. That's not the weighted
+// average of the lines' own TEYs once any line's TEY is on another basis
+// (AMT Free under FederalTEY, say), since those don't share a gross-up and
+// averaging them mixes benchmarks. It's NaN if the weights are invalid.
+func PortfolioTEY(holdings []Holding, in Inputs) float64 {
+	afterTax, _, err := PortfolioAfterTax(holdings, in)
+	if err != nil {
+		return math.NaN()
+	}
+	return afterTax * grossUpFactor(in)
+}
@@ -0,0 +1,77 @@
+package main
+
+import (
+	"math"
+	"strings"
+	"testing"
+)
+
+func TestPortfolioAfterTax(t *testing.T) {
+	in := exampleInputs()
+	muni, treasury := in.Instrument(NatlTaxExemptKind), in.Instrument(TreasuryKind)
+	holdings := []Holding{{Weight: 0.5, Instrument: muni}, {Weight: 0.5, Instrument: treasury}}
+	afterTax, tey, err := PortfolioAfterTax(holdings, in)
+	if err != nil {
+		t.Fatal(err)
+	}
+	res := Compute(in)
+	if want := (res.NatlAfterTax + res.TreasuryAfterTax) / 2; !near(afterTax, want) {
+		t.Errorf("after tax %v, want %v", afterTax, want)
+	}
+	if want := (res.NatlTEY + res.TreasuryTEY) / 2; !near(tey, want) {
+		t.Errorf("TEY %v, want %v", tey, want)
+	}
+	// each half nets what calcAfterTaxYield says it should
+	want := 0.5*calcAfterTaxYield(3.8, false, true, in.NatlAmTPct, in) + 0.5*calcAfterTaxYield(4.5, true, false, 0, in)
+	if !near(afterTax, want) {
+		t.Errorf("after tax %v, want %v from calcAfterTaxYield", afterTax, want)
+	}
+}
+
+func TestPortfolioWeights(t *testing.T) {
+	in := exampleInputs()
+	muni := in.Instrument(NatlTaxExemptKind)
+	for _, tt := range []struct {
+		weights []float64
+		wantErr string
+	}{
+		{[]float64{0.5, 0.4}, "sum to 0.9"},
+		{[]float64{1.2, -0.2}, "negative weight"},
+	} {
+		var holdings []Holding
+		for _, w := range tt.weights {
+			holdings = append(holdings, Holding{Weight: w, Instrument: muni})
+		}
+		if _, _, err := PortfolioAfterTax(holdings, in); err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+			t.Errorf("weights %v: err = %v, want %q", tt.weights, err, tt.wantErr)
+		}
+	}
+}
+
+func TestPortfolioTEY(t *testing.T) {
+	in := exampleInputs()
+	in.AMTFreeTEYMode = FederalTEY
+	holdings := []Holding{{Weight: 0.5, Instrument: in.Instrument(NatlTaxExemptKind)}, {Weight: 0.5, Instrument: in.Instrument(AMTFreeKind)}}
+	tey := PortfolioTEY(holdings, in)
+
+	// it's the fully taxable yield netting the blended after-tax yield
+	afterTax, _, err := PortfolioAfterTax(holdings, in)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := calcAfterTaxYield(tey, true, true, 0, in); !near(got, afterTax) {
+		t.Errorf("PortfolioTEY %v nets %v, want the blend's %v", tey, got, afterTax)
+	}
+
+	// Averaging the Result lines' TEYs mixes the AMT Free line's federal
+	// basis with the muni's, so it comes out different.
+	res := Compute(in)
+	averaged := (res.NatlTEY + res.AMTFreeTEY) / 2
+	if math.Abs(averaged-tey) < 0.01 {
+		t.Errorf("averaged TEYs %v and PortfolioTEY %v should diverge", averaged, tey)
+	}
+
+	if got := PortfolioTEY([]Holding{{Weight: 0.7, Instrument: holdings[0].Instrument}}, in); !math.IsNaN(got) {
+		t.Errorf("weights summing to 0.7: %v, want NaN", got)
+	}
+}
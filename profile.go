@@ -0,0 +1,186 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// taxProfile is a household's saved tax settings; everything taxFlags sets.
+type taxProfile struct {
+	FedBracket           float64
+	StateBracket         float64
+	LocalBracket         float64
+	Itemize              bool
+	AutoItemize          bool
+	StandardDeduction    float64
+	OtherItemized        float64
+	SALTCap              float64
+	StateRateIsEffective bool
+	StateDeductsFederal  bool
+	DeductionBenefitRate float64
+	AMT                  bool
+	AMTBracketIndex      int
+	NIIT                 bool
+	MAGI                 float64
+	NetInvestmentIncome  float64
+	FilingStatus         FilingStatus
+	NatlAmTPct           float64
+	NatlInStateFraction  float64
+	StateAmTPct          float64
+}
+
+func profileOf(in Inputs) taxProfile {
+	return taxProfile{in.FedBracket, in.StateBracket, in.LocalBracket, in.Itemize,
+		in.AutoItemize, in.StandardDeduction, in.OtherItemized, in.SALTCap, in.StateRateIsEffective,
+		in.StateDeductsFederal, in.DeductionBenefitRate, in.AMT, in.AMTBracketIndex, in.NIIT, in.MAGI, in.NetInvestmentIncome, in.FilingStatus,
+		in.NatlAmTPct, in.NatlInStateFraction, in.StateAmTPct}
+}
+
+// applyTo sets in's tax settings from p.
+func (p taxProfile) applyTo(in *Inputs) {
+	in.FedBracket, in.StateBracket, in.LocalBracket = p.FedBracket, p.StateBracket, p.LocalBracket
+	in.Itemize, in.StateRateIsEffective, in.DeductionBenefitRate = p.Itemize, p.StateRateIsEffective, p.DeductionBenefitRate
+	in.StateDeductsFederal = p.StateDeductsFederal
+	in.AutoItemize, in.StandardDeduction, in.OtherItemized, in.SALTCap = p.AutoItemize, p.StandardDeduction, p.OtherItemized, p.SALTCap
+	in.AMT, in.AMTBracketIndex, in.NIIT = p.AMT, p.AMTBracketIndex, p.NIIT
+	in.MAGI, in.NetInvestmentIncome, in.FilingStatus = p.MAGI, p.NetInvestmentIncome, p.FilingStatus
+	in.NatlAmTPct, in.NatlInStateFraction, in.StateAmTPct = p.NatlAmTPct, p.NatlInStateFraction, p.StateAmTPct
+}
+
+// profileDir is where profiles live: $TAXABLEYIELD_PROFILES if set, else
+// taxableyield/profiles under the OS config directory (~/.config on Linux,
+// ~/Library/Application Support on macOS, %AppData% on Windows).
+func profileDir() (string, error) {
+	if dir := os.Getenv("TAXABLEYIELD_PROFILES"); dir != "" {
+		return dir, nil
+	}
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "taxableyield", "profiles"), nil
+}
+
+func profilePath(name string) (string, error) {
+	if name == "" || strings.ContainsAny(name, `/\`) || name == "." || name == ".." {
+		return "", fmt.Errorf("invalid profile name %q", name)
+	}
+	dir, err := profileDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, name+".json"), nil
+}
+
+func saveProfile(name string, p taxProfile) error {
+	path, err := profilePath(name)
+	if err != nil {
+		return err
+	}
+	b, err := json.MarshalIndent(p, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(path, append(b, '\n'), 0o644)
+}
+
+func loadProfile(name string) (taxProfile, error) {
+	var p taxProfile
+	path, err := profilePath(name)
+	if err != nil {
+		return p, err
+	}
+	b, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return p, fmt.Errorf("no profile %q", name)
+	}
+	if err != nil {
+		return p, err
+	}
+	if err := json.Unmarshal(b, &p); err != nil {
+		return p, fmt.Errorf("profile %q: %w", name, err)
+	}
+	return p, nil
+}
+
+func listProfiles() ([]string, error) {
+	dir, err := profileDir()
+	if err != nil {
+		return nil, err
+	}
+	entries, err := os.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var names []string
+	for _, e := range entries {
+		if name, ok := strings.CutSuffix(e.Name(), ".json"); ok && !e.IsDir() {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// profileFlags are the -profile, -save-profile and -list-profiles flags.
+type profileFlags struct {
+	load, save string
+	list       bool
+}
+
+func registerProfileFlags(fs *flag.FlagSet) *profileFlags {
+	var pf profileFlags
+	fs.StringVar(&pf.load, "profile", "", "load tax settings from a saved profile (flags given still win)")
+	fs.StringVar(&pf.save, "save-profile", "", "save the tax settings to a named profile")
+	fs.BoolVar(&pf.list, "list-profiles", false, "list saved profiles and exit")
+	return &pf
+}
+
+// resolve applies the profile flags after fs is parsed: loads -profile into
+// in (keeping flags set explicitly), then saves to -save-profile. With
+// -list-profiles it prints the names instead and reports done.
+func (pf *profileFlags) resolve(fs *flag.FlagSet, in *Inputs, stdout io.Writer) (done bool, err error) {
+	if pf.list {
+		names, err := listProfiles()
+		if err != nil {
+			return true, err
+		}
+		for _, n := range names {
+			fmt.Fprintln(stdout, n)
+		}
+		return true, nil
+	}
+	if pf.load != "" {
+		p, err := loadProfile(pf.load)
+		if err != nil {
+			return false, err
+		}
+		// flags given on the command line win over the profile
+		explicit := map[string]string{}
+		fs.Visit(func(f *flag.Flag) { explicit[f.Name] = f.Value.String() })
+		p.applyTo(in)
+		for name, v := range explicit {
+			if err := fs.Set(name, v); err != nil {
+				return false, err
+			}
+		}
+	}
+	if pf.save != "" {
+		if err := saveProfile(pf.save, profileOf(*in)); err != nil {
+			return false, err
+		}
+	}
+	return false, nil
+}
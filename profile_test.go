@@ -0,0 +1,59 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestProfiles(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("TAXABLEYIELD_PROFILES", dir)
+	run := func(args ...string) string {
+		t.Helper()
+		var out strings.Builder
+		if err := runCommand("muni-breakeven", args, &out); err != nil {
+			t.Fatalf("%q: %v", args, err)
+		}
+		return out.String()
+	}
+
+	if out := run("-list-profiles"); out != "" {
+		t.Errorf("profiles before any were saved: %q", out)
+	}
+	want := run("-fed", "32", "-state", "9.3", "-itemize", "-amt", "-amt-bracket", "4", "-save-profile", "alice")
+	run("-fed", "12", "-save-profile", "bob")
+	if _, err := os.Stat(filepath.Join(dir, "alice.json")); err != nil {
+		t.Errorf("alice's profile: %v", err)
+	}
+	if out := run("-list-profiles"); out != "alice\nbob\n" {
+		t.Errorf("-list-profiles printed %q, want alice and bob", out)
+	}
+
+	if got := run("-profile", "alice"); got != want {
+		t.Errorf("-profile alice:\n%s\nwant\n%s", got, want)
+	}
+	p, err := loadProfile("alice")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.FedBracket != 32 || p.StateBracket != 9.3 || !p.Itemize || !p.AMT || p.AMTBracketIndex != 4 {
+		t.Errorf("alice's profile %+v", p)
+	}
+
+	// flags given still win over the profile
+	if got, other := run("-profile", "alice", "-state", "0"), run("-fed", "32", "-state", "0", "-itemize", "-amt", "-amt-bracket", "4"); got != other {
+		t.Errorf("-profile alice -state 0:\n%s\nwant\n%s", got, other)
+	}
+
+	var out strings.Builder
+	if err := runCommand("muni-breakeven", []string{"-profile", "carol"}, &out); err == nil || !strings.Contains(err.Error(), `no profile "carol"`) {
+		t.Errorf("missing profile: %v", err)
+	}
+	for _, name := range []string{"../evil", `a\b`, ".."} {
+		if err := saveProfile(name, taxProfile{}); err == nil {
+			t.Errorf("saved a profile named %q", name)
+		}
+	}
+}
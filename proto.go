@@ -0,0 +1,351 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"math"
+	"slices"
+
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+// Result travels as the Result message in proto/taxableyield.proto. The
+// encoding is written by hand with protowire rather than generated, so
+// there's no protoc step. As in JSON, a NaN double is left unset and an
+// unset double decodes as NaN; the exception is spread_to_treasury, whose
+// map values carry NaN itself.
+
+// Result message field numbers
+const (
+	resultFullyTaxableAfterTax protowire.Number = iota + 1
+	resultFullyTaxableTEY
+	resultTreasuryAfterTax
+	resultTreasuryTEY
+	resultNatlAfterTax
+	resultNatlTEY
+	resultStateAfterTax
+	resultStateTEY
+	resultAMTFreeAfterTax
+	resultAMTFreeTEY
+	resultFullyTaxableType
+	resultTreasuryType
+	resultNatlTaxExemptType
+	resultStateTaxExemptType
+	resultAMTFreeType
+	resultLines
+	resultGrossUp
+	resultText
+	resultAppliedFeatures
+	resultSpreadToTreasury
+)
+
+// ResultLine message field numbers
+const (
+	lineKind protowire.Number = iota + 1
+	lineLabel
+	lineYield
+	lineAfterTax
+	lineTEY
+	lineBasis
+	lineEffectiveTaxRate
+	lineAfterTaxAfterFee
+	lineNotes
+	lineTEYSpreadBps
+	lineTEYVsFullyTaxable
+	lineTEYVsTreasury
+)
+
+// MarshalProto encodes r as a Result message.
+func (r Result) MarshalProto() ([]byte, error) {
+	var b []byte
+	b = appendDouble(b, resultFullyTaxableAfterTax, r.FullyTaxableAfterTax)
+	b = appendDouble(b, resultFullyTaxableTEY, r.FullyTaxableTEY)
+	b = appendDouble(b, resultTreasuryAfterTax, r.TreasuryAfterTax)
+	b = appendDouble(b, resultTreasuryTEY, r.TreasuryTEY)
+	b = appendDouble(b, resultNatlAfterTax, r.NatlAfterTax)
+	b = appendDouble(b, resultNatlTEY, r.NatlTEY)
+	b = appendDouble(b, resultStateAfterTax, r.StateAfterTax)
+	b = appendDouble(b, resultStateTEY, r.StateTEY)
+	b = appendDouble(b, resultAMTFreeAfterTax, r.AMTFreeAfterTax)
+	b = appendDouble(b, resultAMTFreeTEY, r.AMTFreeTEY)
+	b = appendEnum(b, resultFullyTaxableType, int(r.FullyTaxableType))
+	b = appendEnum(b, resultTreasuryType, int(r.TreasuryType))
+	b = appendEnum(b, resultNatlTaxExemptType, int(r.NatlTaxExemptType))
+	b = appendEnum(b, resultStateTaxExemptType, int(r.StateTaxExemptType))
+	b = appendEnum(b, resultAMTFreeType, int(r.AMTFreeType))
+	for _, l := range r.Lines {
+		b = protowire.AppendTag(b, resultLines, protowire.BytesType)
+		b = protowire.AppendBytes(b, l.appendProto(nil))
+	}
+	b = appendDouble(b, resultGrossUp, r.GrossUp)
+	if r.Text != "" {
+		b = protowire.AppendTag(b, resultText, protowire.BytesType)
+		b = protowire.AppendString(b, r.Text)
+	}
+	for _, f := range r.AppliedFeatures {
+		b = protowire.AppendTag(b, resultAppliedFeatures, protowire.BytesType)
+		b = protowire.AppendString(b, f)
+	}
+	for _, k := range slices.Sorted(maps.Keys(r.SpreadToTreasury)) {
+		// map entries are messages {key = 1, value = 2}. Map values can't
+		// be optional, so unlike other doubles a NaN is written as is.
+		var e []byte
+		e = protowire.AppendTag(e, 1, protowire.BytesType)
+		e = protowire.AppendString(e, k)
+		e = protowire.AppendTag(e, 2, protowire.Fixed64Type)
+		e = protowire.AppendFixed64(e, math.Float64bits(r.SpreadToTreasury[k]))
+		b = protowire.AppendTag(b, resultSpreadToTreasury, protowire.BytesType)
+		b = protowire.AppendBytes(b, e)
+	}
+	return b, nil
+}
+
+func (l ResultLine) appendProto(b []byte) []byte {
+	b = appendEnum(b, lineKind, int(l.Kind))
+	if l.Label != "" {
+		b = protowire.AppendTag(b, lineLabel, protowire.BytesType)
+		b = protowire.AppendString(b, l.Label)
+	}
+	b = appendDouble(b, lineYield, l.Yield)
+	b = appendDouble(b, lineAfterTax, l.AfterTax)
+	b = appendDouble(b, lineTEY, l.TEY)
+	b = appendEnum(b, lineBasis, int(l.Basis))
+	b = appendDouble(b, lineEffectiveTaxRate, l.EffectiveTaxRate)
+	b = appendDouble(b, lineAfterTaxAfterFee, l.AfterTaxAfterFee)
+	b = appendDouble(b, lineTEYSpreadBps, l.TEYSpreadBps)
+	b = appendDouble(b, lineTEYVsFullyTaxable, l.TEYVsFullyTaxable)
+	b = appendDouble(b, lineTEYVsTreasury, l.TEYVsTreasury)
+	for _, n := range l.Notes {
+		b = protowire.AppendTag(b, lineNotes, protowire.BytesType)
+		b = protowire.AppendString(b, n)
+	}
+	return b
+}
+
+// UnmarshalResultProto decodes a Result message. Unknown fields are skipped.
+func UnmarshalResultProto(b []byte) (Result, error) {
+	r := Result{
+		FullyTaxableAfterTax: math.NaN(),
+		FullyTaxableTEY:      math.NaN(),
+		TreasuryAfterTax:     math.NaN(),
+		TreasuryTEY:          math.NaN(),
+		NatlAfterTax:         math.NaN(),
+		NatlTEY:              math.NaN(),
+		StateAfterTax:        math.NaN(),
+		StateTEY:             math.NaN(),
+		AMTFreeAfterTax:      math.NaN(),
+		AMTFreeTEY:           math.NaN(),
+		GrossUp:              math.NaN(),
+	}
+	doubles := map[protowire.Number]*float64{
+		resultFullyTaxableAfterTax: &r.FullyTaxableAfterTax,
+		resultFullyTaxableTEY:      &r.FullyTaxableTEY,
+		resultTreasuryAfterTax:     &r.TreasuryAfterTax,
+		resultTreasuryTEY:          &r.TreasuryTEY,
+		resultNatlAfterTax:         &r.NatlAfterTax,
+		resultNatlTEY:              &r.NatlTEY,
+		resultStateAfterTax:        &r.StateAfterTax,
+		resultStateTEY:             &r.StateTEY,
+		resultAMTFreeAfterTax:      &r.AMTFreeAfterTax,
+		resultAMTFreeTEY:           &r.AMTFreeTEY,
+		resultGrossUp:              &r.GrossUp,
+	}
+	types := map[protowire.Number]*YieldType{
+		resultFullyTaxableType:   &r.FullyTaxableType,
+		resultTreasuryType:       &r.TreasuryType,
+		resultNatlTaxExemptType:  &r.NatlTaxExemptType,
+		resultStateTaxExemptType: &r.StateTaxExemptType,
+		resultAMTFreeType:        &r.AMTFreeType,
+	}
+	err := consumeFields(b, func(num protowire.Number, typ protowire.Type, v []byte) (int, error) {
+		switch {
+		case doubles[num] != nil:
+			return consumeDouble(v, typ, doubles[num])
+		case types[num] != nil:
+			var e int
+			n, err := consumeEnum(v, typ, &e)
+			*types[num] = YieldType(e)
+			return n, err
+		case num == resultLines:
+			msg, n, err := consumeBytes(v, typ)
+			if err != nil {
+				return n, err
+			}
+			l, err := unmarshalLineProto(msg)
+			if err != nil {
+				return n, fmt.Errorf("lines[%d]: %w", len(r.Lines), err)
+			}
+			r.Lines = append(r.Lines, l)
+			return n, nil
+		case num == resultText:
+			msg, n, err := consumeBytes(v, typ)
+			r.Text = string(msg)
+			return n, err
+		case num == resultAppliedFeatures:
+			s, n, err := consumeBytes(v, typ)
+			if err == nil {
+				r.AppliedFeatures = append(r.AppliedFeatures, string(s))
+			}
+			return n, err
+		case num == resultSpreadToTreasury:
+			msg, n, err := consumeBytes(v, typ)
+			if err != nil {
+				return n, err
+			}
+			var key string
+			var val float64
+			err = consumeFields(msg, func(num protowire.Number, typ protowire.Type, v []byte) (int, error) {
+				switch num {
+				case 1:
+					s, n, err := consumeBytes(v, typ)
+					key = string(s)
+					return n, err
+				case 2:
+					return consumeDouble(v, typ, &val)
+				}
+				return -1, nil
+			})
+			if r.SpreadToTreasury == nil {
+				r.SpreadToTreasury = map[string]float64{}
+			}
+			r.SpreadToTreasury[key] = val
+			return n, err
+		}
+		return -1, nil
+	})
+	if err != nil {
+		return Result{}, err
+	}
+	return r, nil
+}
+
+func unmarshalLineProto(b []byte) (ResultLine, error) {
+	l := ResultLine{
+		Yield:             math.NaN(),
+		AfterTax:          math.NaN(),
+		TEY:               math.NaN(),
+		EffectiveTaxRate:  math.NaN(),
+		AfterTaxAfterFee:  math.NaN(),
+		TEYSpreadBps:      math.NaN(),
+		TEYVsFullyTaxable: math.NaN(),
+		TEYVsTreasury:     math.NaN(),
+	}
+	doubles := map[protowire.Number]*float64{
+		lineYield:             &l.Yield,
+		lineAfterTax:          &l.AfterTax,
+		lineTEY:               &l.TEY,
+		lineEffectiveTaxRate:  &l.EffectiveTaxRate,
+		lineAfterTaxAfterFee:  &l.AfterTaxAfterFee,
+		lineTEYSpreadBps:      &l.TEYSpreadBps,
+		lineTEYVsFullyTaxable: &l.TEYVsFullyTaxable,
+		lineTEYVsTreasury:     &l.TEYVsTreasury,
+	}
+	err := consumeFields(b, func(num protowire.Number, typ protowire.Type, v []byte) (int, error) {
+		switch {
+		case doubles[num] != nil:
+			return consumeDouble(v, typ, doubles[num])
+		case num == lineKind:
+			var e int
+			n, err := consumeEnum(v, typ, &e)
+			l.Kind = InstrumentKind(e)
+			return n, err
+		case num == lineBasis:
+			var e int
+			n, err := consumeEnum(v, typ, &e)
+			l.Basis = YieldType(e)
+			return n, err
+		case num == lineLabel:
+			s, n, err := consumeBytes(v, typ)
+			l.Label = string(s)
+			return n, err
+		case num == lineNotes:
+			s, n, err := consumeBytes(v, typ)
+			if err == nil {
+				l.Notes = append(l.Notes, string(s))
+			}
+			return n, err
+		}
+		return -1, nil
+	})
+	return l, err
+}
+
+// appendDouble writes f unless it's NaN, which is left unset.
+func appendDouble(b []byte, num protowire.Number, f float64) []byte {
+	if math.IsNaN(f) {
+		return b
+	}
+	b = protowire.AppendTag(b, num, protowire.Fixed64Type)
+	return protowire.AppendFixed64(b, math.Float64bits(f))
+}
+
+// appendEnum writes e unless it's the zero value, as proto3 does.
+func appendEnum(b []byte, num protowire.Number, e int) []byte {
+	if e == 0 {
+		return b
+	}
+	b = protowire.AppendTag(b, num, protowire.VarintType)
+	return protowire.AppendVarint(b, uint64(int64(e)))
+}
+
+var errWireType = errors.New("wrong wire type")
+
+// consumeFields calls field for each field in b with the bytes after its
+// tag. field returns how many it used, or -1 to have the field skipped.
+func consumeFields(b []byte, field func(protowire.Number, protowire.Type, []byte) (int, error)) error {
+	for len(b) > 0 {
+		num, typ, n := protowire.ConsumeTag(b)
+		if n < 0 {
+			return protowire.ParseError(n)
+		}
+		b = b[n:]
+		n, err := field(num, typ, b)
+		if err != nil {
+			return fmt.Errorf("field %d: %w", num, err)
+		}
+		if n < 0 {
+			n = protowire.ConsumeFieldValue(num, typ, b)
+			if n < 0 {
+				return fmt.Errorf("field %d: %w", num, protowire.ParseError(n))
+			}
+		}
+		b = b[n:]
+	}
+	return nil
+}
+
+func consumeDouble(b []byte, typ protowire.Type, f *float64) (int, error) {
+	if typ != protowire.Fixed64Type {
+		return 0, errWireType
+	}
+	v, n := protowire.ConsumeFixed64(b)
+	if n < 0 {
+		return 0, protowire.ParseError(n)
+	}
+	*f = math.Float64frombits(v)
+	return n, nil
+}
+
+func consumeEnum(b []byte, typ protowire.Type, e *int) (int, error) {
+	if typ != protowire.VarintType {
+		return 0, errWireType
+	}
+	v, n := protowire.ConsumeVarint(b)
+	if n < 0 {
+		return 0, protowire.ParseError(n)
+	}
+	*e = int(int32(v))
+	return n, nil
+}
+
+func consumeBytes(b []byte, typ protowire.Type) ([]byte, int, error) {
+	if typ != protowire.BytesType {
+		return nil, 0, errWireType
+	}
+	v, n := protowire.ConsumeBytes(b)
+	if n < 0 {
+		return nil, 0, protowire.ParseError(n)
+	}
+	return v, n, nil
+}
@@ -0,0 +1,71 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"testing"
+)
+
+func TestResultProtoRoundTrip(t *testing.T) {
+	fallback := exampleInputs()
+	fallback.FullyTaxable = math.NaN()
+	amt := exampleInputs()
+	amt.AMT = true
+	noTreasury := exampleInputs()
+	noTreasury.Treasury = 0
+
+	for name, in := range map[string]Inputs{
+		"example":      exampleInputs(),
+		"NaN fallback": fallback,
+		"AMT":          amt,
+		"no treasury":  noTreasury,
+	} {
+		t.Run(name, func(t *testing.T) {
+			want := Compute(in)
+			b, err := want.MarshalProto()
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, err := UnmarshalResultProto(b)
+			if err != nil {
+				t.Fatal(err)
+			}
+			// render isn't on the wire; %v compares NaNs as equal
+			want.render = renderOptions{}
+			if g, w := fmt.Sprintf("%+v", got), fmt.Sprintf("%+v", want); g != w {
+				t.Errorf("round trip changed the result:\n got %s\nwant %s", g, w)
+			}
+		})
+	}
+}
+
+func TestResultProtoNaNSpread(t *testing.T) {
+	in := exampleInputs()
+	in.FullyTaxable = math.NaN()
+	res := Compute(in)
+	// spreadToTreasury skips NaN lines, but the encoding shouldn't rely on it
+	res.SpreadToTreasury["fully_taxable"] = math.NaN()
+	b, err := res.MarshalProto()
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := UnmarshalResultProto(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if v, ok := got.SpreadToTreasury["fully_taxable"]; !ok || !math.IsNaN(v) {
+		t.Errorf("fully_taxable spread decoded as %v, %v; want NaN", v, ok)
+	}
+	if v, ok := got.SpreadToTreasury["natl"]; !ok || v != res.SpreadToTreasury["natl"] {
+		t.Errorf("natl spread decoded as %v, want %v", v, res.SpreadToTreasury["natl"])
+	}
+	if !math.IsNaN(got.FullyTaxableAfterTax) || !math.IsNaN(got.FullyTaxableTEY) {
+		t.Errorf("fully-taxable fields decoded as %v, %v; want NaN", got.FullyTaxableAfterTax, got.FullyTaxableTEY)
+	}
+}
+
+func TestUnmarshalResultProtoBadInput(t *testing.T) {
+	if _, err := UnmarshalResultProto([]byte{0x0a}); err == nil {
+		t.Error("truncated message decoded without error")
+	}
+}
@@ -0,0 +1,54 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"sort"
+)
+
+// RankByAfterTax is r's lines, best after-tax yield first. Ties keep the
+// lines' order in r (the standard instrument order), so the ranking is the
+// same from run to run; NaNs go last.
+func (r Result) RankByAfterTax() []ResultLine {
+	return rankLines(r.Lines, func(l ResultLine) float64 { return l.AfterTax })
+}
+
+// RankByTEY is RankByAfterTax, by tax equivalent yield.
+func (r Result) RankByTEY() []ResultLine {
+	return rankLines(r.Lines, func(l ResultLine) float64 { return l.TEY })
+}
+
+func rankLines(lines []ResultLine, by func(ResultLine) float64) []ResultLine {
+	ranked := append([]ResultLine(nil), lines...)
+	sort.SliceStable(ranked, func(i, j int) bool {
+		a, b := by(ranked[i]), by(ranked[j])
+		if math.IsNaN(b) {
+			return !math.IsNaN(a)
+		}
+		return a > b
+	})
+	return ranked
+}
+
+// Summary is r in one line for logs and tooltips: the best line after tax
+// and the gross-up, e.g.
+//
+//	Best: Nat'l Tax-Exempt 3.45% AT (TEY 5.00%); gross-up 1.45x
+//
+// Lines whose after-tax yield is zero or NaN don't count, so a Result with
+// nothing to compare says so instead of naming a best.
+func (r Result) Summary() string {
+	ranked := r.RankByAfterTax()
+	if len(ranked) == 0 || !(ranked[0].AfterTax > 0) {
+		return "Best: n/a (no yields to compare)"
+	}
+	best := ranked[0]
+	s := fmt.Sprintf("Best: %s %.2f%% AT", best.Label, best.AfterTax)
+	if isFinite(best.TEY) {
+		s += fmt.Sprintf(" (TEY %.2f%%)", best.TEY)
+	}
+	if isFinite(r.GrossUp) {
+		s += fmt.Sprintf("; gross-up %.2fx", r.GrossUp)
+	}
+	return s
+}
@@ -0,0 +1,72 @@
+package main
+
+import (
+	"math"
+	"slices"
+	"testing"
+)
+
+func labels(lines []ResultLine) []string {
+	var s []string
+	for _, l := range lines {
+		s = append(s, l.Label)
+	}
+	return s
+}
+
+func TestRankTies(t *testing.T) {
+	// without state tax, 4.5% taxable and Treasury and 3.42% munis all net
+	// 3.42%
+	in := Inputs{FullyTaxable: 4.5, Treasury: 4.5, NatlTaxExempt: 3.42, StateTaxExempt: 3.42, AMTFree: 3.2, FedBracket: 24}
+	want := []string{"Fully Taxable", "Treasury", "Nat'l Tax-Exempt", "State Tax-Exempt", "AMT Free"}
+	for range 50 {
+		res := Compute(in)
+		if got := labels(res.RankByAfterTax()); !slices.Equal(got, want) {
+			t.Fatalf("after-tax ranking %q, want ties in instrument order %q", got, want)
+		}
+		if got := labels(res.RankByTEY()); !slices.Equal(got, want) {
+			t.Fatalf("TEY ranking %q, want %q", got, want)
+		}
+	}
+
+	in.AMTFree = 3.5
+	if got := labels(Compute(in).RankByAfterTax()); got[0] != "AMT Free" || !slices.Equal(got[1:], want[:4]) {
+		t.Errorf("ranking %q, want AMT Free first, then the ties in order", got)
+	}
+}
+
+func TestRankNaNLast(t *testing.T) {
+	r := Result{Lines: []ResultLine{
+		{Label: "a", AfterTax: math.NaN()},
+		{Label: "b", AfterTax: 2},
+		{Label: "c", AfterTax: math.NaN()},
+		{Label: "d", AfterTax: 3},
+	}}
+	if got := labels(r.RankByAfterTax()); !slices.Equal(got, []string{"d", "b", "a", "c"}) {
+		t.Errorf("ranking %q, want [d b a c]", got)
+	}
+	if r.Lines[0].Label != "a" {
+		t.Error("ranking reordered r.Lines")
+	}
+}
+
+func TestSummary(t *testing.T) {
+	for _, tt := range []struct {
+		name string
+		res  Result
+		want string
+	}{
+		{"standard", Compute(exampleInputs()), "Best: AMT Free 3.70% AT (TEY 5.37%); gross-up 1.45x"},
+		// 3.5 * 0.95 beats 3.2, grossed up by 1 / 0.71
+		{"all exempt", Compute(Inputs{FedBracket: 24, StateBracket: 5, NatlTaxExempt: 3.5, StateTaxExempt: 3.2}),
+			"Best: Nat'l Tax-Exempt 3.32% AT (TEY 4.68%); gross-up 1.41x"},
+		{"all zero", Compute(Inputs{FedBracket: 24, StateBracket: 5}), "Best: n/a (no yields to compare)"},
+		{"empty", Result{}, "Best: n/a (no yields to compare)"},
+		{"no TEY or gross-up", Result{Lines: []ResultLine{{Label: "Skipped", AfterTax: math.NaN()}, {Label: "CD", AfterTax: 2, TEY: math.NaN()}}, GrossUp: math.Inf(1)},
+			"Best: CD 2.00% AT"},
+	} {
+		if got := tt.res.Summary(); got != tt.want {
+			t.Errorf("%s: %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,72 @@
+package main
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// StandardBrackets are the federal brackets (%) a rate card usually shows.
+var StandardBrackets = []float64{12, 22, 24, 32, 35, 37}
+
+// RateCard is a table of tax equivalent yields for in-state (double-exempt)
+// munis: card[i][j] is the TEY of muniYields[i] at federal bracket
+// brackets[j]. The state bracket, AMT and the rest come from in.
+func RateCard(muniYields []float64, brackets []float64, in Inputs) [][]float64 {
+	card := make([][]float64, len(muniYields))
+	for i, y := range muniYields {
+		card[i] = make([]float64, len(brackets))
+		for j, fed := range brackets {
+			in.FedBracket = fed
+			muni := Instrument{Kind: StateTaxExemptKind, Yield: y, AMTPct: in.StateAmTPct}
+			card[i][j] = muni.tey(muni.AfterTax(in), grossUpFactor(in), in)
+		}
+	}
+	return card
+}
+
+// WriteRateCardCSV writes card as CSV, with a header row of brackets and the
+// muni yield leading each row. Values are plain numbers (no %).
+func WriteRateCardCSV(w io.Writer, muniYields, brackets []float64, card [][]float64) error {
+	cw := csv.NewWriter(w)
+	header := []string{"muni_yield"}
+	for _, b := range brackets {
+		header = append(header, strconv.FormatFloat(b, 'f', -1, 64))
+	}
+	if err := cw.Write(header); err != nil {
+		return err
+	}
+	for i, row := range card {
+		rec := []string{strconv.FormatFloat(muniYields[i], 'f', -1, 64)}
+		for _, v := range row {
+			rec = append(rec, strconv.FormatFloat(v, 'f', 3, 64))
+		}
+		if err := cw.Write(rec); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// RateCardMarkdown renders card as a Markdown table.
+func RateCardMarkdown(muniYields, brackets []float64, card [][]float64) string {
+	var b strings.Builder
+	b.WriteString("| Muni yield |")
+	for _, br := range brackets {
+		fmt.Fprintf(&b, " %g%% |", br)
+	}
+	b.WriteString("\n|---|")
+	b.WriteString(strings.Repeat("---|", len(brackets)))
+	b.WriteByte('\n')
+	for i, row := range card {
+		fmt.Fprintf(&b, "| %.3f%% |", muniYields[i])
+		for _, v := range row {
+			fmt.Fprintf(&b, " %.3f%% |", v)
+		}
+		b.WriteByte('\n')
+	}
+	return b.String()
+}
@@ -0,0 +1,40 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRateCard(t *testing.T) {
+	in := Inputs{StateBracket: 5, Itemize: true}
+	yields := []float64{3, 4}
+	card := RateCard(yields, StandardBrackets, in)
+	if len(card) != 2 || len(card[0]) != len(StandardBrackets) {
+		t.Fatalf("card is %dx%d", len(card), len(card[0]))
+	}
+	// 3% in-state muni at 24%: grossed up by the combined rate,
+	// 24 + 5 - 5*0.24 (the itemized deduction) = 27.8%
+	if got, want := card[0][2], 3/(1-0.278); !near(got, want) {
+		t.Errorf("3%% at 24%%: %v, want %v", got, want)
+	}
+	for i := range card {
+		for j := 1; j < len(card[i]); j++ {
+			if !(card[i][j] > card[i][j-1]) {
+				t.Errorf("row %d: TEY doesn't rise with the bracket: %v", i, card[i])
+			}
+		}
+	}
+
+	var csv strings.Builder
+	if err := WriteRateCardCSV(&csv, yields, StandardBrackets, card); err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(csv.String(), "\n")
+	if lines[0] != "muni_yield,12,22,24,32,35,37" || !strings.HasPrefix(lines[1], "3,") || !strings.Contains(lines[1], ",4.155,") {
+		t.Errorf("CSV:\n%s", csv.String())
+	}
+	md := RateCardMarkdown(yields, StandardBrackets, card)
+	if !strings.HasPrefix(md, "| Muni yield | 12% | 22% |") || !strings.Contains(md, "| 3.000% |") || !strings.Contains(md, " 4.155% |") {
+		t.Errorf("Markdown:\n%s", md)
+	}
+}
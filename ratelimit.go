@@ -0,0 +1,120 @@
+package main
+
+import (
+	"container/list"
+	"fmt"
+	"math"
+	"net"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// rateLimiter is a token bucket per client: each holds up to burst tokens,
+// refilled at rate per second, and a request spends one.
+type rateLimiter struct {
+	rate      float64
+	burst     float64
+	keyHeader string          // if set and sent with one of keys, clients are keyed by it, not IP
+	keys      map[string]bool // the API keys keyHeader may carry
+	capacity  int             // most buckets kept; the least recently used go first
+	now       func() time.Time
+
+	mu      sync.Mutex
+	buckets map[string]*list.Element // of *bucket, in lru
+	lru     *list.List               // most recently used first
+}
+
+type bucket struct {
+	key    string
+	tokens float64
+	last   time.Time
+}
+
+// maxBuckets is how many clients the limiter tracks. Past it, the one heard
+// from least recently is forgotten (and starts over with a full bucket).
+const maxBuckets = 10000
+
+func newRateLimiter(rate float64, burst int, keyHeader string, keys map[string]bool) *rateLimiter {
+	return &rateLimiter{rate: rate, burst: float64(max(burst, 1)), keyHeader: keyHeader, keys: keys,
+		capacity: maxBuckets, now: time.Now, buckets: map[string]*list.Element{}, lru: list.New()}
+}
+
+// allow spends a token of key's, or says how long until one is available.
+func (l *rateLimiter) allow(key string) (ok bool, retryAfter time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	now := l.now()
+	e, found := l.buckets[key]
+	if found {
+		l.lru.MoveToFront(e)
+	} else {
+		if l.lru.Len() >= l.capacity {
+			oldest := l.lru.Back()
+			l.lru.Remove(oldest)
+			delete(l.buckets, oldest.Value.(*bucket).key)
+		}
+		e = l.lru.PushFront(&bucket{key: key, tokens: l.burst, last: now})
+		l.buckets[key] = e
+	}
+	b := e.Value.(*bucket)
+	b.tokens = math.Min(l.burst, b.tokens+now.Sub(b.last).Seconds()*l.rate)
+	b.last = now
+	if b.tokens >= 1 {
+		b.tokens--
+		return true, 0
+	}
+	return false, time.Duration((1 - b.tokens) / l.rate * float64(time.Second))
+}
+
+// clientKey is the API key header if configured and sent with a known key,
+// else the IP. An unknown key counts against the IP, so making keys up
+// doesn't get a client more buckets.
+func (l *rateLimiter) clientKey(r *http.Request) string {
+	if l.keyHeader != "" {
+		if k := r.Header.Get(l.keyHeader); k != "" && l.keys[k] {
+			return "key:" + k
+		}
+	}
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+	return "ip:" + host
+}
+
+// middleware answers 429 with Retry-After (whole seconds, rounded up) once
+// a client runs out of tokens.
+func (l *rateLimiter) middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ok, wait := l.allow(l.clientKey(r))
+		if !ok {
+			w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(wait.Seconds()))))
+			httpError(w, r, "rate limit exceeded", http.StatusTooManyRequests)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// readRateKeys reads the API keys in path, one per line. Blank lines and
+// lines starting with # are skipped.
+func readRateKeys(path string) (map[string]bool, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	keys := map[string]bool{}
+	for _, line := range strings.Split(string(b), "\n") {
+		if k := strings.TrimSpace(line); k != "" && !strings.HasPrefix(k, "#") {
+			keys[k] = true
+		}
+	}
+	if len(keys) == 0 {
+		return nil, fmt.Errorf("%s: no API keys", path)
+	}
+	return keys, nil
+}
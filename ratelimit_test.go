@@ -0,0 +1,140 @@
+package main
+
+import (
+	"io"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestRateLimit(t *testing.T) {
+	cfg := defaultServerConfig
+	cfg.RateLimit, cfg.RateBurst, cfg.RateKeyHeader = 0.5, 3, "X-API-Key"
+	cfg.RateKeys = map[string]bool{"k1": true}
+	srv := newServer(cfg)
+	get := func(ip, key string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("GET", "/openapi.json", nil)
+		req.RemoteAddr = ip + ":4321"
+		if key != "" {
+			req.Header.Set("X-API-Key", key)
+		}
+		rec := httptest.NewRecorder()
+		srv.ServeHTTP(rec, req)
+		return rec
+	}
+
+	for i := range 3 {
+		if rec := get("192.0.2.1", ""); rec.Code != 200 {
+			t.Fatalf("request %d: %d, want 200 within the burst", i+1, rec.Code)
+		}
+	}
+	rec := get("192.0.2.1", "")
+	if rec.Code != 429 {
+		t.Fatalf("4th request: %d, want 429", rec.Code)
+	}
+	// a token every 2s
+	if got := rec.Header().Get("Retry-After"); got != "2" {
+		t.Errorf("Retry-After %q, want 2", got)
+	}
+	if rec.Header().Get(requestIDHeader) == "" {
+		t.Error("429 without a request ID")
+	}
+
+	// other clients have their own buckets
+	if rec := get("192.0.2.2", ""); rec.Code != 200 {
+		t.Errorf("another IP: %d, want 200", rec.Code)
+	}
+	if rec := get("192.0.2.1", "k1"); rec.Code != 200 {
+		t.Errorf("an API key from the limited IP: %d, want 200", rec.Code)
+	}
+	// a made-up key is just the IP again, so rotating keys doesn't help
+	for _, key := range []string{"k2", "k3"} {
+		if rec := get("192.0.2.1", key); rec.Code != 429 {
+			t.Errorf("unknown key %s from the limited IP: %d, want 429", key, rec.Code)
+		}
+	}
+
+	// off by default
+	srv = newServer(defaultServerConfig)
+	for i := range 50 {
+		if rec := get("192.0.2.1", ""); rec.Code != 200 {
+			t.Fatalf("unlimited request %d: %d", i+1, rec.Code)
+		}
+	}
+}
+
+func TestRateLimiterRefill(t *testing.T) {
+	now := time.Unix(0, 0)
+	l := newRateLimiter(2, 2, "", nil)
+	l.now = func() time.Time { return now }
+	for range 2 {
+		if ok, _ := l.allow("a"); !ok {
+			t.Fatal("denied within the burst")
+		}
+	}
+	if ok, wait := l.allow("a"); ok || wait != 500*time.Millisecond {
+		t.Fatalf("allow after the burst = %v, %v; want false, 500ms", ok, wait)
+	}
+	now = now.Add(250 * time.Millisecond)
+	if ok, wait := l.allow("a"); ok || wait != 250*time.Millisecond {
+		t.Errorf("allow a quarter second on = %v, %v; want false, 250ms", ok, wait)
+	}
+	now = now.Add(250 * time.Millisecond)
+	if ok, _ := l.allow("a"); !ok {
+		t.Error("denied after a token refilled")
+	}
+	// refills stop at the burst
+	now = now.Add(time.Hour)
+	for i := range 3 {
+		if ok, _ := l.allow("a"); ok != (i < 2) {
+			t.Errorf("request %d after an hour: allowed %v", i+1, ok)
+		}
+	}
+}
+
+func TestRateLimiterEviction(t *testing.T) {
+	now := time.Unix(0, 0)
+	l := newRateLimiter(1, 1, "", nil)
+	l.capacity = 2
+	l.now = func() time.Time { return now }
+	for _, key := range []string{"a", "b"} {
+		if ok, _ := l.allow(key); !ok {
+			t.Fatalf("%s denied its first request", key)
+		}
+	}
+	l.allow("a") // denied, but a is now the most recently used
+	if ok, _ := l.allow("c"); !ok {
+		t.Fatal("c denied its first request")
+	}
+	if len(l.buckets) != 2 || l.lru.Len() != 2 {
+		t.Fatalf("%d buckets in a limiter of 2", len(l.buckets))
+	}
+	if _, kept := l.buckets["b"]; kept {
+		t.Error("b, the least recently used, wasn't evicted")
+	}
+	if ok, _ := l.allow("a"); ok {
+		t.Error("a's empty bucket was forgotten instead of b's")
+	}
+}
+
+func TestReadRateKeys(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "keys")
+	if err := os.WriteFile(path, []byte("# clients\nk1\n\n  k2  \n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	keys, err := readRateKeys(path)
+	if err != nil || len(keys) != 2 || !keys["k1"] || !keys["k2"] {
+		t.Errorf("readRateKeys = %v, %v; want k1 and k2", keys, err)
+	}
+	if err := os.WriteFile(path, []byte("# none yet\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := readRateKeys(path); err == nil {
+		t.Error("no error for a file without keys")
+	}
+	if err := runServe([]string{"-rate-limit", "1", "-rate-key-header", "X-API-Key"}, io.Discard); err == nil {
+		t.Error("-rate-key-header without -rate-keys-file: no error")
+	}
+}
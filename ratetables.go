@@ -0,0 +1,157 @@
+package main
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"sort"
+	"sync"
+)
+
+// Bracket is one step of a rate schedule: Rate (%) applies to income above
+// Over, up to the next bracket's Over.
+type Bracket struct {
+	Over float64
+	Rate float64
+}
+
+// YearTables are one tax year's federal schedules (single filer, but for
+// LTCG).
+type YearTables struct {
+	Federal []Bracket // ordinary income brackets, ascending by Over
+
+	// Taxable income where the 15% and 20% LTCG rates start, by status
+	LTCG map[FilingStatus][2]float64
+
+	// AMT: 26% up to AMTThreshold of AMTI net of the exemption, 28% above.
+	// The exemption phases out by 25 cents per dollar of AMTI over
+	// AMTPhaseoutStart.
+	AMTThreshold     float64
+	AMTExemption     float64
+	AMTPhaseoutStart float64
+}
+
+// RateTables is a registry of YearTables, safe for concurrent use. Lookups
+// take a read lock only, so the parallel batch path doesn't contend on it.
+type RateTables struct {
+	mu    sync.RWMutex
+	years map[int]YearTables
+}
+
+// DefaultRateTables holds the built-in years; Register projections on it, or
+// on a RateTables of your own.
+var DefaultRateTables = builtinRateTables()
+
+// Register adds or replaces year's tables. They're copied, so the caller can
+// reuse t.
+func (rt *RateTables) Register(year int, t YearTables) {
+	t.Federal = slices.Clone(t.Federal)
+	t.LTCG = maps.Clone(t.LTCG)
+	sort.SliceStable(t.Federal, func(i, j int) bool { return t.Federal[i].Over < t.Federal[j].Over })
+	rt.mu.Lock()
+	defer rt.mu.Unlock()
+	if rt.years == nil {
+		rt.years = map[int]YearTables{}
+	}
+	rt.years[year] = t
+}
+
+// Lookup returns year's tables. Treat them as read-only; they're shared.
+func (rt *RateTables) Lookup(year int) (YearTables, bool) {
+	rt.mu.RLock()
+	defer rt.mu.RUnlock()
+	t, ok := rt.years[year]
+	return t, ok
+}
+
+// Years lists the registered years, ascending.
+func (rt *RateTables) Years() []int {
+	rt.mu.RLock()
+	defer rt.mu.RUnlock()
+	years := make([]int, 0, len(rt.years))
+	for y := range rt.years {
+		years = append(years, y)
+	}
+	sort.Ints(years)
+	return years
+}
+
+// FederalMarginalRate is the federal bracket (%) for taxableIncome in year,
+// from DefaultRateTables.
+func FederalMarginalRate(year int, taxableIncome float64) (float64, error) {
+	t, ok := DefaultRateTables.Lookup(year)
+	if !ok {
+		return 0, fmt.Errorf("no rate tables for %d", year)
+	}
+	return t.marginalRate(taxableIncome), nil
+}
+
+// AMTMarginalRate is the marginal AMT rate (%) at amti in year: 26 or 28,
+// times 1.25 while the exemption is phasing out (the 32.5% and 35% that
+// Inputs.AMTBracketIndex offers).
+func AMTMarginalRate(year int, amti float64) (float64, error) {
+	t, ok := DefaultRateTables.Lookup(year)
+	if !ok {
+		return 0, fmt.Errorf("no rate tables for %d", year)
+	}
+	exemption := max(0, t.AMTExemption-0.25*max(0, amti-t.AMTPhaseoutStart))
+	rate := 26.0
+	if amti-exemption > t.AMTThreshold {
+		rate = 28
+	}
+	if amti > t.AMTPhaseoutStart && exemption > 0 {
+		rate *= 1.25
+	}
+	return rate, nil
+}
+
+func (t YearTables) marginalRate(income float64) float64 {
+	if len(t.Federal) == 0 {
+		return 0
+	}
+	rate := t.Federal[0].Rate
+	for _, b := range t.Federal[1:] {
+		if income > b.Over {
+			rate = b.Rate
+		}
+	}
+	return rate
+}
+
+func builtinRateTables() *RateTables {
+	rt := &RateTables{}
+	rt.Register(2023, YearTables{
+		Federal: []Bracket{
+			{0, 10}, {11000, 12}, {44725, 22}, {95375, 24},
+			{182100, 32}, {231250, 35}, {578125, 37},
+		},
+		LTCG: map[FilingStatus][2]float64{
+			Single: {44625, 492300}, MarriedJoint: {89250, 553850},
+			MarriedSeparate: {44625, 276900}, HeadOfHousehold: {59750, 523050},
+		},
+		AMTThreshold: 220700, AMTExemption: 81300, AMTPhaseoutStart: 578150,
+	})
+	rt.Register(2024, YearTables{
+		Federal: []Bracket{
+			{0, 10}, {11600, 12}, {47150, 22}, {100525, 24},
+			{191950, 32}, {243725, 35}, {609350, 37},
+		},
+		LTCG: map[FilingStatus][2]float64{
+			Single: {47025, 518900}, MarriedJoint: {94050, 583750},
+			MarriedSeparate: {47025, 291850}, HeadOfHousehold: {63000, 551350},
+		},
+		AMTThreshold: 232600, AMTExemption: 85700, AMTPhaseoutStart: 609350,
+	})
+	rt.Register(2025, YearTables{
+		Federal: []Bracket{
+			{0, 10}, {11925, 12}, {48475, 22}, {103350, 24},
+			{197300, 32}, {250525, 35}, {626350, 37},
+		},
+		LTCG: map[FilingStatus][2]float64{
+			Single: {48350, 533400}, MarriedJoint: {96700, 600050},
+			MarriedSeparate: {48350, 300000}, HeadOfHousehold: {64750, 566700},
+		},
+		AMTThreshold: 239100, AMTExemption: 88100, AMTPhaseoutStart: 626350,
+	})
+	return rt
+}
@@ -0,0 +1,95 @@
+package main
+
+import (
+	"slices"
+	"sync"
+	"testing"
+)
+
+func TestFederalMarginalRate(t *testing.T) {
+	for _, tt := range []struct {
+		year   int
+		income float64
+		want   float64
+	}{
+		{2023, 5000, 10},
+		{2023, 100000, 24},
+		{2024, 100000, 22},
+		{2024, 100525, 22}, // Over is exclusive
+		{2024, 1e6, 37},
+	} {
+		got, err := FederalMarginalRate(tt.year, tt.income)
+		if err != nil || got != tt.want {
+			t.Errorf("FederalMarginalRate(%d, %v) = %v, %v; want %v", tt.year, tt.income, got, err, tt.want)
+		}
+	}
+	if _, err := FederalMarginalRate(1900, 50000); err == nil {
+		t.Error("1900: no error for a year without tables")
+	}
+}
+
+func TestRateTablesRegister(t *testing.T) {
+	var rt RateTables
+	brackets := []Bracket{{50000, 20}, {0, 10}}
+	rt.Register(2030, YearTables{Federal: brackets})
+	brackets[0].Rate = 99
+
+	got, ok := rt.Lookup(2030)
+	if !ok {
+		t.Fatal("2030 not registered")
+	}
+	if want := []Bracket{{0, 10}, {50000, 20}}; !slices.Equal(got.Federal, want) {
+		t.Errorf("brackets %v, want %v: sorted, and the caller's slice copied", got.Federal, want)
+	}
+	if r := got.marginalRate(60000); r != 20 {
+		t.Errorf("rate at 60000 %v, want 20", r)
+	}
+
+	rt.Register(2030, YearTables{Federal: []Bracket{{0, 15}}})
+	rt.Register(2029, YearTables{})
+	if got, _ := rt.Lookup(2030); got.marginalRate(60000) != 15 {
+		t.Error("re-registering 2030 didn't replace it")
+	}
+	if years := rt.Years(); !slices.Equal(years, []int{2029, 2030}) {
+		t.Errorf("Years() = %v, want [2029 2030]", years)
+	}
+}
+
+// Run with -race: Lookup and Register from many goroutines at once, as the
+// parallel batch path does.
+func TestRateTablesConcurrent(t *testing.T) {
+	rt := builtinRateTables()
+	var wg sync.WaitGroup
+	for g := range 16 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range 1000 {
+				if g == 0 && i%100 == 0 {
+					rt.Register(3000+i, YearTables{Federal: []Bracket{{0, 10}}})
+				}
+				if tbl, ok := rt.Lookup(2024); !ok || tbl.marginalRate(100000) != 22 {
+					t.Errorf("Lookup(2024) = %v, %v", tbl, ok)
+					return
+				}
+				rt.Years()
+			}
+		}()
+	}
+	for range 8 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for range 1000 {
+				if r, err := FederalMarginalRate(2023, 200000); err != nil || r != 32 {
+					t.Errorf("FederalMarginalRate(2023, 200000) = %v, %v", r, err)
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	if got, want := len(rt.Years()), len(builtinRateTables().Years())+10; got != want {
+		t.Errorf("%d years registered, want %d", got, want)
+	}
+}
@@ -0,0 +1,25 @@
+package main
+
+import "math"
+
+// EffectiveYieldWithReinvestment is the total (realized compound) yield (%)
+// of a bond paying coupon (%) annually for years, each coupon reinvested at
+// reinvest (%) to the horizon and the principal returned at par. It's
+// coupon back when reinvest equals it, less when reinvesting at a lower rate
+// (current yield overstates the return then), more at a higher one. NaN if
+// years < 1.
+func EffectiveYieldWithReinvestment(coupon, reinvest float64, years int) float64 {
+	if years < 1 {
+		return math.NaN()
+	}
+	if reinvest == coupon {
+		return coupon // exactly, without the round trip through Pow
+	}
+	c, r, n := coupon/100, reinvest/100, float64(years)
+	// the coupons' future value: an annuity at r
+	coupons := c * n
+	if r != 0 {
+		coupons = c * (math.Pow(1+r, n) - 1) / r
+	}
+	return 100 * (math.Pow(1+coupons, 1/n) - 1)
+}
@@ -0,0 +1,67 @@
+package main
+
+import (
+	"math"
+	"testing"
+)
+
+func TestEffectiveYieldWithReinvestment(t *testing.T) {
+	// (1 + c * ((1+r)^n - 1) / r)^(1/n) - 1
+	total := func(c, r float64, n int) float64 {
+		fv := 1 + c/100*float64(n)
+		if r != 0 {
+			fv = 1 + c/100*(math.Pow(1+r/100, float64(n))-1)/(r/100)
+		}
+		return 100 * (math.Pow(fv, 1/float64(n)) - 1)
+	}
+	for _, tt := range []struct {
+		coupon, reinvest float64
+		years            int
+	}{
+		{5, 2, 10},
+		{5, 0, 10},
+		{5, 8, 30},
+		{3.5, 4.5, 5},
+	} {
+		got := EffectiveYieldWithReinvestment(tt.coupon, tt.reinvest, tt.years)
+		if want := total(tt.coupon, tt.reinvest, tt.years); !near(got, want) {
+			t.Errorf("%v%% reinvested at %v%% for %d years: %v, want %v", tt.coupon, tt.reinvest, tt.years, got, want)
+		}
+		// reinvesting below the coupon earns less than current yield, above it more
+		if (got < tt.coupon) != (tt.reinvest < tt.coupon) {
+			t.Errorf("%v%% reinvested at %v%%: total yield %v on the wrong side of the coupon", tt.coupon, tt.reinvest, got)
+		}
+	}
+	if got := EffectiveYieldWithReinvestment(5, 5, 10); got != 5 {
+		t.Errorf("reinvesting at the coupon: %v, want 5", got)
+	}
+	if got := EffectiveYieldWithReinvestment(5, 2, 1); !near(got, 5) {
+		t.Errorf("one year: %v, want the coupon", got)
+	}
+	if got := EffectiveYieldWithReinvestment(5, 2, 0); !math.IsNaN(got) {
+		t.Errorf("zero years: %v, want NaN", got)
+	}
+}
+
+func TestReinvestmentCompute(t *testing.T) {
+	in := exampleInputs()
+	current := Compute(in)
+	in.ReinvestmentRate, in.ReinvestmentYears = 2, 10
+	if got := Compute(in); got.FullyTaxableAfterTax != current.FullyTaxableAfterTax {
+		t.Errorf("no kinds opted in, but fully taxable nets %v, not %v", got.FullyTaxableAfterTax, current.FullyTaxableAfterTax)
+	}
+
+	in.Reinvest = map[InstrumentKind]bool{FullyTaxableKind: true}
+	res := Compute(in)
+	totalYield := EffectiveYieldWithReinvestment(5, 2, 10)
+	l, _ := res.Line(FullyTaxableKind)
+	if !near(l.Yield, totalYield) || !near(l.AfterTax, totalYield*(1-0.24-0.093*0.76)) {
+		t.Errorf("fully taxable at %v%% nets %v, want %v%% taxed", l.Yield, l.AfterTax, totalYield)
+	}
+	if l.AfterTax >= current.FullyTaxableAfterTax {
+		t.Errorf("reinvesting at 2%% nets %v, not below current yield's %v", l.AfterTax, current.FullyTaxableAfterTax)
+	}
+	if res.NatlAfterTax != current.NatlAfterTax || res.TreasuryAfterTax != current.TreasuryAfterTax {
+		t.Errorf("lines not opted in moved: %v, %v", res.NatlAfterTax, res.TreasuryAfterTax)
+	}
+}
@@ -0,0 +1,181 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"html"
+	"io"
+	"math"
+	"strings"
+)
+
+// renderOptions are the Inputs that shape a Result's text. A Result that
+// didn't come from Compute (decoded JSON, say) renders with the defaults.
+type renderOptions struct {
+	showFee         bool // add the after-fee column
+	showTreasuryTEY bool // there's a treasury yield for TEYVsTreasury
+	format          FormatOptions
+}
+
+// errWriter remembers the first write error and drops writes after it, so
+// the renderers can Fprintf freely and check once at the end.
+type errWriter struct {
+	w   io.Writer
+	err error
+}
+
+func (e *errWriter) Write(p []byte) (int, error) {
+	if e.err != nil {
+		return 0, e.err
+	}
+	n, err := e.w.Write(p)
+	e.err = err
+	return n, err
+}
+
+func (o renderOptions) text(r Result) string {
+	var b strings.Builder
+	o.writeText(&b, r)
+	return b.String()
+}
+
+// writeText writes the display text (3 decimals, with %, unless o.format
+// says otherwise), one line per instrument.
+func (o renderOptions) writeText(w io.Writer, r Result) {
+	f := o.format.formatter()
+	for i, l := range r.Lines {
+		if i > 0 {
+			io.WriteString(w, "\n")
+		}
+		fmt.Fprintf(w, "%-18s %s after tax, %s tax equivalent", l.Label+":", f.pct(l.AfterTax), f.pct(l.TEY))
+		if o.showFee {
+			fmt.Fprintf(w, ", %s after fee", f.pct(l.AfterTaxAfterFee))
+		}
+		if o.format.ShowBothTEY {
+			fmt.Fprintf(w, ", %s vs fully taxable", f.pct(l.TEYVsFullyTaxable))
+			if o.showTreasuryTEY {
+				fmt.Fprintf(w, ", %s vs treasury", f.pct(l.TEYVsTreasury))
+			}
+		}
+		for _, n := range l.Notes {
+			io.WriteString(w, " ["+n+"]")
+		}
+	}
+	if o.format.ShowGrossUp {
+		fmt.Fprintf(w, "\n%-18s %s", "Gross-up:", f.factor(r.GrossUp))
+	}
+	if r.MunisPointless() {
+		io.WriteString(w, "\n"+pointlessWarning)
+	}
+}
+
+// WriteText writes r's text, the same bytes as r.Text, to w.
+func (r Result) WriteText(w io.Writer) error {
+	ew := &errWriter{w: w}
+	r.render.writeText(ew, r)
+	return ew.err
+}
+
+// WriteJSON writes r as JSON to w, newline-terminated.
+func (r Result) WriteJSON(w io.Writer) error {
+	return json.NewEncoder(w).Encode(r)
+}
+
+// tableCells are a line's cells for the Markdown and HTML tables.
+func (o renderOptions) tableCells(l ResultLine) []string {
+	f := o.format.formatter()
+	label := l.Label
+	for _, n := range l.Notes {
+		label += " [" + n + "]"
+	}
+	cells := []string{label, strings.TrimSpace(f.pct(l.Yield)), strings.TrimSpace(f.pct(l.AfterTax)), strings.TrimSpace(f.pct(l.TEY))}
+	if o.showFee {
+		cells = append(cells, strings.TrimSpace(f.pct(l.AfterTaxAfterFee)))
+	}
+	if o.format.ShowSpread {
+		cells = append(cells, formatBps(l.TEYSpreadBps))
+	}
+	if o.format.ShowBothTEY {
+		cells = append(cells, strings.TrimSpace(f.pct(l.TEYVsFullyTaxable)))
+		if o.showTreasuryTEY {
+			cells = append(cells, strings.TrimSpace(f.pct(l.TEYVsTreasury)))
+		}
+	}
+	return cells
+}
+
+func (o renderOptions) tableHeader() []string {
+	h := []string{"Instrument", "Yield", "After tax", "Tax equivalent"}
+	if o.showFee {
+		h = append(h, "After fee")
+	}
+	if o.format.ShowSpread {
+		h = append(h, "TEY spread")
+	}
+	if o.format.ShowBothTEY {
+		h = append(h, "TEY vs fully taxable")
+		if o.showTreasuryTEY {
+			h = append(h, "TEY vs treasury")
+		}
+	}
+	return h
+}
+
+// formatBps renders a spread like "+12 bps", or "n/a" for NaN.
+func formatBps(bps float64) string {
+	if math.IsNaN(bps) {
+		return "n/a"
+	}
+	return fmt.Sprintf("%+.0f bps", bps)
+}
+
+// WriteMarkdown writes r's lines to w as a Markdown table.
+func (r Result) WriteMarkdown(w io.Writer) error {
+	ew := &errWriter{w: w}
+	row := func(cells []string) {
+		for i, c := range cells {
+			cells[i] = strings.ReplaceAll(c, "|", `\|`)
+		}
+		fmt.Fprintf(ew, "| %s |\n", strings.Join(cells, " | "))
+	}
+	h := r.render.tableHeader()
+	row(h)
+	io.WriteString(ew, "|---"+strings.Repeat("|--:", len(h)-1)+"|\n")
+	for _, l := range r.Lines {
+		row(r.render.tableCells(l))
+	}
+	return ew.err
+}
+
+// Markdown is WriteMarkdown's output as a string.
+func (r Result) Markdown() string {
+	var b strings.Builder
+	r.WriteMarkdown(&b)
+	return b.String()
+}
+
+// WriteHTML writes r's lines to w as an HTML table.
+func (r Result) WriteHTML(w io.Writer) error {
+	ew := &errWriter{w: w}
+	row := func(tag string, cells []string) {
+		io.WriteString(ew, "<tr>")
+		for _, c := range cells {
+			fmt.Fprintf(ew, "<%s>%s</%s>", tag, html.EscapeString(c), tag)
+		}
+		io.WriteString(ew, "</tr>\n")
+	}
+	io.WriteString(ew, "<table>\n")
+	row("th", r.render.tableHeader())
+	for _, l := range r.Lines {
+		row("td", r.render.tableCells(l))
+	}
+	io.WriteString(ew, "</table>\n")
+	return ew.err
+}
+
+// HTML is WriteHTML's output as a string.
+func (r Result) HTML() string {
+	var b strings.Builder
+	r.WriteHTML(&b)
+	return b.String()
+}
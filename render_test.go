@@ -0,0 +1,126 @@
+package main
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func TestWritersMatchStrings(t *testing.T) {
+	fee := exampleInputs()
+	fee.AdvisoryFee = 0.25
+	both := exampleInputs()
+	both.Format = FormatOptions{ShowBothTEY: true, ShowGrossUp: true, ShowSpread: true}
+	for name, in := range map[string]Inputs{
+		"example":   exampleInputs(),
+		"fee":       fee,
+		"both TEYs": both,
+		"pointless": {FullyTaxable: 5, NatlTaxExempt: 4},
+	} {
+		res := Compute(in)
+		var text, md, html, js bytes.Buffer
+		for _, err := range []error{res.WriteText(&text), res.WriteMarkdown(&md), res.WriteHTML(&html), res.WriteJSON(&js)} {
+			if err != nil {
+				t.Fatalf("%s: %v", name, err)
+			}
+		}
+		if text.String() != res.Text {
+			t.Errorf("%s: WriteText\n%q\nText\n%q", name, text.String(), res.Text)
+		}
+		if md.String() != res.Markdown() {
+			t.Errorf("%s: WriteMarkdown\n%q\nMarkdown\n%q", name, md.String(), res.Markdown())
+		}
+		if html.String() != res.HTML() {
+			t.Errorf("%s: WriteHTML\n%q\nHTML\n%q", name, html.String(), res.HTML())
+		}
+		b, err := json.Marshal(res)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if js.String() != string(b)+"\n" {
+			t.Errorf("%s: WriteJSON\n%s\njson.Marshal\n%s", name, js.String(), b)
+		}
+	}
+}
+
+// failWriter accepts n writes, then fails.
+type failWriter struct {
+	n, calls int
+}
+
+var errFull = errors.New("disk full")
+
+func (f *failWriter) Write(p []byte) (int, error) {
+	f.calls++
+	if f.calls > f.n {
+		return 0, errFull
+	}
+	return len(p), nil
+}
+
+func TestWriterErrors(t *testing.T) {
+	res := Compute(exampleInputs())
+	for name, write := range map[string]func(*failWriter) error{
+		"text":     func(w *failWriter) error { return res.WriteText(w) },
+		"markdown": func(w *failWriter) error { return res.WriteMarkdown(w) },
+		"html":     func(w *failWriter) error { return res.WriteHTML(w) },
+		"json":     func(w *failWriter) error { return res.WriteJSON(w) },
+	} {
+		w := &failWriter{n: 0}
+		if err := write(w); !errors.Is(err, errFull) {
+			t.Errorf("%s: error %v, want %v", name, err, errFull)
+		}
+		if w.calls != 1 {
+			t.Errorf("%s: %d writes after the first failed", name, w.calls-1)
+		}
+	}
+}
+
+func TestTEYSpread(t *testing.T) {
+	in := exampleInputs()
+	in.NatlTaxExempt = 3.9
+	res := Compute(in)
+	natl, _ := res.Line(NatlTaxExemptKind)
+	state, _ := res.Line(StateTaxExemptKind)
+	// the national muni beats the 5% benchmark, the state muni trails it
+	if !near(natl.TEYSpreadBps, (natl.TEY-5)*100) || natl.TEYSpreadBps <= 0 {
+		t.Errorf("natl spread %v bps, want (%v - 5) * 100 > 0", natl.TEYSpreadBps, natl.TEY)
+	}
+	if !near(state.TEYSpreadBps, (state.TEY-5)*100) || state.TEYSpreadBps >= 0 {
+		t.Errorf("state spread %v bps, want (%v - 5) * 100 < 0", state.TEYSpreadBps, state.TEY)
+	}
+
+	if md := res.Markdown(); strings.Contains(md, "TEY spread") || strings.Contains(md, "bps") {
+		t.Errorf("spread column without ShowSpread:\n%s", md)
+	}
+	in.Format.ShowSpread = true
+	res = Compute(in)
+	for name, out := range map[string]string{"markdown": res.Markdown(), "html": res.HTML()} {
+		for _, want := range []string{"TEY spread", "+13 bps", "-7 bps", "+0 bps"} {
+			if !strings.Contains(out, want) {
+				t.Errorf("%s has no %q:\n%s", name, want, out)
+			}
+		}
+	}
+
+	var csvOut strings.Builder
+	if err := WriteResultsCSV(&csvOut, []Inputs{in}, []Result{res}); err != nil {
+		t.Fatal(err)
+	}
+	rows, err := csv.NewReader(strings.NewReader(csvOut.String())).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	col := slices.Index(rows[0], "natl_tey_spread_bps")
+	if col < 0 {
+		t.Fatalf("no natl_tey_spread_bps column in %q", rows[0])
+	}
+	if got, _ := strconv.ParseFloat(rows[1][col], 64); !near(got, natl.TEYSpreadBps) {
+		t.Errorf("CSV natl spread %q, want %v", rows[1][col], natl.TEYSpreadBps)
+	}
+}
@@ -0,0 +1,63 @@
+package main
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"unicode"
+)
+
+// requestIDHeader carries the request ID in and back out.
+const requestIDHeader = "X-Request-ID"
+
+type requestIDKey struct{}
+
+// withRequestID gives every request an ID: the caller's X-Request-ID if it's
+// usable, a random one otherwise. It's echoed back in the response header and
+// available to handlers through requestID.
+func withRequestID(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := r.Header.Get(requestIDHeader)
+		if !validRequestID(id) {
+			id = newRequestID()
+		}
+		w.Header().Set(requestIDHeader, id)
+		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), requestIDKey{}, id)))
+	})
+}
+
+// requestID is the ID withRequestID gave ctx's request, or "".
+func requestID(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey{}).(string)
+	return id
+}
+
+// validRequestID keeps caller IDs short and printable, since they end up in
+// logs and headers.
+func validRequestID(id string) bool {
+	if id == "" || len(id) > 128 {
+		return false
+	}
+	for _, c := range id {
+		if c > unicode.MaxASCII || !unicode.IsPrint(c) {
+			return false
+		}
+	}
+	return true
+}
+
+func newRequestID() string {
+	var b [16]byte
+	rand.Read(b[:])
+	return hex.EncodeToString(b[:])
+}
+
+// httpError is http.Error with the request ID on the end of the message.
+func httpError(w http.ResponseWriter, r *http.Request, msg string, code int) {
+	if id := requestID(r.Context()); id != "" {
+		msg = fmt.Sprintf("%s (request %s)", msg, id)
+	}
+	http.Error(w, msg, code)
+}
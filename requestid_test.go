@@ -0,0 +1,75 @@
+package main
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"log/slog"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestRequestID(t *testing.T) {
+	var logs bytes.Buffer
+	cfg := defaultServerConfig
+	cfg.Logger = slog.New(slog.NewJSONHandler(&logs, nil))
+	srv := newServer(cfg)
+	compute := func(body, id string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("POST", "/compute", strings.NewReader(body))
+		if id != "" {
+			req.Header.Set(requestIDHeader, id)
+		}
+		rec := httptest.NewRecorder()
+		srv.ServeHTTP(rec, req)
+		return rec
+	}
+	// loggedIDs is the request_id of every log record so far.
+	loggedIDs := func() []string {
+		var ids []string
+		for _, line := range strings.Split(strings.TrimSpace(logs.String()), "\n") {
+			var rec struct {
+				RequestID string `json:"request_id"`
+			}
+			if err := json.Unmarshal([]byte(line), &rec); err != nil {
+				t.Fatalf("log line %q: %v", line, err)
+			}
+			ids = append(ids, rec.RequestID)
+		}
+		logs.Reset()
+		return ids
+	}
+
+	rec := compute(`{"FedBracket": 24, "Treasury": 4.5}`, "trace-42")
+	if got := rec.Header().Get(requestIDHeader); got != "trace-42" {
+		t.Errorf("echoed ID %q, want trace-42", got)
+	}
+	if ids := loggedIDs(); len(ids) != 1 || ids[0] != "trace-42" {
+		t.Errorf("logged IDs %q, want [trace-42]", ids)
+	}
+
+	var generated []string
+	for _, sent := range []string{"", "bad\nid", strings.Repeat("x", 129)} {
+		rec := compute(`{"FedBracket": 24}`, sent)
+		id := rec.Header().Get(requestIDHeader)
+		if b, err := hex.DecodeString(id); err != nil || len(b) != 16 {
+			t.Errorf("sent %q: generated ID %q isn't 32 hex digits", sent, id)
+		}
+		if ids := loggedIDs(); len(ids) != 1 || ids[0] != id {
+			t.Errorf("sent %q: logged IDs %q, want [%s]", sent, ids, id)
+		}
+		generated = append(generated, id)
+	}
+	if generated[0] == generated[1] || generated[1] == generated[2] {
+		t.Errorf("generated IDs repeat: %q", generated)
+	}
+
+	// error bodies name the ID too
+	rec = compute(`{`, "trace-43")
+	if rec.Code != 400 || !strings.Contains(rec.Body.String(), "(request trace-43)") {
+		t.Errorf("got %d %q, want a 400 naming request trace-43", rec.Code, rec.Body.String())
+	}
+	if ids := loggedIDs(); len(ids) != 1 || ids[0] != "trace-43" {
+		t.Errorf("logged IDs %q, want [trace-43]", ids)
+	}
+}
@@ -0,0 +1,40 @@
+package main
+
+import "strconv"
+
+// ToMap flattens r's numbers into a map keyed by stable names, for templates
+// and generic serializers:
+//
+//	<instrument>_yield, <instrument>_after_tax, <instrument>_tey,
+//	<instrument>_effective_rate, <instrument>_after_fee,
+//	<instrument>_tax_cost, gross_up
+//
+// where <instrument> is e.g. "treasury" or "natl". Second and later lines of
+// the same kind (extra AMT Free funds) get "_2", "_3", ... on the instrument.
+func (r Result) ToMap() map[string]float64 {
+	m := map[string]float64{"gross_up": r.GrossUp}
+	for i, prefix := range lineKeys(r.Lines) {
+		l := r.Lines[i]
+		m[prefix+"_yield"] = l.Yield
+		m[prefix+"_after_tax"] = l.AfterTax
+		m[prefix+"_tey"] = l.TEY
+		m[prefix+"_effective_rate"] = l.EffectiveTaxRate
+		m[prefix+"_after_fee"] = l.AfterTaxAfterFee
+		m[prefix+"_tax_cost"] = l.TaxCostRatio()
+	}
+	return m
+}
+
+// lineKeys gives each line a unique key from its kind.
+func lineKeys(lines []ResultLine) []string {
+	keys := make([]string, len(lines))
+	seen := map[InstrumentKind]int{}
+	for i, l := range lines {
+		seen[l.Kind]++
+		keys[i] = instrumentKeys[l.Kind]
+		if n := seen[l.Kind]; n > 1 {
+			keys[i] += "_" + strconv.Itoa(n)
+		}
+	}
+	return keys
+}
@@ -0,0 +1,35 @@
+package main
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestToMapKeys(t *testing.T) {
+	res := Compute(exampleInputs())
+	m := res.ToMap()
+	var want []string
+	for _, inst := range []string{"fully_taxable", "treasury", "natl", "state", "amt_free"} {
+		for _, f := range []string{"yield", "after_tax", "tey", "effective_rate", "after_fee", "tax_cost"} {
+			want = append(want, inst+"_"+f)
+		}
+	}
+	want = append(want, "gross_up")
+	for _, k := range want {
+		if _, ok := m[k]; !ok {
+			t.Errorf("missing %s", k)
+		}
+	}
+	if len(m) != len(want) {
+		var extra []string
+		for k := range m {
+			if !slices.Contains(want, k) {
+				extra = append(extra, k)
+			}
+		}
+		t.Errorf("unexpected keys %v", extra)
+	}
+	if m["treasury_after_tax"] != res.TreasuryAfterTax || m["natl_tey"] != res.NatlTEY || m["gross_up"] != res.GrossUp {
+		t.Error("ToMap values don't match the Result")
+	}
+}
@@ -0,0 +1,45 @@
+package main
+
+import "math"
+
+// maxRetentionGridCells bounds RetentionGrid's size.
+const maxRetentionGridCells = 100000
+
+// RetentionGrid is the share of instrument's yield kept after tax (afterTax
+// / pretax) at each federal bracket from fedFrom to fedTo by fedStep (rows)
+// and state bracket from stateFrom to stateTo by stateStep (columns), with
+// no other tax settings: no itemizing, AMT or NIIT. It's the data behind a
+// heatmap of where a taxable bond gets crushed. Brackets must be within
+// 0..100, each From <= To, and steps positive; otherwise, or past
+// maxRetentionGridCells cells, it's nil. A zero-yield instrument's cells are
+// NaN.
+func RetentionGrid(instrument Instrument, fedFrom, fedTo, fedStep, stateFrom, stateTo, stateStep float64) [][]float64 {
+	fedN, ok1 := gridPoints(fedFrom, fedTo, fedStep)
+	stateN, ok2 := gridPoints(stateFrom, stateTo, stateStep)
+	if !ok1 || !ok2 || fedN*stateN > maxRetentionGridCells {
+		return nil
+	}
+	pretax := instrument.EffectiveYield()
+	grid := make([][]float64, fedN)
+	for i := range grid {
+		grid[i] = make([]float64, stateN)
+		for j := range grid[i] {
+			in := Inputs{FedBracket: fedFrom + float64(i)*fedStep, StateBracket: stateFrom + float64(j)*stateStep}
+			grid[i][j] = instrument.AfterTax(in) / pretax
+		}
+	}
+	return grid
+}
+
+// gridPoints is how many steps from from to to take, counting both ends,
+// if that's a sensible bracket range.
+func gridPoints(from, to, step float64) (int, bool) {
+	if !(from >= 0 && to <= 100 && from <= to && step > 0) {
+		return 0, false
+	}
+	n := (to-from)/step + 1
+	if n > maxRetentionGridCells {
+		return 0, false
+	}
+	return int(math.Floor(n + 1e-9)), true
+}
@@ -0,0 +1,55 @@
+package main
+
+import (
+	"math"
+	"testing"
+)
+
+func TestRetentionGrid(t *testing.T) {
+	bond := Instrument{Kind: FullyTaxableKind, Yield: 5, FedTaxable: true, StateTaxable: true}
+	grid := RetentionGrid(bond, 0, 37, 1, 0, 13.3, 0.1)
+	if len(grid) != 38 {
+		t.Fatalf("%d rows, want 38 federal brackets", len(grid))
+	}
+	for i, row := range grid {
+		if len(row) != 134 {
+			t.Fatalf("row %d has %d cells, want 134 state brackets", i, len(row))
+		}
+	}
+	if grid[0][0] != 1 {
+		t.Errorf("0%%/0%% keeps %v, want 1", grid[0][0])
+	}
+	if got, want := grid[37][133], 1-0.37-0.133; !near(got, want) {
+		t.Errorf("37%%/13.3%% keeps %v, want %v", got, want)
+	}
+	if got := grid[24][50]; !near(got, 1-0.24-0.05) {
+		t.Errorf("24%%/5%% keeps %v, want 0.71", got)
+	}
+
+	// a state muni keeps everything at every bracket
+	muni := Instrument{Kind: StateTaxExemptKind, Yield: 3}
+	for _, row := range RetentionGrid(muni, 10, 30, 10, 0, 10, 5) {
+		for _, v := range row {
+			if v != 1 {
+				t.Errorf("muni keeps %v", v)
+			}
+		}
+	}
+	if g := RetentionGrid(Instrument{Kind: FullyTaxableKind, FedTaxable: true}, 0, 10, 5, 0, 0, 1); len(g) != 3 || len(g[0]) != 1 || !math.IsNaN(g[0][0]) {
+		t.Errorf("zero-yield grid %v, want 3x1 of NaN", g)
+	}
+
+	for _, bad := range [][6]float64{
+		{0, 37, 0, 0, 10, 1},         // zero step
+		{0, 37, 1, 0, 10, -1},        // negative step
+		{37, 10, 1, 0, 10, 1},        // from past to
+		{-5, 10, 1, 0, 10, 1},        // below 0%
+		{0, 37, 1, 0, 150, 1},        // above 100%
+		{0, 100, 0.01, 0, 100, 0.01}, // too many cells
+		{math.NaN(), 10, 1, 0, 10, 1},
+	} {
+		if g := RetentionGrid(bond, bad[0], bad[1], bad[2], bad[3], bad[4], bad[5]); g != nil {
+			t.Errorf("%v: %d rows, want nil", bad, len(g))
+		}
+	}
+}
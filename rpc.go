@@ -0,0 +1,174 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// JSON-RPC 2.0 error codes. The -32000s are ours.
+const (
+	rpcParseError     = -32700
+	rpcInvalidRequest = -32600
+	rpcMethodNotFound = -32601
+	rpcInvalidParams  = -32602
+	rpcInternalError  = -32603
+	rpcTimeout        = -32000
+)
+
+type rpcRequest struct {
+	JSONRPC string          `json:"jsonrpc"`
+	Method  string          `json:"method"`
+	Params  json.RawMessage `json:"params"`
+	ID      json.RawMessage `json:"id"` // absent for notifications
+}
+
+type rpcResponse struct {
+	JSONRPC string          `json:"jsonrpc"`
+	Result  json.RawMessage `json:"result,omitempty"`
+	Error   *rpcError       `json:"error,omitempty"`
+	ID      json.RawMessage `json:"id"`
+}
+
+type rpcError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+	Data    any    `json:"data,omitempty"`
+}
+
+func (e *rpcError) Error() string { return e.Message }
+
+// handleRPC serves JSON-RPC 2.0 on POST /rpc, single calls or batches:
+//
+//	compute       params: Inputs         result: Result
+//	computeBatch  params: [Inputs, ...]  result: [Result, ...]
+//
+// Inputs go through Validate, and a failure is an invalid-params error. A
+// batch of only notifications gets 204 and no body.
+func (cfg serverConfig) handleRPC(w http.ResponseWriter, r *http.Request) {
+	log := cfg.logger(r)
+	r.Body = http.MaxBytesReader(w, r.Body, cfg.MaxUploadBytes)
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		writeJSON(w, r, rpcResponse{JSONRPC: "2.0", Error: &rpcError{Code: rpcInvalidRequest, Message: err.Error()}, ID: json.RawMessage("null")})
+		return
+	}
+	ctx := r.Context()
+	if cfg.RequestTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, cfg.RequestTimeout)
+		defer cancel()
+	}
+
+	body = bytes.TrimSpace(body)
+	if len(body) > 0 && body[0] == '[' {
+		var calls []json.RawMessage
+		if err := json.Unmarshal(body, &calls); err != nil {
+			writeJSON(w, r, rpcFailure(nil, &rpcError{Code: rpcParseError, Message: err.Error()}))
+			return
+		}
+		if len(calls) == 0 {
+			writeJSON(w, r, rpcFailure(nil, &rpcError{Code: rpcInvalidRequest, Message: "empty batch"}))
+			return
+		}
+		var out []rpcResponse
+		for _, c := range calls {
+			if resp, ok := cfg.rpcCall(ctx, c); ok {
+				out = append(out, resp)
+			}
+		}
+		log.Info("rpc batch", "calls", len(calls))
+		if len(out) == 0 {
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		writeJSON(w, r, out)
+		return
+	}
+
+	resp, ok := cfg.rpcCall(ctx, body)
+	if !ok {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+	if resp.Error != nil {
+		log.Info("rpc: error", "code", resp.Error.Code, "err", resp.Error.Message)
+	}
+	writeJSON(w, r, resp)
+}
+
+// rpcCall runs one call. ok is false for a notification, which gets no
+// response.
+func (cfg serverConfig) rpcCall(ctx context.Context, raw json.RawMessage) (resp rpcResponse, ok bool) {
+	var req rpcRequest
+	if err := json.Unmarshal(raw, &req); err != nil {
+		var syntax *json.SyntaxError
+		if errors.As(err, &syntax) {
+			return rpcFailure(nil, &rpcError{Code: rpcParseError, Message: err.Error()}), true
+		}
+		return rpcFailure(nil, &rpcError{Code: rpcInvalidRequest, Message: err.Error()}), true
+	}
+	if req.JSONRPC != "2.0" || req.Method == "" {
+		return rpcFailure(req.ID, &rpcError{Code: rpcInvalidRequest, Message: `want "jsonrpc": "2.0" and a method`}), true
+	}
+	result, err := rpcDispatch(ctx, req.Method, req.Params)
+	if req.ID == nil {
+		return rpcResponse{}, false
+	}
+	var b []byte
+	if err == nil {
+		// encoded here so a bad result fails only its own call
+		b, err = json.Marshal(result)
+	}
+	if err != nil {
+		var rerr *rpcError
+		if !errors.As(err, &rerr) {
+			rerr = &rpcError{Code: rpcInternalError, Message: err.Error()}
+		}
+		return rpcFailure(req.ID, rerr), true
+	}
+	return rpcResponse{JSONRPC: "2.0", Result: b, ID: req.ID}, true
+}
+
+func rpcDispatch(ctx context.Context, method string, params json.RawMessage) (any, error) {
+	switch method {
+	case "compute":
+		var in Inputs
+		if err := json.Unmarshal(params, &in); err != nil {
+			return nil, &rpcError{Code: rpcInvalidParams, Message: "params: " + err.Error()}
+		}
+		res, err := ComputeChecked(in)
+		if err != nil {
+			return nil, &rpcError{Code: rpcInvalidParams, Message: err.Error()}
+		}
+		return res, nil
+	case "computeBatch":
+		var inputs []Inputs
+		if err := json.Unmarshal(params, &inputs); err != nil {
+			return nil, &rpcError{Code: rpcInvalidParams, Message: "params: " + err.Error()}
+		}
+		for i, in := range inputs {
+			if err := in.Validate(); err != nil {
+				return nil, &rpcError{Code: rpcInvalidParams, Message: fmt.Sprintf("params[%d]: %v", i, err), Data: map[string]int{"index": i}}
+			}
+		}
+		results, err := ComputeBatchContext(ctx, inputs)
+		if err != nil {
+			return nil, &rpcError{Code: rpcTimeout, Message: "batch " + err.Error()}
+		}
+		return results, nil
+	default:
+		return nil, &rpcError{Code: rpcMethodNotFound, Message: fmt.Sprintf("no method %q", method)}
+	}
+}
+
+func rpcFailure(id json.RawMessage, err *rpcError) rpcResponse {
+	if id == nil {
+		id = json.RawMessage("null")
+	}
+	return rpcResponse{JSONRPC: "2.0", Error: err, ID: id}
+}
@@ -0,0 +1,113 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// rpcPost sends body to /rpc and returns the status and raw response.
+func rpcPost(t *testing.T, body string) (int, string) {
+	t.Helper()
+	rec := httptest.NewRecorder()
+	newServer(defaultServerConfig).ServeHTTP(rec, httptest.NewRequest("POST", "/rpc", strings.NewReader(body)))
+	return rec.Code, rec.Body.String()
+}
+
+func TestRPCCompute(t *testing.T) {
+	code, body := rpcPost(t, `{"jsonrpc": "2.0", "method": "compute", "params": {"FedBracket": 24, "StateBracket": 5, "FullyTaxable": 5, "NatlTaxExempt": 3.5}, "id": 7}`)
+	if code != 200 {
+		t.Fatalf("status %d: %s", code, body)
+	}
+	var resp struct {
+		JSONRPC string
+		Result  Result
+		Error   *rpcError
+		ID      int
+	}
+	if err := json.Unmarshal([]byte(body), &resp); err != nil {
+		t.Fatal(err)
+	}
+	want := Compute(Inputs{FedBracket: 24, StateBracket: 5, FullyTaxable: 5, NatlTaxExempt: 3.5})
+	if resp.JSONRPC != "2.0" || resp.ID != 7 || resp.Error != nil || !near(resp.Result.NatlAfterTax, want.NatlAfterTax) {
+		t.Errorf("response %s", body)
+	}
+
+	for _, tt := range []struct {
+		name, body string
+		code       int
+	}{
+		{"parse error", `{"jsonrpc": "2.0", `, rpcParseError},
+		{"no version", `{"method": "compute", "id": 1}`, rpcInvalidRequest},
+		{"unknown method", `{"jsonrpc": "2.0", "method": "nope", "id": 1}`, rpcMethodNotFound},
+		{"bad params", `{"jsonrpc": "2.0", "method": "compute", "params": [1], "id": 1}`, rpcInvalidParams},
+		{"invalid inputs", `{"jsonrpc": "2.0", "method": "compute", "params": {"AMT": true, "AMTBracketIndex": 7}, "id": 1}`, rpcInvalidParams},
+		{"empty batch", `[]`, rpcInvalidRequest},
+	} {
+		code, body := rpcPost(t, tt.body)
+		var resp rpcResponse
+		if err := json.Unmarshal([]byte(body), &resp); err != nil || code != 200 {
+			t.Fatalf("%s: %d %q: %v", tt.name, code, body, err)
+		}
+		if resp.Error == nil || resp.Error.Code != tt.code || resp.Result != nil {
+			t.Errorf("%s: %s, want error %d", tt.name, body, tt.code)
+		}
+	}
+
+	if code, body := rpcPost(t, `{"jsonrpc": "2.0", "method": "compute", "params": {}}`); code != 204 || body != "" {
+		t.Errorf("notification: %d %q, want 204 and no body", code, body)
+	}
+}
+
+func TestRPCBatch(t *testing.T) {
+	code, body := rpcPost(t, `[
+		{"jsonrpc": "2.0", "method": "compute", "params": {"FedBracket": 24, "Treasury": 4.5}, "id": "a"},
+		{"jsonrpc": "2.0", "method": "compute", "params": {"FedBracket": 24}},
+		{"jsonrpc": "2.0", "method": "computeBatch", "params": [{"FedBracket": 24, "Treasury": 4.5}, {"FedBracket": 32, "Treasury": 4.5}], "id": 2},
+		{"jsonrpc": "2.0", "method": "computeBatch", "params": [{}, {"AMT": true, "AMTBracketIndex": -1}], "id": 3},
+		{"jsonrpc": "2.0", "method": "nope", "id": 4}
+	]`)
+	if code != 200 {
+		t.Fatalf("status %d: %s", code, body)
+	}
+	var resps []struct {
+		Result json.RawMessage
+		Error  *struct {
+			Code int
+			Data map[string]int
+		}
+		ID json.RawMessage
+	}
+	if err := json.Unmarshal([]byte(body), &resps); err != nil {
+		t.Fatal(err)
+	}
+	// the notification gets no response
+	if len(resps) != 4 {
+		t.Fatalf("%d responses, want 4: %s", len(resps), body)
+	}
+	for i, id := range []string{`"a"`, "2", "3", "4"} {
+		if string(resps[i].ID) != id {
+			t.Errorf("response %d has id %s, want %s", i, resps[i].ID, id)
+		}
+	}
+
+	var one Result
+	if err := json.Unmarshal(resps[0].Result, &one); err != nil || !near(one.TreasuryAfterTax, 3.42) {
+		t.Errorf("compute result %s: %v", resps[0].Result, err)
+	}
+	var many []Result
+	if err := json.Unmarshal(resps[1].Result, &many); err != nil || len(many) != 2 || !near(many[1].TreasuryAfterTax, 4.5*0.68) {
+		t.Errorf("computeBatch result %s: %v", resps[1].Result, err)
+	}
+	if e := resps[2].Error; e == nil || e.Code != rpcInvalidParams || e.Data["index"] != 1 {
+		t.Errorf("invalid batch entry: %+v, want invalid params at index 1", e)
+	}
+	if e := resps[3].Error; e == nil || e.Code != rpcMethodNotFound {
+		t.Errorf("unknown method: %+v", e)
+	}
+
+	if code, body := rpcPost(t, `[{"jsonrpc": "2.0", "method": "compute", "params": {}}]`); code != 204 || body != "" {
+		t.Errorf("batch of notifications: %d %q, want 204 and no body", code, body)
+	}
+}
@@ -0,0 +1,66 @@
+package main
+
+// InstrumentRule is an instrument's tax treatment: the after-tax yield of a
+// pretax yield under in's settings. Set Instrument.Rule to one for an
+// instrument no built-in covers, and pass it to ComputeInstruments.
+type InstrumentRule interface {
+	AfterTax(yield float64, in Inputs) float64
+}
+
+// InstrumentRuleFunc adapts a plain function to InstrumentRule.
+type InstrumentRuleFunc func(yield float64, in Inputs) float64
+
+func (f InstrumentRuleFunc) AfterTax(yield float64, in Inputs) float64 {
+	return f(yield, in)
+}
+
+// builtinRule marks the package's own rules, which stand in for a built-in
+// instrument's treatment rather than adding a custom one.
+type builtinRule interface {
+	builtinRule()
+}
+
+// quotedRule is for yields already quoted after tax (the AMT Free
+// convention from the original JS).
+type quotedRule struct{}
+
+func (quotedRule) AfterTax(yield float64, in Inputs) float64 { return yield }
+
+// taxRule is the built-in treatment every standard instrument uses.
+type taxRule struct {
+	FedTaxable      bool
+	StateTaxable    bool
+	AMTPct          float64
+	InStateFraction float64
+	AMTRate         float64 // replaces the AMT rate on AMTPct, if set
+	Dividend        bool    // under UKTax
+}
+
+func (r taxRule) AfterTax(yield float64, in Inputs) float64 {
+	if in.TaxSystem == UKTax {
+		return calcAfterTaxYieldUK(yield, r.Dividend, in)
+	}
+	return r.breakdown(yield, in).AfterTax
+}
+
+// breakdown is taxBreakdown, with state tax only on the part that isn't
+// InStateFraction.
+func (r taxRule) breakdown(yield float64, in Inputs) Breakdown {
+	rate := amtRate(in)
+	if !r.FedTaxable && r.AMTRate > 0 {
+		rate = r.AMTRate
+	}
+	b := taxBreakdownAt(yield, r.FedTaxable, r.StateTaxable, r.AMTPct, rate, in)
+	if r.StateTaxable && r.InStateFraction != 0 && in.UseDecimal {
+		return exactBreakdown(b, r.FedTaxable, r.StateTaxable, 1-r.InStateFraction, in)
+	}
+	if r.StateTaxable && r.InStateFraction != 0 {
+		share := 1 - r.InStateFraction
+		b.StateTax *= share
+		b.DeductionCredit *= share
+		b.StateDeduction *= share
+		b.TotalTax = b.FedTax + b.StateTax - b.DeductionCredit - b.StateDeduction + b.NIIT
+		b.AfterTax = b.Yield * (1.0 - b.TotalTax/100.0)
+	}
+	return b
+}
@@ -0,0 +1,59 @@
+package main
+
+import "testing"
+
+func TestCustomInstrumentRule(t *testing.T) {
+	in := exampleInputs()
+	flat15 := InstrumentRuleFunc(func(yield float64, in Inputs) float64 { return yield * 0.85 })
+	base := Compute(in)
+	// CorporateKind, and no Kind at all: the zero value is FullyTaxableKind,
+	// which must not make the custom line the benchmark
+	for _, kind := range []InstrumentKind{CorporateKind, FullyTaxableKind} {
+		insts := append(in.Instruments(), Instrument{Kind: kind, Name: "Private Credit", Yield: 6, Rule: flat15})
+		res := ComputeInstruments(insts, in)
+
+		var line ResultLine
+		for _, l := range res.Lines {
+			if l.Label == "Private Credit" {
+				line = l
+			}
+		}
+		if line.Label == "" {
+			t.Fatalf("%v: no Private Credit line in %+v", kind, res.Lines)
+		}
+		if !near(line.AfterTax, 5.1) || !near(line.TEY, 5.1*res.GrossUp) || !near(line.EffectiveTaxRate, 15) {
+			t.Errorf("%v: custom line after tax %v, TEY %v, tax rate %v; want 5.1, %v, 15",
+				kind, line.AfterTax, line.TEY, line.EffectiveTaxRate, 5.1*res.GrossUp)
+		}
+		// the built-ins are untouched
+		if res.FullyTaxableAfterTax != base.FullyTaxableAfterTax || res.FullyTaxableTEY != base.FullyTaxableTEY ||
+			res.NatlAfterTax != base.NatlAfterTax {
+			t.Errorf("%v: adding a custom line changed the built-in ones", kind)
+		}
+	}
+}
+
+func TestBuiltinsAsRules(t *testing.T) {
+	// Every built-in's treatment is an InstrumentRule; wrapped in a
+	// plain func, they compute the same Result.
+	for _, in := range []Inputs{exampleInputs(), {FullyTaxable: 5, NatlTaxExempt: 3.5, FedBracket: 35, StateBracket: 9.3, AMT: true, NatlAmTPct: 20}} {
+		var insts []Instrument
+		for _, inst := range in.Instruments() {
+			if !in.reports(inst) {
+				continue
+			}
+			inst.Rule = InstrumentRuleFunc(inst.rule().AfterTax)
+			inst.AfterTaxQuoted = false
+			insts = append(insts, inst)
+		}
+		got, want := ComputeInstruments(insts, in), Compute(in)
+		if len(got.Lines) != len(want.Lines) {
+			t.Fatalf("%d lines, want %d", len(got.Lines), len(want.Lines))
+		}
+		for i, l := range want.Lines {
+			if !near(got.Lines[i].AfterTax, l.AfterTax) || !near(got.Lines[i].TEY, l.TEY) {
+				t.Errorf("%s as a rule: %v/%v, want %v/%v", l.Label, got.Lines[i].AfterTax, got.Lines[i].TEY, l.AfterTax, l.TEY)
+			}
+		}
+	}
+}
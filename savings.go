@@ -0,0 +1,65 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+)
+
+// vsSavings is a savings account's after-tax APY (fully taxable, like any
+// bank interest) and the best of munis' Result lines after tax under in.
+// ok is false with no munis to compare.
+func vsSavings(apy float64, munis []Instrument, in Inputs) (savings float64, best ResultLine, ok bool) {
+	savings = calcAfterTaxYield(apy, true, true, 0, in)
+	ranked := ComputeInstruments(munis, in).RankByAfterTax()
+	if len(ranked) == 0 {
+		return savings, ResultLine{}, false
+	}
+	return savings, ranked[0], true
+}
+
+// savingsVerdict is the one-line answer to "munis or the HYSA?".
+func savingsVerdict(savings float64, best ResultLine) string {
+	bps := (best.AfterTax - savings) * 100
+	switch {
+	case bps > 0:
+		return fmt.Sprintf("%s beats savings by %.0f bps after tax.", best.Label, bps)
+	case bps < 0:
+		return fmt.Sprintf("Savings beats %s by %.0f bps after tax; keep the cash.", best.Label, -bps)
+	default:
+		return fmt.Sprintf("Savings and %s come out even after tax.", best.Label)
+	}
+}
+
+func runVsSavings(args []string, stdout io.Writer) error {
+	var in Inputs
+	fs := flag.NewFlagSet("vs-savings", flag.ContinueOnError)
+	var apy, natl, inState, amtFree float64
+	percentVar(fs, &apy, "apy", 4.5, "savings account APY (%)")
+	percentVar(fs, &natl, "natl", 0, "national muni yield (%)")
+	percentVar(fs, &inState, "in-state", 0, "in-state muni yield (%)")
+	percentVar(fs, &amtFree, "amt-free", 0, "AMT-free fund yield (%), already after tax")
+	profile := taxFlags(fs, &in)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if done, err := profile.resolve(fs, &in, stdout); done || err != nil {
+		return err
+	}
+	in.NatlTaxExempt, in.StateTaxExempt, in.AMTFree = natl, inState, amtFree
+
+	var munis []Instrument
+	for _, k := range []InstrumentKind{NatlTaxExemptKind, StateTaxExemptKind, AMTFreeKind} {
+		if inst := in.Instrument(k); inst.Yield != 0 {
+			munis = append(munis, inst)
+		}
+	}
+	savings, best, ok := vsSavings(apy, munis, in)
+	if !ok {
+		return fmt.Errorf("vs-savings needs a muni yield: -natl, -in-state or -amt-free")
+	}
+	fmt.Fprintf(stdout, "%-18s %6.3f%% after tax\n", "Savings:", savings)
+	fmt.Fprintf(stdout, "%-18s %6.3f%% after tax\n", best.Label+":", best.AfterTax)
+	fmt.Fprintln(stdout, savingsVerdict(savings, best))
+	return nil
+}
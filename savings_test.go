@@ -0,0 +1,48 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRunVsSavings(t *testing.T) {
+	for _, tt := range []struct {
+		name string
+		args []string
+		want string
+	}{
+		// 4.5 * (1 - 0.463) against 3.5 * (1 - 0.093)
+		{"high bracket", []string{"-apy", "4.5", "-natl", "3.5", "-fed", "37", "-state", "9.3"},
+			"Savings:            2.417% after tax\nNat'l Tax-Exempt:   3.175% after tax\nNat'l Tax-Exempt beats savings by 76 bps after tax.\n"},
+		// 4.5 * (1 - 0.17) against the better of 3.5 * (1 - 0.05) and 3.2
+		{"low bracket", []string{"-apy", "4.5%", "-natl", "3.5", "-in-state", "3.2", "-fed", "12", "-state", "5"},
+			"Savings:            3.735% after tax\nNat'l Tax-Exempt:   3.325% after tax\nSavings beats Nat'l Tax-Exempt by 41 bps after tax; keep the cash.\n"},
+	} {
+		var out strings.Builder
+		if err := runCommand("vs-savings", tt.args, &out); err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		if out.String() != tt.want {
+			t.Errorf("%s: got\n%s\nwant\n%s", tt.name, out.String(), tt.want)
+		}
+	}
+	if err := runCommand("vs-savings", []string{"-fed", "12"}, new(strings.Builder)); err == nil || !strings.Contains(err.Error(), "muni yield") {
+		t.Errorf("no munis: error %v", err)
+	}
+}
+
+func TestSavingsVerdict(t *testing.T) {
+	in := Inputs{FedBracket: 24, StateBracket: 5}
+	muni := in.Instrument(NatlTaxExemptKind)
+	muni.Yield = 3.5
+	savings, best, ok := vsSavings(3.5/0.71*0.95, []Instrument{muni}, in)
+	if !ok || !near(savings, best.AfterTax) {
+		t.Fatalf("savings %v, best %+v, %v: want them even", savings, best, ok)
+	}
+	if got := savingsVerdict(best.AfterTax, best); got != "Savings and Nat'l Tax-Exempt come out even after tax." {
+		t.Errorf("even: %q", got)
+	}
+	if _, _, ok := vsSavings(4.5, nil, in); ok {
+		t.Error("ok with no munis")
+	}
+}
@@ -0,0 +1,60 @@
+package main
+
+import (
+	"math"
+	"sort"
+)
+
+// SortScenarios sorts results in place by by(result), stably, so ties keep
+// their order. NaNs go last either way. It returns the permutation applied:
+// order[i] is the original index of what's now results[i], for reordering a
+// parallel []Inputs with PermuteInputs.
+func SortScenarios(results []Result, by func(Result) float64, ascending bool) (order []int) {
+	order = make([]int, len(results))
+	keys := make([]float64, len(results))
+	for i, r := range results {
+		order[i] = i
+		keys[i] = by(r)
+	}
+	sort.SliceStable(order, func(a, b int) bool {
+		ka, kb := keys[order[a]], keys[order[b]]
+		switch {
+		case math.IsNaN(ka):
+			return false
+		case math.IsNaN(kb):
+			return true
+		case ascending:
+			return ka < kb
+		default:
+			return ka > kb
+		}
+	})
+	sorted := make([]Result, len(results))
+	for i, j := range order {
+		sorted[i] = results[j]
+	}
+	copy(results, sorted)
+	return order
+}
+
+// PermuteInputs reorders inputs to match results after SortScenarios.
+func PermuteInputs(inputs []Inputs, order []int) []Inputs {
+	out := make([]Inputs, len(order))
+	for i, j := range order {
+		out[i] = inputs[j]
+	}
+	return out
+}
+
+// Extractors for SortScenarios.
+
+func ByFullyTaxableAfterTax(r Result) float64 { return r.FullyTaxableAfterTax }
+func ByTreasuryAfterTax(r Result) float64     { return r.TreasuryAfterTax }
+func ByNatlAfterTax(r Result) float64         { return r.NatlAfterTax }
+func ByStateAfterTax(r Result) float64        { return r.StateAfterTax }
+func ByAMTFreeAfterTax(r Result) float64      { return r.AMTFreeAfterTax }
+func ByGrossUp(r Result) float64              { return r.GrossUp }
+
+// ByMuniAdvantage is how much the national muni beats the fully taxable
+// bond after tax, for "who benefits most from munis" reports.
+func ByMuniAdvantage(r Result) float64 { return r.NatlAfterTax - r.FullyTaxableAfterTax }
@@ -0,0 +1,59 @@
+package main
+
+import (
+	"math"
+	"slices"
+	"testing"
+)
+
+func TestSortScenarios(t *testing.T) {
+	// brackets for five clients; two pairs tie
+	brackets := []float64{24, 35, 12, 35, 24}
+	var inputs []Inputs
+	var results []Result
+	for _, b := range brackets {
+		in := Inputs{FullyTaxable: 5, NatlTaxExempt: 3.5, FedBracket: b}
+		inputs = append(inputs, in)
+		results = append(results, Compute(in))
+	}
+
+	sorted := slices.Clone(results)
+	order := SortScenarios(sorted, ByMuniAdvantage, false)
+	if want := []int{1, 3, 0, 4, 2}; !slices.Equal(order, want) {
+		t.Fatalf("descending order %v, want %v (ties in input order)", order, want)
+	}
+	for i, j := range order {
+		if sorted[i].FullyTaxableAfterTax != results[j].FullyTaxableAfterTax {
+			t.Errorf("results[%d] isn't original result %d", i, j)
+		}
+	}
+	for i, in := range PermuteInputs(inputs, order) {
+		if in.FedBracket != brackets[order[i]] {
+			t.Errorf("inputs[%d] bracket %v, want %v", i, in.FedBracket, brackets[order[i]])
+		}
+	}
+
+	sorted = slices.Clone(results)
+	if order := SortScenarios(sorted, ByNatlAfterTax, true); !slices.Equal(order, []int{0, 1, 2, 3, 4}) {
+		t.Errorf("all natl yields tie, order %v, want unchanged", order)
+	}
+	sorted = slices.Clone(results)
+	if order := SortScenarios(sorted, ByFullyTaxableAfterTax, true); !slices.Equal(order, []int{1, 3, 0, 4, 2}) {
+		t.Errorf("ascending after tax order %v, want [1 3 0 4 2]", order)
+	}
+
+	// NaNs go last either way
+	nan := func(r Result) float64 {
+		if r.GrossUp > 1.4 {
+			return math.NaN()
+		}
+		return r.GrossUp
+	}
+	for _, asc := range []bool{true, false} {
+		sorted = slices.Clone(results)
+		order := SortScenarios(sorted, nan, asc)
+		if order[3] != 1 || order[4] != 3 {
+			t.Errorf("ascending %v: order %v, want the 35%% clients last", asc, order)
+		}
+	}
+}
@@ -0,0 +1,48 @@
+package schema
+
+// Operation is one endpoint in an OpenAPI document.
+type Operation struct {
+	Method   string // lower case, e.g. "post"
+	Path     string
+	Summary  string
+	Request  string // component name of the request body, or ""
+	Response string // component name of the 200 response
+}
+
+// OpenAPI returns an OpenAPI 3 document for ops, with components holding the
+// named schemas they refer to.
+func OpenAPI(title, version string, components map[string]Schema, ops ...Operation) map[string]any {
+	ref := func(name string) Schema {
+		return Schema{"$ref": "#/components/schemas/" + name}
+	}
+	paths := map[string]any{}
+	for _, op := range ops {
+		o := map[string]any{
+			"summary": op.Summary,
+			"responses": map[string]any{
+				"200": map[string]any{
+					"description": "OK",
+					"content":     map[string]any{"application/json": map[string]any{"schema": ref(op.Response)}},
+				},
+			},
+		}
+		if op.Request != "" {
+			o["requestBody"] = map[string]any{
+				"required": true,
+				"content":  map[string]any{"application/json": map[string]any{"schema": ref(op.Request)}},
+			}
+		}
+		item, _ := paths[op.Path].(map[string]any)
+		if item == nil {
+			item = map[string]any{}
+			paths[op.Path] = item
+		}
+		item[op.Method] = o
+	}
+	return map[string]any{
+		"openapi":    "3.1.0",
+		"info":       map[string]any{"title": title, "version": version},
+		"paths":      paths,
+		"components": map[string]any{"schemas": components},
+	}
+}
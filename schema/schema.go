@@ -0,0 +1,90 @@
+// Package schema derives JSON Schema and OpenAPI documents from Go types, so
+// API clients can generate typed SDKs.
+package schema
+
+import (
+	"encoding"
+	"reflect"
+)
+
+// Schema is a JSON Schema node, ready for encoding/json.
+type Schema map[string]any
+
+var textMarshaler = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+
+// Generate returns the schema for the type of v, following encoding/json's
+// rules: exported fields by name, TextMarshalers as strings, maps as objects,
+// and pointers, slices and maps also null.
+func Generate(v any) Schema {
+	return forType(reflect.TypeOf(v))
+}
+
+func forType(t reflect.Type) Schema {
+	if t.Implements(textMarshaler) {
+		return Schema{"type": "string"}
+	}
+	switch t.Kind() {
+	case reflect.Pointer:
+		s := forType(t.Elem())
+		s.nullable()
+		return s
+	case reflect.Bool:
+		return Schema{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return Schema{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return Schema{"type": "number"}
+	case reflect.String:
+		return Schema{"type": "string"}
+	case reflect.Array:
+		return Schema{"type": "array", "items": forType(t.Elem())}
+	case reflect.Slice:
+		// a nil slice encodes as null
+		return Schema{"type": []string{"array", "null"}, "items": forType(t.Elem())}
+	case reflect.Map:
+		return Schema{"type": []string{"object", "null"}, "additionalProperties": forType(t.Elem())}
+	case reflect.Struct:
+		props := Schema{}
+		for i := 0; i < t.NumField(); i++ {
+			f := t.Field(i)
+			if !f.IsExported() || f.Tag.Get("json") == "-" {
+				continue
+			}
+			props[f.Name] = forType(f.Type)
+		}
+		return Schema{"type": "object", "properties": props}
+	default:
+		return Schema{}
+	}
+}
+
+// Property returns the schema of the named property, or nil.
+func (s Schema) Property(name string) Schema {
+	props, _ := s["properties"].(Schema)
+	p, _ := props[name].(Schema)
+	return p
+}
+
+// Items returns the element schema of an array schema, or nil.
+func (s Schema) Items() Schema {
+	items, _ := s["items"].(Schema)
+	return items
+}
+
+// Nullable marks the named property as also accepting null, with a
+// description of what null means.
+func (s Schema) Nullable(field, description string) {
+	p := s.Property(field)
+	if p == nil {
+		return
+	}
+	p.nullable()
+	p["description"] = description
+}
+
+func (s Schema) nullable() {
+	if t, ok := s["type"].(string); ok {
+		s["type"] = []string{t, "null"}
+	}
+}
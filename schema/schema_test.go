@@ -0,0 +1,55 @@
+package schema
+
+import (
+	"reflect"
+	"testing"
+)
+
+type sample struct {
+	Name    string
+	Rate    float64
+	Count   int
+	On      bool
+	Tags    []string
+	Weights map[string]float64
+	Next    *sample `json:"-"`
+	hidden  int
+}
+
+func TestGenerate(t *testing.T) {
+	s := Generate(sample{})
+	want := Schema{"type": "object", "properties": Schema{
+		"Name":    Schema{"type": "string"},
+		"Rate":    Schema{"type": "number"},
+		"Count":   Schema{"type": "integer"},
+		"On":      Schema{"type": "boolean"},
+		"Tags":    Schema{"type": []string{"array", "null"}, "items": Schema{"type": "string"}},
+		"Weights": Schema{"type": []string{"object", "null"}, "additionalProperties": Schema{"type": "number"}},
+	}}
+	if !reflect.DeepEqual(s, want) {
+		t.Errorf("got %v\nwant %v", s, want)
+	}
+}
+
+func TestNullable(t *testing.T) {
+	s := Generate(sample{})
+	s.Nullable("Rate", "null means unknown")
+	s.Nullable("Missing", "ignored")
+	rate := s.Property("Rate")
+	if !reflect.DeepEqual(rate["type"], []string{"number", "null"}) || rate["description"] != "null means unknown" {
+		t.Errorf("Rate: %v", rate)
+	}
+	if s.Property("Missing") != nil {
+		t.Error("Nullable added a property")
+	}
+}
+
+func TestOpenAPI(t *testing.T) {
+	doc := OpenAPI("t", "1", map[string]Schema{"S": Generate(sample{})},
+		Operation{Method: "post", Path: "/x", Summary: "x", Request: "S", Response: "S"})
+	op := doc["paths"].(map[string]any)["/x"].(map[string]any)["post"].(map[string]any)
+	body := op["requestBody"].(map[string]any)["content"].(map[string]any)["application/json"].(map[string]any)
+	if ref := body["schema"].(Schema)["$ref"]; ref != "#/components/schemas/S" {
+		t.Errorf("request $ref %v", ref)
+	}
+}
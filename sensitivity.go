@@ -0,0 +1,38 @@
+package main
+
+// sensitivityBump is the bracket change (points) either side of in for the
+// central differences in Sensitivity.
+const sensitivityBump = 0.01
+
+// GradientPoint is how much an after-tax yield moves per 1-point rise in
+// each bracket.
+type GradientPoint struct {
+	Fed   float64 // ∂afterTax/∂FedBracket
+	State float64 // ∂afterTax/∂StateBracket
+}
+
+// Sensitivity is the local gradient of each line's after-tax yield, keyed by
+// line as in Result.ToMap ("natl", "amt_free_2", ...). A fully-taxable line's
+// Fed is about -yield/100 without itemizing. Under AMT, Fed is 0 for every
+// line, since the AMT rate replaces the bracket.
+func Sensitivity(in Inputs) map[string]GradientPoint {
+	partial := func(bump func(*Inputs, float64)) map[string]float64 {
+		up, down := in, in
+		bump(&up, sensitivityBump)
+		bump(&down, -sensitivityBump)
+		hi, lo := Compute(up).Lines, Compute(down).Lines
+		d := map[string]float64{}
+		for i, key := range lineKeys(hi) {
+			d[key] = (hi[i].AfterTax - lo[i].AfterTax) / (2 * sensitivityBump)
+		}
+		return d
+	}
+	fed := partial(func(in *Inputs, h float64) { in.FedBracket += h })
+	state := partial(func(in *Inputs, h float64) { in.StateBracket += h })
+
+	out := map[string]GradientPoint{}
+	for key, d := range fed {
+		out[key] = GradientPoint{Fed: d, State: state[key]}
+	}
+	return out
+}
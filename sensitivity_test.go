@@ -0,0 +1,53 @@
+package main
+
+import (
+	"math"
+	"testing"
+)
+
+func TestSensitivity(t *testing.T) {
+	base := Inputs{FullyTaxable: 5, Treasury: 4.5, NatlTaxExempt: 3.5, StateTaxExempt: 3.2, FedBracket: 24, StateBracket: 5}
+	itemized := base
+	itemized.Itemize = true
+	amt := base
+	amt.AMT = true
+
+	tests := []struct {
+		name string
+		in   Inputs
+		want map[string]GradientPoint
+	}{
+		{"not itemizing", base, map[string]GradientPoint{
+			"fully_taxable": {-0.05, -0.05},
+			"treasury":      {-0.045, 0},
+			"natl":          {0, -0.035},
+			"state":         {0, 0},
+		}},
+		// d/dfed of y(1 - fed - state + fed*state) is -y(1 - state)
+		{"itemizing", itemized, map[string]GradientPoint{
+			"fully_taxable": {-0.05 * 0.95, -0.05 * 0.76},
+			"treasury":      {-0.045, 0},
+			"natl":          {0, -0.035}, // no federal tax to deduct from
+			"state":         {0, 0},
+		}},
+		{"AMT", amt, map[string]GradientPoint{
+			"fully_taxable": {0, -0.05},
+			"treasury":      {0, 0},
+			"natl":          {0, -0.035},
+			"state":         {0, 0},
+		}},
+	}
+	for _, tt := range tests {
+		got := Sensitivity(tt.in)
+		for key, want := range tt.want {
+			g, ok := got[key]
+			if !ok {
+				t.Errorf("%s: no %q gradient", tt.name, key)
+				continue
+			}
+			if math.Abs(g.Fed-want.Fed) > 1e-6 || math.Abs(g.State-want.State) > 1e-6 {
+				t.Errorf("%s: %s gradient %+v, want %+v", tt.name, key, g, want)
+			}
+		}
+	}
+}
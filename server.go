@@ -0,0 +1,266 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/kybouw/taxableyield/schema"
+)
+
+// serverConfig holds the server's tunables.
+type serverConfig struct {
+	MaxUploadBytes int64 // largest body accepted: the CSV on /batch, JSON elsewhere
+
+	ReadTimeout     time.Duration
+	WriteTimeout    time.Duration
+	IdleTimeout     time.Duration
+	RequestTimeout  time.Duration // per-request compute budget; 0 is none
+	ShutdownTimeout time.Duration // how long to drain on shutdown
+
+	// Per-client rate limit: RateLimit requests a second, in bursts of up
+	// to RateBurst. Clients are keyed by RateKeyHeader (an API key) when
+	// it's set and sent with one of RateKeys, by IP otherwise. 0 turns
+	// limiting off.
+	RateLimit     float64
+	RateBurst     int
+	RateKeyHeader string
+	RateKeys      map[string]bool
+
+	Logger *slog.Logger // nil is slog.Default()
+}
+
+var defaultServerConfig = serverConfig{
+	MaxUploadBytes:  1 << 20,
+	ReadTimeout:     10 * time.Second,
+	WriteTimeout:    30 * time.Second,
+	IdleTimeout:     60 * time.Second,
+	RequestTimeout:  20 * time.Second,
+	ShutdownTimeout: 30 * time.Second,
+	RateBurst:       10,
+}
+
+// newServer returns the HTTP API:
+//
+//	POST /compute       Inputs in, Result out
+//	POST /batch         multipart CSV upload ("file") in, results CSV out
+//	POST /rpc           JSON-RPC 2.0: compute and computeBatch (see handleRPC)
+//	POST /chart         Inputs in, PNG (or SVG) bracket sweep out (see handleChart)
+//	GET  /openapi.json  OpenAPI document for /compute
+//
+// Every response carries an X-Request-ID (see withRequestID), and clients
+// over cfg's rate limit get 429.
+func newServer(cfg serverConfig) http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /compute", cfg.handleCompute)
+	mux.HandleFunc("POST /batch", cfg.handleBatch)
+	mux.HandleFunc("POST /rpc", cfg.handleRPC)
+	mux.HandleFunc("POST /chart", cfg.handleChart)
+	mux.HandleFunc("GET /openapi.json", handleOpenAPI)
+	var h http.Handler = mux
+	if cfg.RateLimit > 0 {
+		h = newRateLimiter(cfg.RateLimit, cfg.RateBurst, cfg.RateKeyHeader, cfg.RateKeys).middleware(h)
+	}
+	return withRequestID(h)
+}
+
+// logger is cfg.Logger tagged with r's request ID.
+func (cfg serverConfig) logger(r *http.Request) *slog.Logger {
+	l := cfg.Logger
+	if l == nil {
+		l = slog.Default()
+	}
+	return l.With("request_id", requestID(r.Context()))
+}
+
+func (cfg serverConfig) handleCompute(w http.ResponseWriter, r *http.Request) {
+	log := cfg.logger(r)
+	ctx := r.Context()
+	if cfg.RequestTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, cfg.RequestTimeout)
+		defer cancel()
+	}
+	var in Inputs
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, cfg.MaxUploadBytes)).Decode(&in); err != nil {
+		var tooBig *http.MaxBytesError
+		if errors.As(err, &tooBig) {
+			err = fmt.Errorf("body exceeds %d bytes", tooBig.Limit)
+		}
+		log.Info("compute: bad request", "err", err)
+		httpError(w, r, "bad request: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	start := time.Now()
+	res := Compute(in)
+	switch err := ctx.Err(); {
+	case errors.Is(err, context.DeadlineExceeded):
+		httpError(w, r, "compute timed out", http.StatusGatewayTimeout)
+		return
+	case err != nil:
+		httpError(w, r, "compute canceled", http.StatusServiceUnavailable)
+		return
+	}
+	log.Info("compute", "fingerprint", in.Fingerprint(), "duration", time.Since(start))
+	writeJSON(w, r, res)
+}
+
+func (cfg serverConfig) handleBatch(w http.ResponseWriter, r *http.Request) {
+	r.Body = http.MaxBytesReader(w, r.Body, cfg.MaxUploadBytes)
+	file, _, err := r.FormFile("file")
+	if err != nil {
+		var tooBig *http.MaxBytesError
+		if errors.As(err, &tooBig) {
+			err = fmt.Errorf("upload exceeds %d bytes", tooBig.Limit)
+		}
+		httpError(w, r, "bad request: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	defer file.Close()
+
+	inputs, err := ReadInputsCSV(file, nil)
+	if err != nil {
+		httpError(w, r, "bad request: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	ctx := r.Context()
+	if cfg.RequestTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, cfg.RequestTimeout)
+		defer cancel()
+	}
+	results, err := ComputeBatchContext(ctx, inputs)
+	switch {
+	case errors.Is(err, context.DeadlineExceeded):
+		httpError(w, r, "batch timed out", http.StatusGatewayTimeout)
+		return
+	case err != nil:
+		httpError(w, r, "batch canceled", http.StatusServiceUnavailable)
+		return
+	}
+	var buf bytes.Buffer
+	if err := WriteResultsCSV(&buf, inputs, results); err != nil {
+		httpError(w, r, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "text/csv")
+	w.Header().Set("Content-Disposition", `attachment; filename="results.csv"`)
+	w.Write(buf.Bytes())
+}
+
+func handleOpenAPI(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, r, openAPIDoc())
+}
+
+// writeJSON encodes v before writing anything, so an unencodable value
+// (an unexpected NaN, say) still gets a proper error status.
+func writeJSON(w http.ResponseWriter, r *http.Request, v any) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		httpError(w, r, err.Error(), http.StatusUnprocessableEntity)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(append(b, '\n'))
+}
+
+// inputsSchema is the JSON Schema of Inputs, including the null-for-NaN
+// convention on FullyTaxable.
+func inputsSchema() schema.Schema {
+	s := schema.Generate(Inputs{})
+	s.Nullable("FullyTaxable", "null (or omitted) means unknown; the gross-up falls back to a synthetic 1% yield")
+	return s
+}
+
+func resultSchema() schema.Schema {
+	s := schema.Generate(Result{})
+	s.Nullable("FullyTaxableAfterTax", "null when Inputs.FullyTaxable was null")
+	s.Nullable("FullyTaxableTEY", "null when Inputs.FullyTaxable was null, or not finite")
+	for _, f := range []string{"TreasuryTEY", "NatlTEY", "StateTEY", "AMTFreeTEY"} {
+		s.Nullable(f, "null when not finite, as at a 100% tax rate")
+	}
+	s.Nullable("GrossUp", "null when the benchmark's effective tax is 100% or more")
+	line := s.Property("Lines").Items()
+	line.Nullable("Yield", "null on the fully-taxable line when Inputs.FullyTaxable was null")
+	line.Nullable("AfterTax", "null on the fully-taxable line when Inputs.FullyTaxable was null")
+	line.Nullable("EffectiveTaxRate", "null on the fully-taxable line when Inputs.FullyTaxable was null")
+	line.Nullable("TEY", "null on the fully-taxable line when Inputs.FullyTaxable was null, or when not finite")
+	line.Nullable("AfterTaxAfterFee", "null on the fully-taxable line when Inputs.FullyTaxable was null")
+	line.Nullable("TEYSpreadBps", "null with the synthetic benchmark, or when not finite")
+	line.Nullable("TEYVsFullyTaxable", "null when not finite")
+	line.Nullable("TEYVsTreasury", "null without a treasury yield, or when not finite")
+	return s
+}
+
+func openAPIDoc() map[string]any {
+	return schema.OpenAPI("taxableyield", "1.0.0",
+		map[string]schema.Schema{"Inputs": inputsSchema(), "Result": resultSchema()},
+		schema.Operation{Method: "post", Path: "/compute", Summary: "Compute after-tax and tax equivalent yields", Request: "Inputs", Response: "Result"},
+	)
+}
+
+func runServe(args []string, stdout io.Writer) error {
+	fs := flag.NewFlagSet("serve", flag.ContinueOnError)
+	addr := fs.String("addr", ":8080", "listen address")
+	cfg := defaultServerConfig
+	fs.Int64Var(&cfg.MaxUploadBytes, "max-upload", cfg.MaxUploadBytes, "largest request body (bytes): the CSV on /batch, JSON elsewhere")
+	fs.DurationVar(&cfg.ReadTimeout, "read-timeout", cfg.ReadTimeout, "HTTP read timeout")
+	fs.DurationVar(&cfg.WriteTimeout, "write-timeout", cfg.WriteTimeout, "HTTP write timeout")
+	fs.DurationVar(&cfg.IdleTimeout, "idle-timeout", cfg.IdleTimeout, "HTTP keep-alive idle timeout")
+	fs.DurationVar(&cfg.RequestTimeout, "request-timeout", cfg.RequestTimeout, "per-request compute timeout (0 for none)")
+	fs.DurationVar(&cfg.ShutdownTimeout, "shutdown-timeout", cfg.ShutdownTimeout, "how long to drain in-flight requests on shutdown")
+	fs.Float64Var(&cfg.RateLimit, "rate-limit", cfg.RateLimit, "requests per second per client (0 for no limit)")
+	fs.IntVar(&cfg.RateBurst, "rate-burst", cfg.RateBurst, "requests a client may burst over -rate-limit")
+	fs.StringVar(&cfg.RateKeyHeader, "rate-key-header", "", `header to key clients by (e.g. "X-API-Key") instead of IP`)
+	keysFile := fs.String("rate-keys-file", "", "file of API keys accepted in -rate-key-header, one per line")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if cfg.RateKeyHeader != "" {
+		if *keysFile == "" {
+			return errors.New("-rate-key-header needs -rate-keys-file")
+		}
+		keys, err := readRateKeys(*keysFile)
+		if err != nil {
+			return err
+		}
+		cfg.RateKeys = keys
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	srv := &http.Server{
+		Addr:         *addr,
+		Handler:      newServer(cfg),
+		ReadTimeout:  cfg.ReadTimeout,
+		WriteTimeout: cfg.WriteTimeout,
+		IdleTimeout:  cfg.IdleTimeout,
+	}
+	fmt.Fprintf(stdout, "listening on %s\n", *addr)
+	return serveUntil(ctx, srv, cfg.ShutdownTimeout)
+}
+
+// serveUntil runs srv until ctx is done, then shuts it down gracefully,
+// waiting up to drain for in-flight requests.
+func serveUntil(ctx context.Context, srv *http.Server, drain time.Duration) error {
+	errc := make(chan error, 1)
+	go func() { errc <- srv.ListenAndServe() }()
+	select {
+	case err := <-errc:
+		return err
+	case <-ctx.Done():
+	}
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), drain)
+	defer cancel()
+	return srv.Shutdown(shutdownCtx)
+}
@@ -0,0 +1,356 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"mime/multipart"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+// csvUpload is a /batch request uploading body as the "file" field.
+func csvUpload(t *testing.T, body string) *http.Request {
+	t.Helper()
+	var buf bytes.Buffer
+	mw := multipart.NewWriter(&buf)
+	fw, err := mw.CreateFormFile("file", "inputs.csv")
+	if err != nil {
+		t.Fatal(err)
+	}
+	io.WriteString(fw, body)
+	mw.Close()
+	req := httptest.NewRequest("POST", "/batch", &buf)
+	req.Header.Set("Content-Type", mw.FormDataContentType())
+	return req
+}
+
+// manyRows is a CSV of n copies of the example's yields.
+func manyRows(n int) string {
+	var b strings.Builder
+	b.WriteString("FullyTaxable,Treasury,NatlTaxExempt,FedBracket,StateBracket\n")
+	for range n {
+		b.WriteString("5,4.5,3.8,24,9.3\n")
+	}
+	return b.String()
+}
+
+func TestComputeEndpoint(t *testing.T) {
+	body, _ := json.Marshal(exampleInputs())
+	rec := httptest.NewRecorder()
+	newServer(defaultServerConfig).ServeHTTP(rec, httptest.NewRequest("POST", "/compute", bytes.NewReader(body)))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status %d: %s", rec.Code, rec.Body)
+	}
+	var res Result
+	if err := json.Unmarshal(rec.Body.Bytes(), &res); err != nil {
+		t.Fatal(err)
+	}
+	if want := Compute(exampleInputs()); res.Text != want.Text {
+		t.Errorf("got\n%s\nwant\n%s", res.Text, want.Text)
+	}
+}
+
+func TestComputeEndpointLimits(t *testing.T) {
+	body, _ := json.Marshal(exampleInputs())
+	t.Run("too big", func(t *testing.T) {
+		cfg := defaultServerConfig
+		cfg.MaxUploadBytes = 16
+		rec := httptest.NewRecorder()
+		newServer(cfg).ServeHTTP(rec, httptest.NewRequest("POST", "/compute", bytes.NewReader(body)))
+		if rec.Code != http.StatusBadRequest || !strings.Contains(rec.Body.String(), "body exceeds 16 bytes") {
+			t.Errorf("status %d: %s", rec.Code, rec.Body)
+		}
+	})
+	t.Run("timeout", func(t *testing.T) {
+		cfg := defaultServerConfig
+		cfg.RequestTimeout = time.Nanosecond
+		rec := httptest.NewRecorder()
+		newServer(cfg).ServeHTTP(rec, httptest.NewRequest("POST", "/compute", bytes.NewReader(body)))
+		if rec.Code != http.StatusGatewayTimeout {
+			t.Errorf("status %d, want %d: %s", rec.Code, http.StatusGatewayTimeout, rec.Body)
+		}
+	})
+}
+
+func TestBatchTimeout(t *testing.T) {
+	cfg := defaultServerConfig
+	cfg.RequestTimeout = time.Nanosecond
+	rec := httptest.NewRecorder()
+	newServer(cfg).ServeHTTP(rec, csvUpload(t, manyRows(500)))
+	if rec.Code != http.StatusGatewayTimeout {
+		t.Errorf("status %d, want %d: %s", rec.Code, http.StatusGatewayTimeout, rec.Body)
+	}
+}
+
+func TestBatchCanceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	rec := httptest.NewRecorder()
+	newServer(defaultServerConfig).ServeHTTP(rec, csvUpload(t, manyRows(500)).WithContext(ctx))
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Errorf("status %d, want %d: %s", rec.Code, http.StatusServiceUnavailable, rec.Body)
+	}
+}
+
+func TestServeUntilDrains(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := l.Addr().String()
+	l.Close()
+
+	started := make(chan struct{})
+	srv := &http.Server{Addr: addr, Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		close(started)
+		time.Sleep(200 * time.Millisecond)
+		io.WriteString(w, "done")
+	})}
+	ctx, stop := context.WithCancel(context.Background())
+	served := make(chan error, 1)
+	go func() { served <- serveUntil(ctx, srv, 5*time.Second) }()
+
+	type reply struct {
+		body string
+		err  error
+	}
+	replies := make(chan reply, 1)
+	go func() {
+		for {
+			resp, err := http.Get(fmt.Sprintf("http://%s/", addr))
+			if err != nil {
+				time.Sleep(10 * time.Millisecond) // not listening yet
+				continue
+			}
+			b, err := io.ReadAll(resp.Body)
+			resp.Body.Close()
+			replies <- reply{string(b), err}
+			return
+		}
+	}()
+
+	select {
+	case <-started:
+	case <-time.After(5 * time.Second):
+		t.Fatal("request never reached the handler")
+	}
+	stop()
+	if r := <-replies; r.err != nil || r.body != "done" {
+		t.Errorf("in-flight request got %q, %v; want it to finish", r.body, r.err)
+	}
+	if err := <-served; err != nil {
+		t.Errorf("serveUntil: %v", err)
+	}
+}
+
+// validate checks v, decoded JSON, against s, a decoded JSON Schema, for the
+// keywords schema.Generate emits. It returns the first problem found.
+func validate(v any, s map[string]any, path string) error {
+	var types []string
+	switch t := s["type"].(type) {
+	case string:
+		types = []string{t}
+	case []any:
+		for _, x := range t {
+			types = append(types, x.(string))
+		}
+	}
+	if len(types) > 0 {
+		got := map[bool]string{true: "null"}[v == nil]
+		switch x := v.(type) {
+		case bool:
+			got = "boolean"
+		case float64:
+			got = "number"
+			if x == math.Trunc(x) && slices.Contains(types, "integer") {
+				got = "integer"
+			}
+		case string:
+			got = "string"
+		case []any:
+			got = "array"
+		case map[string]any:
+			got = "object"
+		}
+		if !slices.Contains(types, got) {
+			return fmt.Errorf("%s: %s, want %v", path, got, types)
+		}
+	}
+	switch x := v.(type) {
+	case map[string]any:
+		props, _ := s["properties"].(map[string]any)
+		extra, _ := s["additionalProperties"].(map[string]any)
+		for k, fv := range x {
+			ps, ok := props[k].(map[string]any)
+			if !ok {
+				ps = extra
+			}
+			if ps == nil {
+				continue
+			}
+			if err := validate(fv, ps, path+"."+k); err != nil {
+				return err
+			}
+		}
+	case []any:
+		items, _ := s["items"].(map[string]any)
+		for i, e := range x {
+			if err := validate(e, items, fmt.Sprintf("%s[%d]", path, i)); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// decoded is v through a JSON round trip.
+func decoded(t *testing.T, v any) any {
+	t.Helper()
+	b, err := json.Marshal(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var out any
+	if err := json.Unmarshal(b, &out); err != nil {
+		t.Fatal(err)
+	}
+	return out
+}
+
+func TestSchemasValidateSamples(t *testing.T) {
+	inSchema := decoded(t, inputsSchema()).(map[string]any)
+	resSchema := decoded(t, resultSchema()).(map[string]any)
+
+	in := exampleInputs()
+	in.Enabled = map[InstrumentKind]bool{NatlTaxExemptKind: true}
+	if err := validate(decoded(t, in), inSchema, "Inputs"); err != nil {
+		t.Errorf("example Inputs: %v", err)
+	}
+	// the documented null-for-NaN convention
+	var fallback map[string]any
+	json.Unmarshal([]byte(`{"FullyTaxable": null, "Treasury": 4.5, "FedBracket": 24, "Itemize": true}`), &fallback)
+	if err := validate(fallback, inSchema, "Inputs"); err != nil {
+		t.Errorf("null FullyTaxable: %v", err)
+	}
+	var bad map[string]any
+	json.Unmarshal([]byte(`{"FedBracket": "high"}`), &bad)
+	if err := validate(bad, inSchema, "Inputs"); err == nil {
+		t.Error("a string FedBracket validated")
+	}
+
+	nan := exampleInputs()
+	nan.FullyTaxable = math.NaN()
+	for name, in := range map[string]Inputs{"example": exampleInputs(), "NaN fallback": nan} {
+		if err := validate(decoded(t, Compute(in)), resSchema, "Result"); err != nil {
+			t.Errorf("%s Result: %v", name, err)
+		}
+	}
+}
+
+func TestOpenAPIEndpoint(t *testing.T) {
+	rec := httptest.NewRecorder()
+	newServer(defaultServerConfig).ServeHTTP(rec, httptest.NewRequest("GET", "/openapi.json", nil))
+	var doc struct {
+		OpenAPI    string
+		Paths      map[string]map[string]any
+		Components struct{ Schemas map[string]any }
+	}
+	if err := json.Unmarshal(rec.Body.Bytes(), &doc); err != nil {
+		t.Fatalf("%v: %s", err, rec.Body)
+	}
+	if doc.Paths["/compute"]["post"] == nil || doc.Components.Schemas["Inputs"] == nil || doc.Components.Schemas["Result"] == nil {
+		t.Errorf("missing /compute or its schemas: %s", rec.Body)
+	}
+}
+
+func TestBatchUpload(t *testing.T) {
+	upload := "FullyTaxable,Treasury,NatlTaxExempt,FedBracket,StateBracket,Itemize\n" +
+		"5,4.5,3.8,24,9.3,true\n" +
+		",4.5%,3.8,35,0,false\n" // no fully-taxable yield: the gross-up fallback
+	rec := httptest.NewRecorder()
+	newServer(defaultServerConfig).ServeHTTP(rec, csvUpload(t, upload))
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status %d: %s", rec.Code, rec.Body)
+	}
+	if ct, cd := rec.Header().Get("Content-Type"), rec.Header().Get("Content-Disposition"); ct != "text/csv" || !strings.Contains(cd, "attachment") {
+		t.Errorf("Content-Type %q, Content-Disposition %q", ct, cd)
+	}
+	rows, err := csv.NewReader(rec.Body).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rows) != 3 {
+		t.Fatalf("%d rows, want a header and 2", len(rows))
+	}
+	col := map[string]int{}
+	for i, h := range rows[0] {
+		col[h] = i
+	}
+	for _, c := range []struct {
+		row  int
+		name string
+		want string
+	}{
+		{1, "FedBracket", "24"},
+		{1, "Itemize", "true"},
+		{1, "fully_taxable_after_tax", "3.4466"},
+		{1, "treasury_after_tax", "3.42"},
+		{2, "FedBracket", "35"},
+		{2, "fully_taxable_after_tax", ""},
+		{2, "treasury_after_tax", "2.925"},
+		{2, "natl_after_tax", "3.8"},
+	} {
+		i, ok := col[c.name]
+		if !ok {
+			t.Errorf("no %s column in %v", c.name, rows[0])
+			continue
+		}
+		if got := rows[c.row][i]; got != c.want && !closeStrings(got, c.want) {
+			t.Errorf("row %d %s = %q, want %q", c.row, c.name, got, c.want)
+		}
+	}
+}
+
+// closeStrings is whether a and b are numbers within 1e-9.
+func closeStrings(a, b string) bool {
+	x, err1 := strconv.ParseFloat(a, 64)
+	y, err2 := strconv.ParseFloat(b, 64)
+	return err1 == nil && err2 == nil && near(x, y)
+}
+
+func TestBatchUploadErrors(t *testing.T) {
+	t.Run("malformed", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		newServer(defaultServerConfig).ServeHTTP(rec, csvUpload(t, "FedBracket,Treasury\n24,4.5\n24,lots\n"))
+		if rec.Code != http.StatusBadRequest || !strings.Contains(rec.Body.String(), "line 3") {
+			t.Errorf("status %d: %s", rec.Code, rec.Body)
+		}
+	})
+	t.Run("too big", func(t *testing.T) {
+		cfg := defaultServerConfig
+		cfg.MaxUploadBytes = 256
+		rec := httptest.NewRecorder()
+		newServer(cfg).ServeHTTP(rec, csvUpload(t, manyRows(100)))
+		if rec.Code != http.StatusBadRequest || !strings.Contains(rec.Body.String(), "exceeds 256 bytes") {
+			t.Errorf("status %d: %s", rec.Code, rec.Body)
+		}
+	})
+	t.Run("no file", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		newServer(defaultServerConfig).ServeHTTP(rec, httptest.NewRequest("POST", "/batch", strings.NewReader("")))
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("status %d: %s", rec.Code, rec.Body)
+		}
+	})
+}
@@ -0,0 +1,71 @@
+package main
+
+import (
+	"maps"
+	"slices"
+	"sync"
+	"time"
+)
+
+// Report is one computation: what went in, what came out, and when.
+type Report struct {
+	Inputs Inputs
+	Result Result
+	Time   time.Time
+}
+
+// Session records every Compute call, e.g. for an advisor's demo that
+// regenerates a report or steps back through what was tried. The zero value
+// is ready to use, and it's safe for concurrent use.
+type Session struct {
+	mu  sync.Mutex
+	log []Report
+}
+
+// Compute is Compute(in), logged to the session. The log keeps its own copy
+// of in's maps and slices, so the caller can reuse them.
+func (s *Session) Compute(in Inputs) Result {
+	res := Compute(in)
+	in = in.clone()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.log = append(s.log, Report{Inputs: in, Result: res, Time: time.Now()})
+	return res
+}
+
+// clone is in with nothing shared with the original: its maps, slices and
+// pointers are copied.
+func (in Inputs) clone() Inputs {
+	in.Enabled = maps.Clone(in.Enabled)
+	in.YieldToWorst = maps.Clone(in.YieldToWorst)
+	in.YieldToMaturity = maps.Clone(in.YieldToMaturity)
+	in.CreditSpread = maps.Clone(in.CreditSpread)
+	in.Compounding = maps.Clone(in.Compounding)
+	in.Reinvest = maps.Clone(in.Reinvest)
+	in.AMTFreeFunds = slices.Clone(in.AMTFreeFunds)
+	if in.TreasuryStateExempt != nil {
+		exempt := *in.TreasuryStateExempt
+		in.TreasuryStateExempt = &exempt
+	}
+	return in
+}
+
+// History is the session's computations, oldest first. The Inputs' maps
+// are the log's own; treat them as read-only.
+func (s *Session) History() []Report {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return slices.Clone(s.log)
+}
+
+// Replay recomputes each logged Inputs in order, without logging again. The
+// results match History's unless Compute itself has changed since (rate
+// tables, say).
+func (s *Session) Replay() []Result {
+	history := s.History()
+	results := make([]Result, len(history))
+	for i, r := range history {
+		results[i] = Compute(r.Inputs)
+	}
+	return results
+}
@@ -0,0 +1,100 @@
+package main
+
+import (
+	"encoding/json"
+	"sync"
+	"testing"
+)
+
+func TestSessionReplay(t *testing.T) {
+	high := exampleInputs()
+	high.FedBracket, high.AMT, high.NatlAmTPct = 37, true, 20
+	scenarios := []Inputs{exampleInputs(), high, {FedBracket: 12, FullyTaxable: 4.5, NatlTaxExempt: 3}}
+
+	var s Session
+	var results []Result
+	for _, in := range scenarios {
+		results = append(results, s.Compute(in))
+	}
+	history := s.History()
+	if len(history) != len(scenarios) {
+		t.Fatalf("%d reports, want %d", len(history), len(scenarios))
+	}
+	for i, r := range history {
+		if r.Inputs.Fingerprint() != scenarios[i].Fingerprint() {
+			t.Errorf("report %d has inputs %+v", i, r.Inputs)
+		}
+		if i > 0 && r.Time.Before(history[i-1].Time) {
+			t.Errorf("report %d at %v, before report %d", i, r.Time, i-1)
+		}
+	}
+
+	replayed := s.Replay()
+	if len(replayed) != len(results) {
+		t.Fatalf("%d replayed, want %d", len(replayed), len(results))
+	}
+	for i := range results {
+		want, err := json.Marshal(results[i])
+		if err != nil {
+			t.Fatal(err)
+		}
+		for name, res := range map[string]Result{"replayed": replayed[i], "logged": history[i].Result} {
+			got, err := json.Marshal(res)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(got) != string(want) {
+				t.Errorf("%s %d:\n%s\nwant\n%s", name, i, got, want)
+			}
+		}
+	}
+	if n := len(s.History()); n != len(scenarios) {
+		t.Errorf("Replay logged: %d reports", n)
+	}
+}
+
+func TestSessionConcurrent(t *testing.T) {
+	var s Session
+	var wg sync.WaitGroup
+	for range 20 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			s.Compute(exampleInputs())
+		}()
+	}
+	wg.Wait()
+	if n := len(s.History()); n != 20 {
+		t.Errorf("%d reports, want 20", n)
+	}
+}
+
+func TestSessionCopiesMaps(t *testing.T) {
+	in := exampleInputs()
+	in.Enabled = map[InstrumentKind]bool{FullyTaxableKind: true, NatlTaxExemptKind: true}
+	in.YieldToWorst, in.UseYTW = map[InstrumentKind]float64{NatlTaxExemptKind: 3.6}, true
+	in.CreditSpread = map[InstrumentKind]float64{FullyTaxableKind: 0.2}
+	in.Compounding = map[InstrumentKind]Compounding{FullyTaxableKind: Monthly}
+	in.Reinvest = map[InstrumentKind]bool{FullyTaxableKind: true}
+	in.ReinvestmentRate, in.ReinvestmentYears = 4, 5
+	in.AMTFreeFunds = []NamedYield{{Name: "Fund A", Yield: 3.5}}
+	var s Session
+	s.Compute(in)
+	want := s.Replay()[0]
+
+	// the caller reuses its maps for the next scenario
+	in.Enabled[TreasuryKind] = true
+	in.YieldToWorst[NatlTaxExemptKind] = 2
+	in.CreditSpread[FullyTaxableKind] = 1
+	in.Compounding[FullyTaxableKind] = Annual
+	delete(in.Reinvest, FullyTaxableKind)
+	in.AMTFreeFunds[0].Yield = 1
+
+	got := s.Replay()[0]
+	if got.Text != want.Text {
+		t.Errorf("changing the caller's maps changed the logged Inputs:\n%s\nwant\n%s", got.Text, want.Text)
+	}
+	if logged := s.History()[0].Inputs; logged.YieldToWorst[NatlTaxExemptKind] != 3.6 || !logged.Reinvest[FullyTaxableKind] {
+		t.Errorf("logged maps %v, %v", logged.YieldToWorst, logged.Reinvest)
+	}
+}
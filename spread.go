@@ -0,0 +1,28 @@
+package main
+
+import "math"
+
+// spreadToTreasury is each line's after-tax yield minus the treasury's, in
+// basis points, keyed like ToMap ("natl", "amt_free", ...). It's nil when
+// there's no treasury yield to measure against. The treasury itself and
+// lines with NaN yields are left out.
+func spreadToTreasury(lines []ResultLine) map[string]float64 {
+	treasury := -1
+	for i, l := range lines {
+		if l.Kind == TreasuryKind && l.Yield != 0 && !math.IsNaN(l.AfterTax) {
+			treasury = i
+			break
+		}
+	}
+	if treasury < 0 {
+		return nil
+	}
+	spreads := map[string]float64{}
+	for i, key := range lineKeys(lines) {
+		if i == treasury || math.IsNaN(lines[i].AfterTax) {
+			continue
+		}
+		spreads[key] = 100 * (lines[i].AfterTax - lines[treasury].AfterTax)
+	}
+	return spreads
+}
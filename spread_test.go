@@ -0,0 +1,41 @@
+package main
+
+import (
+	"math"
+	"testing"
+)
+
+func TestSpreadToTreasury(t *testing.T) {
+	// a 37% California investor: 4.5% Treasury nets 2.835%, a 3.5% in-state
+	// muni all of it
+	in := Inputs{FullyTaxable: 5, Treasury: 4.5, NatlTaxExempt: 3.5, StateTaxExempt: 3.5, FedBracket: 37, StateBracket: 13.3}
+	res := Compute(in)
+	s := res.SpreadToTreasury
+	for key, want := range map[string]float64{
+		"fully_taxable": 100 * (5*(1-0.503) - 2.835),
+		"natl":          100 * (3.5*(1-0.133) - 2.835),
+		"state":         100 * (3.5 - 2.835),
+		"amt_free":      -283.5,
+	} {
+		if !near(s[key], want) {
+			t.Errorf("%s spread %v bps, want %v", key, s[key], want)
+		}
+	}
+	if s["state"] <= 0 || s["natl"] <= 0 {
+		t.Errorf("munis should pick up yield over Treasuries at 37%%: %v", s)
+	}
+	if _, ok := s["treasury"]; ok {
+		t.Error("the Treasury has a spread to itself")
+	}
+
+	in.Treasury = 0
+	if s := Compute(in).SpreadToTreasury; s != nil {
+		t.Errorf("spreads without a Treasury yield: %v", s)
+	}
+
+	// NaN lines are skipped
+	lines := []ResultLine{{Kind: TreasuryKind, Yield: 4, AfterTax: 3}, {Kind: NatlTaxExemptKind, AfterTax: math.NaN()}, {Kind: StateTaxExemptKind, AfterTax: 3.2}}
+	if s := spreadToTreasury(lines); len(s) != 1 || !near(s["state"], 20) {
+		t.Errorf("spreads %v, want just state at 20", s)
+	}
+}
@@ -0,0 +1,65 @@
+package main
+
+import (
+	"fmt"
+	"maps"
+	"math"
+	"slices"
+)
+
+// ComputeStrict is Compute, but returns an error instead of letting a NaN or
+// Inf into the Result. The error names the offending line and field, and
+// why. The NaNs Compute uses to mean "not applicable" are allowed: TEY
+// spreads with the synthetic benchmark (see ResultLine.TEYSpreadBps) and
+// TEYVsTreasury without a treasury yield.
+func ComputeStrict(in Inputs) (Result, error) {
+	// Effective tax on the benchmark the gross-up actually uses.
+	bench := benchmarkInstrument(in)
+	benchTax := 100 * (1 - bench.AfterTax(in)/bench.EffectiveYield())
+	if !(benchTax < 100) {
+		return Result{}, fmt.Errorf("gross-up undefined: effective tax on the %s benchmark is %.3g%%", in.Benchmark, benchTax)
+	}
+
+	res := Compute(in)
+	if !isFinite(res.GrossUp) {
+		return Result{}, fmt.Errorf("gross-up is %v", res.GrossUp)
+	}
+
+	_, haveBench := benchmarkYield(in)
+	for _, l := range res.Lines {
+		checks := []struct {
+			field string
+			value float64
+			naOK  bool // NaN means not applicable here
+		}{
+			{"Yield", l.Yield, false},
+			{"AfterTax", l.AfterTax, false},
+			{"TEY", l.TEY, false},
+			{"EffectiveTaxRate", l.EffectiveTaxRate, false},
+			{"AfterTaxAfterFee", l.AfterTaxAfterFee, false},
+			{"TEYSpreadBps", l.TEYSpreadBps, !haveBench},
+			{"TEYVsFullyTaxable", l.TEYVsFullyTaxable, false},
+			{"TEYVsTreasury", l.TEYVsTreasury, !res.render.showTreasuryTEY},
+		}
+		for _, c := range checks {
+			if isFinite(c.value) || c.naOK && math.IsNaN(c.value) {
+				continue
+			}
+			if !isFinite(l.Yield) {
+				return Result{}, fmt.Errorf("%s line: %s is %v: its yield is %v", l.Label, c.field, c.value, l.Yield)
+			}
+			return Result{}, fmt.Errorf("%s line: %s is %v: tax settings out of range", l.Label, c.field, c.value)
+		}
+	}
+	for _, key := range slices.Sorted(maps.Keys(res.SpreadToTreasury)) {
+		if v := res.SpreadToTreasury[key]; !isFinite(v) {
+			return Result{}, fmt.Errorf("SpreadToTreasury[%s] is %v: tax settings out of range", key, v)
+		}
+	}
+
+	return res, nil
+}
+
+func isFinite(f float64) bool {
+	return !math.IsNaN(f) && !math.IsInf(f, 0)
+}
@@ -0,0 +1,46 @@
+package main
+
+import (
+	"math"
+	"strings"
+	"testing"
+)
+
+func TestComputeStrict(t *testing.T) {
+	tests := []struct {
+		name    string
+		edit    func(*Inputs)
+		wantErr string // "" for no error
+	}{
+		{"example", func(in *Inputs) {}, ""},
+		{"tax over 100%", func(in *Inputs) { in.FedBracket, in.StateBracket, in.Itemize = 80, 30, false }, "gross-up undefined: effective tax on the Fully Taxable benchmark is 110%"},
+		{"tax exactly 100%", func(in *Inputs) { in.FedBracket, in.StateBracket = 100, 0 }, "gross-up undefined: effective tax on the Fully Taxable benchmark is 100%"},
+		{"treasury benchmark", func(in *Inputs) {
+			in.Benchmark = TreasuryKind
+			in.FedBracket, in.StateBracket = 100, 0
+		}, "effective tax on the Treasury benchmark is 100%"},
+		{"NaN extra instrument", func(in *Inputs) { in.Corporate = math.NaN() }, "Corporate line: Yield is NaN"},
+		{"Inf extra instrument", func(in *Inputs) { in.Agency = math.Inf(1) }, "Agency line: Yield is +Inf: its yield is +Inf"},
+		{"NaN fallback", func(in *Inputs) { in.FullyTaxable = math.NaN() }, "Fully Taxable line: Yield is NaN"},
+		{"no treasury", func(in *Inputs) { in.Treasury = 0 }, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			in := exampleInputs()
+			tt.edit(&in)
+			res, err := ComputeStrict(in)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatalf("ComputeStrict: %v", err)
+				}
+				if res.Text != Compute(in).Text {
+					t.Errorf("ComputeStrict result differs from Compute:\n%s", res.Text)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("ComputeStrict error = %v, want one containing %q", err, tt.wantErr)
+			}
+		})
+	}
+}
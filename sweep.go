@@ -0,0 +1,52 @@
+package main
+
+import (
+	"math"
+
+	"github.com/kybouw/taxableyield/chart"
+)
+
+// BracketPoint is one step of a BracketSweep.
+type BracketPoint struct {
+	FedBracket float64
+	Result     Result
+}
+
+// BracketSweep is in computed at each federal bracket from from to to by
+// step, both ends included. It's empty unless step > 0 and from <= to.
+func BracketSweep(in Inputs, from, to, step float64) []BracketPoint {
+	if !(step > 0) {
+		return nil
+	}
+	var sweep []BracketPoint
+	for i := 0; ; i++ {
+		fed := from + float64(i)*step
+		if fed > to+step*1e-9 {
+			return sweep
+		}
+		in.FedBracket = fed
+		sweep = append(sweep, BracketPoint{FedBracket: fed, Result: Compute(in)})
+	}
+}
+
+// FindCrossovers are the brackets where a and b's after-tax yields cross
+// over sweep, interpolated linearly between adjacent points (as
+// chart.Crossings does), e.g. where a muni overtakes the treasury. A point
+// without both lines counts as NaN, so no crossing is found next to it.
+func FindCrossovers(sweep []BracketPoint, a, b InstrumentKind) []float64 {
+	var sa, sb chart.Series
+	for _, p := range sweep {
+		sa.X = append(sa.X, p.FedBracket)
+		sa.Y = append(sa.Y, afterTaxOf(p.Result, a))
+		sb.Y = append(sb.Y, afterTaxOf(p.Result, b))
+	}
+	return chart.Crossings(sa, sb)
+}
+
+// afterTaxOf is r's after-tax yield for k, or NaN without that line.
+func afterTaxOf(r Result, k InstrumentKind) float64 {
+	if l, ok := r.Line(k); ok {
+		return l.AfterTax
+	}
+	return math.NaN()
+}
@@ -0,0 +1,54 @@
+package main
+
+import (
+	"math"
+	"testing"
+)
+
+func TestBracketSweep(t *testing.T) {
+	sweep := BracketSweep(exampleInputs(), 10, 37, 1)
+	if len(sweep) != 28 {
+		t.Fatalf("%d points, want 28", len(sweep))
+	}
+	for i, p := range sweep {
+		if p.FedBracket != float64(10+i) {
+			t.Errorf("point %d at %v%%", i, p.FedBracket)
+		}
+	}
+	if BracketSweep(exampleInputs(), 10, 37, 0) != nil {
+		t.Error("a zero step should sweep nothing")
+	}
+}
+
+func TestFindCrossovers(t *testing.T) {
+	t.Run("one", func(t *testing.T) {
+		in := exampleInputs()
+		// treasury 4.5(1-f) meets the muni's flat 3.8(1 - 0.093)
+		want := 100 * (1 - 3.8*(1-0.093)/4.5)
+		got := FindCrossovers(BracketSweep(in, 10, 37, 1), NatlTaxExemptKind, TreasuryKind)
+		if len(got) != 1 || math.Abs(got[0]-want) > 1e-9 {
+			t.Errorf("got %v, want [%v]", got, want)
+		}
+	})
+	t.Run("none", func(t *testing.T) {
+		in := exampleInputs()
+		if got := FindCrossovers(BracketSweep(in, 10, 37, 1), StateTaxExemptKind, AMTFreeKind); len(got) != 0 {
+			t.Errorf("got %v, want none", got)
+		}
+	})
+	t.Run("on the last point", func(t *testing.T) {
+		// 5% treasury, no state tax: 4% after tax at exactly 20%
+		in := Inputs{Treasury: 5, NatlTaxExempt: 4}
+		got := FindCrossovers(BracketSweep(in, 10, 20, 1), NatlTaxExemptKind, TreasuryKind)
+		if len(got) != 1 || got[0] != 20 {
+			t.Errorf("got %v, want [20]", got)
+		}
+	})
+	t.Run("missing line", func(t *testing.T) {
+		in := exampleInputs()
+		in.Treasury = 0
+		if got := FindCrossovers(BracketSweep(in, 10, 37, 1), NatlTaxExemptKind, TreasuryKind); len(got) != 0 {
+			t.Errorf("got %v, want none without a treasury line", got)
+		}
+	})
+}
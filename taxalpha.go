@@ -0,0 +1,32 @@
+package main
+
+import (
+	"fmt"
+	"math"
+)
+
+// TaxAlpha is what picking r's best line after tax earns over just buying
+// the fully taxable bond, in basis points after tax. It's 0 when the fully
+// taxable bond is already the best, and NaN without a fully-taxable line.
+func (r Result) TaxAlpha() float64 {
+	naive, ok := r.Line(FullyTaxableKind)
+	ranked := r.RankByAfterTax()
+	if !ok || math.IsNaN(naive.AfterTax) || len(ranked) == 0 {
+		return math.NaN()
+	}
+	return 100 * (ranked[0].AfterTax - naive.AfterTax)
+}
+
+// TaxAlphaSummary describes TaxAlpha in a line, e.g.
+//
+//	Tax alpha: +25 bps (AMT Free over Fully Taxable)
+func (r Result) TaxAlphaSummary() string {
+	alpha := r.TaxAlpha()
+	switch {
+	case math.IsNaN(alpha):
+		return "Tax alpha: n/a (no fully taxable yield)"
+	case alpha <= 0:
+		return "Tax alpha: none (Fully Taxable is already best)"
+	}
+	return fmt.Sprintf("Tax alpha: %s (%s over Fully Taxable)", formatBps(alpha), r.RankByAfterTax()[0].Label)
+}
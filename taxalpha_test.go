@@ -0,0 +1,41 @@
+package main
+
+import (
+	"math"
+	"testing"
+)
+
+func TestTaxAlpha(t *testing.T) {
+	yields := Inputs{FullyTaxable: 5, Treasury: 4.5, NatlTaxExempt: 3.5, StateTaxExempt: 3.5}
+	for _, tt := range []struct {
+		name    string
+		fed     float64
+		state   float64
+		alpha   float64
+		summary string
+	}{
+		// 5% nets 2.485%, the in-state muni 3.5%
+		{"California 37%", 37, 13.3, 101.5, "Tax alpha: +102 bps (State Tax-Exempt over Fully Taxable)"},
+		{"10%, no state tax", 10, 0, 0, "Tax alpha: none (Fully Taxable is already best)"},
+	} {
+		in := yields
+		in.FedBracket, in.StateBracket = tt.fed, tt.state
+		res := Compute(in)
+		if got := res.TaxAlpha(); !near(got, tt.alpha) {
+			t.Errorf("%s: alpha %v bps, want %v", tt.name, got, tt.alpha)
+		}
+		if got := res.TaxAlphaSummary(); got != tt.summary {
+			t.Errorf("%s: summary %q, want %q", tt.name, got, tt.summary)
+		}
+	}
+
+	in := yields
+	in.Enabled = map[InstrumentKind]bool{NatlTaxExemptKind: true}
+	res := Compute(in)
+	if got := res.TaxAlpha(); !math.IsNaN(got) {
+		t.Errorf("no fully taxable line: alpha %v, want NaN", got)
+	}
+	if got := res.TaxAlphaSummary(); got != "Tax alpha: n/a (no fully taxable yield)" {
+		t.Errorf("no fully taxable line: %q", got)
+	}
+}
@@ -0,0 +1,17 @@
+package main
+
+import "math"
+
+// taxDrag is how much of afterTax Inputs.TaxDragBps takes off inst: the
+// full haircut on taxable lines, but never past 0, and nothing on exempt
+// ones or after-tax quotes. Total return and balanced fund lines are taxable
+// even though their rules, not FedTaxable, say so.
+func taxDrag(inst Instrument, afterTax float64, in Inputs) float64 {
+	if in.TaxDragBps <= 0 || inst.AfterTaxQuoted {
+		return 0
+	}
+	if !inst.FedTaxable && inst.Kind != TotalReturnKind && inst.Kind != BalancedFundKind {
+		return 0
+	}
+	return math.Min(in.TaxDragBps/100, math.Max(afterTax, 0))
+}
@@ -0,0 +1,33 @@
+package main
+
+import "testing"
+
+func TestTaxDrag(t *testing.T) {
+	in := exampleInputs()
+	in.Balanced = BalancedFund{Yield: 4, OrdinaryFraction: 0.6, QualifiedFraction: 0.4}
+	base := Compute(in)
+	taxable := map[InstrumentKind]bool{FullyTaxableKind: true, TreasuryKind: true, BalancedFundKind: true}
+
+	in.TaxDragBps = 25
+	dragged := Compute(in)
+	for i, l := range base.Lines {
+		want := l.AfterTax
+		if taxable[l.Kind] {
+			want -= 0.25
+		}
+		if got := dragged.Lines[i].AfterTax; !near(got, want) {
+			t.Errorf("25bp: %s after tax %v, want %v (from %v)", l.Label, got, want, l.AfterTax)
+		}
+	}
+
+	in.TaxDragBps = 1000
+	for i, l := range Compute(in).Lines {
+		want := base.Lines[i].AfterTax
+		if taxable[l.Kind] {
+			want = 0
+		}
+		if !near(l.AfterTax, want) {
+			t.Errorf("1000bp: %s after tax %v, want %v", l.Label, l.AfterTax, want)
+		}
+	}
+}
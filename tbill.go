@@ -0,0 +1,48 @@
+package main
+
+import (
+	"fmt"
+	"math"
+)
+
+// T-bills are quoted as a discount from face on a 360-day year, which
+// understates their yield. These convert a discount rate (%) to yields that
+// compare with coupon bonds.
+
+// billPrice is the price per 100 face of a bill at discountRate (%).
+func billPrice(discountRate float64, daysToMaturity int) float64 {
+	return 100 * (1 - discountRate/100*float64(daysToMaturity)/360)
+}
+
+// BillDiscountToBondEquivalent is the bond (coupon) equivalent yield (%) of a
+// bill, as the Treasury quotes it: a simple 365-day yield on the price for
+// bills of up to half a year, and the semiannual-compounding formula beyond.
+func BillDiscountToBondEquivalent(discountRate float64, daysToMaturity int) float64 {
+	t := float64(daysToMaturity)
+	p := billPrice(discountRate, daysToMaturity)
+	if daysToMaturity <= 182 {
+		return (100 - p) / p * 365 / t * 100
+	}
+	// the Treasury's long-bill formula: solve for the semiannual yield whose
+	// half-year of simple interest, compounded over the rest, meets par
+	x := t / 365
+	return (-2*x + 2*math.Sqrt(x*x-(2*x-1)*(1-100/p))) / (2*x - 1) * 100
+}
+
+// BillDiscountToInvestmentYield is the simple 365-day return on the price,
+// with no semiannual adjustment however long the bill.
+func BillDiscountToInvestmentYield(discountRate float64, daysToMaturity int) float64 {
+	p := billPrice(discountRate, daysToMaturity)
+	return (100 - p) / p * 365 / float64(daysToMaturity) * 100
+}
+
+// tbillInstrument converts the bill's discount quote to a bond equivalent
+// yield, then taxes it like a treasury.
+func (in Inputs) tbillInstrument() Instrument {
+	inst := Instrument{Kind: TBillKind, FedTaxable: true, Optional: true}
+	if in.TBillDiscount != 0 && in.TBillDays > 0 {
+		inst.Yield = BillDiscountToBondEquivalent(in.TBillDiscount, in.TBillDays)
+		inst.Notes = []string{fmt.Sprintf("BEY of %.3f%% discount, %dd", in.TBillDiscount, in.TBillDays)}
+	}
+	return inst
+}
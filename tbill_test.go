@@ -0,0 +1,64 @@
+package main
+
+import (
+	"math"
+	"testing"
+)
+
+func TestBillDiscountToBondEquivalent(t *testing.T) {
+	tests := []struct {
+		discount float64
+		days     int
+		want     float64
+	}{
+		// 13-week bill at a 5% discount: price 98.7361, so a 1.2639
+		// gain over 91 days, on a 365-day year
+		{5, 91, 5.1343},
+		// 52-week bill at 4.8%: price 95.1467, semiannual formula
+		{4.8, 364, 5.0513},
+		{5, 300, 5.2362},
+		{0, 91, 0},
+	}
+	for _, tt := range tests {
+		if got := BillDiscountToBondEquivalent(tt.discount, tt.days); math.Abs(got-tt.want) > 5e-5 {
+			t.Errorf("%v%%, %dd: BEY %.5f, want %.4f", tt.discount, tt.days, got, tt.want)
+		}
+	}
+	// the two formulas meet at half a year
+	if a, b := BillDiscountToBondEquivalent(5, 182), BillDiscountToBondEquivalent(5, 183); math.Abs(a-b) > 0.01 {
+		t.Errorf("BEY jumps from %v to %v at 182 days", a, b)
+	}
+	// a bill's BEY is always above its discount rate
+	if got := BillDiscountToBondEquivalent(5, 28); !(got > 5) {
+		t.Errorf("4-week BEY %v", got)
+	}
+}
+
+func TestBillDiscountToInvestmentYield(t *testing.T) {
+	if got := BillDiscountToInvestmentYield(4.8, 364); math.Abs(got-5.1149) > 5e-5 {
+		t.Errorf("52-week investment yield %.5f, want 5.1149", got)
+	}
+	if a, b := BillDiscountToInvestmentYield(5, 91), BillDiscountToBondEquivalent(5, 91); a != b {
+		t.Errorf("up to half a year the two agree: %v vs %v", a, b)
+	}
+}
+
+func TestTBillLine(t *testing.T) {
+	in := exampleInputs()
+	if _, ok := Compute(in).Line(TBillKind); ok {
+		t.Error("a T-bill line without a T-bill quote")
+	}
+	in.TBillDiscount, in.TBillDays = 5, 91
+	l, ok := Compute(in).Line(TBillKind)
+	if !ok {
+		t.Fatal("no T-bill line")
+	}
+	bey := BillDiscountToBondEquivalent(5, 91)
+	// federally taxed, state-exempt
+	if !near(l.Yield, bey) || !near(l.AfterTax, bey*(1-0.24)) {
+		t.Errorf("yield %v, after tax %v; want %v, %v", l.Yield, l.AfterTax, bey, bey*(1-0.24))
+	}
+	if len(l.Notes) != 1 {
+		t.Errorf("notes %v, want the conversion noted", l.Notes)
+	}
+}
@@ -0,0 +1,26 @@
+package main
+
+// TEYMode is how a line's tax equivalent yield is derived.
+type TEYMode int
+
+const (
+	// GrossUpTEY multiplies by the fully-taxable gross-up factor (the
+	// original behavior).
+	GrossUpTEY TEYMode = iota
+	// FederalTEY is the yield a federally taxed, state-exempt bond would
+	// need for the same after-tax yield: the traditional y/(1-fed) muni TEY.
+	// For a municipal fund this doesn't credit it with a state exemption it
+	// may not have.
+	FederalTEY
+)
+
+func (m TEYMode) String() string {
+	switch m {
+	case GrossUpTEY:
+		return "gross-up"
+	case FederalTEY:
+		return "federal"
+	default:
+		return "unknown"
+	}
+}
@@ -0,0 +1,106 @@
+package main
+
+import (
+	"math"
+	"strings"
+	"testing"
+)
+
+func TestAMTFreeTEYMode(t *testing.T) {
+	in := exampleInputs()
+	grossUp := Compute(in)
+	if want := 3.7 * grossUp.GrossUp; !near(grossUp.AMTFreeTEY, want) {
+		t.Errorf("gross-up TEY %v, want %v", grossUp.AMTFreeTEY, want)
+	}
+
+	in.AMTFreeTEYMode = FederalTEY
+	federal := Compute(in)
+	if want := 3.7 / (1 - 0.24); !near(federal.AMTFreeTEY, want) {
+		t.Errorf("federal TEY %v, want %v", federal.AMTFreeTEY, want)
+	}
+	if !(federal.AMTFreeTEY < grossUp.AMTFreeTEY) {
+		t.Errorf("federal TEY %v isn't below the gross-up one %v with a state tax", federal.AMTFreeTEY, grossUp.AMTFreeTEY)
+	}
+	// only the AMT Free line changes
+	if federal.NatlTEY != grossUp.NatlTEY || federal.AMTFreeAfterTax != grossUp.AMTFreeAfterTax {
+		t.Error("the mode touched more than the AMT Free TEY")
+	}
+}
+
+func TestTEYModeString(t *testing.T) {
+	for m, want := range map[TEYMode]string{GrossUpTEY: "gross-up", FederalTEY: "federal", 9: "unknown"} {
+		if got := m.String(); got != want {
+			t.Errorf("%d: %q, want %q", m, got, want)
+		}
+	}
+}
+
+func TestBenchmarkRescalesEveryTEY(t *testing.T) {
+	in := exampleInputs()
+	vsTaxable := Compute(in)
+	in.Benchmark = TreasuryKind
+	vsTreasury := Compute(in)
+
+	ratio := vsTreasury.GrossUp / vsTaxable.GrossUp
+	if near(ratio, 1) {
+		t.Fatal("setup: the two benchmarks should gross up differently")
+	}
+	if len(vsTaxable.Lines) != 5 {
+		t.Fatalf("%d lines", len(vsTaxable.Lines))
+	}
+	for i, l := range vsTaxable.Lines {
+		if got := vsTreasury.Lines[i].TEY / l.TEY; !near(got, ratio) {
+			t.Errorf("%s TEY scaled by %v switching benchmarks, want %v", l.Label, got, ratio)
+		}
+	}
+	if vsTreasury.TreasuryTEY != 4.5 || vsTaxable.FullyTaxableTEY != 5 {
+		t.Errorf("benchmarks' own TEYs %v, %v; want their yields", vsTreasury.TreasuryTEY, vsTaxable.FullyTaxableTEY)
+	}
+}
+
+func TestTEYBothBenchmarks(t *testing.T) {
+	in := exampleInputs()
+	in.Format.ShowBothTEY = true
+	res := Compute(in)
+	// fully taxable nets 1 - 0.24 - 0.093*0.76 of each point, the
+	// state-exempt Treasury 1 - 0.24
+	taxableGrossUp, treasuryGrossUp := 1/(1-0.24-0.093*0.76), 1/(1-0.24)
+	for _, kind := range []InstrumentKind{NatlTaxExemptKind, StateTaxExemptKind, AMTFreeKind} {
+		l, _ := res.Line(kind)
+		if want := l.AfterTax * taxableGrossUp; !near(l.TEYVsFullyTaxable, want) {
+			t.Errorf("%s: TEY vs fully taxable %v, want %v", l.Label, l.TEYVsFullyTaxable, want)
+		}
+		if want := l.AfterTax * treasuryGrossUp; !near(l.TEYVsTreasury, want) {
+			t.Errorf("%s: TEY vs treasury %v, want %v", l.Label, l.TEYVsTreasury, want)
+		}
+		if near(l.TEYVsFullyTaxable, l.TEYVsTreasury) {
+			t.Errorf("%s: both TEYs are %v", l.Label, l.TEYVsTreasury)
+		}
+		// the default benchmark is fully taxable
+		if l.TEY != l.TEYVsFullyTaxable {
+			t.Errorf("%s: TEY %v, but %v vs fully taxable", l.Label, l.TEY, l.TEYVsFullyTaxable)
+		}
+	}
+	// each benchmark's TEY against itself is its yield
+	if l, _ := res.Line(FullyTaxableKind); l.TEYVsFullyTaxable != in.FullyTaxable {
+		t.Errorf("fully taxable TEY vs itself %v", l.TEYVsFullyTaxable)
+	}
+	if l, _ := res.Line(TreasuryKind); l.TEYVsTreasury != in.Treasury {
+		t.Errorf("treasury TEY vs itself %v", l.TEYVsTreasury)
+	}
+	if !strings.Contains(res.Text, "vs treasury") {
+		t.Errorf("Text has no treasury TEY:\n%s", res.Text)
+	}
+
+	// without a treasury yield there's nothing to compare to
+	in.Treasury = 0
+	res = Compute(in)
+	for _, l := range res.Lines {
+		if !math.IsNaN(l.TEYVsTreasury) {
+			t.Errorf("%s: TEY vs treasury %v without a treasury yield", l.Label, l.TEYVsTreasury)
+		}
+	}
+	if text := res.Text; strings.Contains(text, "vs treasury") || !strings.Contains(text, "vs fully taxable") {
+		t.Errorf("Text without a treasury yield:\n%s", text)
+	}
+}
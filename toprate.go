@@ -0,0 +1,11 @@
+package main
+
+// CombinedTopRate is the all-in marginal rate (%) on fully taxable
+// interest: federal (or AMT), state and local, and NIIT, less the itemized
+// state-tax deduction. It's the fully-taxable line's effective tax rate.
+//
+// The 0.9% additional Medicare tax isn't included; it's on wages and
+// self-employment income, never interest.
+func CombinedTopRate(in Inputs) float64 {
+	return 100 * (1 - calcAfterTaxYield(1, true, true, 0, in))
+}
@@ -0,0 +1,31 @@
+package main
+
+import "testing"
+
+func TestCombinedTopRate(t *testing.T) {
+	tests := []struct {
+		name string
+		in   Inputs
+		want float64
+	}{
+		{"California with NIIT", Inputs{FedBracket: 37, StateBracket: 13.3, NIIT: true}, 37 + 13.3 + 3.8},
+		{"California with NIIT, itemizing", Inputs{FedBracket: 37, StateBracket: 13.3, NIIT: true, Itemize: true}, 37 + 13.3*(1-0.37) + 3.8},
+		{"Texas with NIIT", Inputs{FedBracket: 37, NIIT: true}, 37 + 3.8},
+		{"Texas with NIIT, itemizing", Inputs{FedBracket: 37, NIIT: true, Itemize: true}, 37 + 3.8},
+		{"New York City", Inputs{FedBracket: 37, StateBracket: 10.9, LocalBracket: 3.876, NIIT: true}, 37 + 10.9 + 3.876 + 3.8},
+		{"Texas, below the NIIT threshold", Inputs{FedBracket: 24, NIIT: true, MAGI: 150000, NetInvestmentIncome: 10000}, 24},
+	}
+	for _, tt := range tests {
+		got := CombinedTopRate(tt.in)
+		if !near(got, tt.want) {
+			t.Errorf("%s: got %v, want %v", tt.name, got, tt.want)
+		}
+		// it's the fully-taxable line's implied rate
+		in := tt.in
+		in.FullyTaxable = 5
+		line, _ := Compute(in).Line(FullyTaxableKind)
+		if !near(line.EffectiveTaxRate, got) {
+			t.Errorf("%s: fully taxable line's rate %v, CombinedTopRate %v", tt.name, line.EffectiveTaxRate, got)
+		}
+	}
+}
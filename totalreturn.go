@@ -0,0 +1,83 @@
+package main
+
+import "math"
+
+// TotalReturnInstrument is an equity-like holding returning a dividend yield
+// now and price appreciation later, all in percent a year.
+type TotalReturnInstrument struct {
+	Name string
+
+	DividendYield     float64
+	QualifiedFraction float64 // share (0..1) of dividends taxed at Inputs.QDIRate
+	Appreciation      float64
+
+	// Years the position is held before the gain is realized. Deferring
+	// the tax compounds the untaxed gain; 0 (or anything under a year)
+	// taxes it as it accrues.
+	HoldingYears float64
+}
+
+// Instrument is t as an Instrument for ComputeInstruments. Its Yield is the
+// pretax total return, dividends plus appreciation.
+func (t TotalReturnInstrument) Instrument() Instrument {
+	return Instrument{Kind: TotalReturnKind, Name: t.Name, Yield: t.DividendYield + t.Appreciation,
+		Rule: totalReturnRule{t}, Optional: true}
+}
+
+type totalReturnRule struct {
+	t TotalReturnInstrument
+}
+
+// AfterTax is the after-tax total return: dividends taxed as received
+// (qualified ones at QDIRate, the rest as ordinary income) plus
+// appreciation taxed at QDIRate on sale, annualized over HoldingYears.
+// yield beyond DividendYield is taken as appreciation.
+func (r totalReturnRule) AfterTax(yield float64, in Inputs) float64 {
+	div := r.t.DividendYield
+	gain := yield - div
+	q := r.t.QualifiedFraction
+
+	divAfterTax := calcAfterTaxYield(div*(1-q), true, true, 0, in) + qualifiedAfterTax(div*q, in)
+	return divAfterTax + deferredGainAfterTax(gain, r.t.HoldingYears, in)
+}
+
+func (totalReturnRule) builtinRule() {}
+
+// qualifiedAfterTax is the after-tax value of qualified dividends or
+// long-term gains y: federal tax at QDIRate (the same with or without AMT),
+// state tax as ordinary income, and NIIT when it applies. The itemized
+// state-tax deduction is still worth the ordinary bracket.
+func qualifiedAfterTax(y float64, in Inputs) float64 {
+	if y == 0 {
+		return 0
+	}
+	q := in
+	q.FedBracket = in.QDIRate
+	q.AMT = false
+	if in.AMT {
+		q.Itemize = false
+	}
+	if q.Itemize && q.DeductionBenefitRate == 0 {
+		q.DeductionBenefitRate = in.FedBracket
+	}
+	return calcAfterTaxYield(y, true, true, 0, q)
+}
+
+// deferredGainAfterTax annualizes a gain growing untaxed at gain% a year for
+// years, then taxed on sale. The basis comes back tax-free, so
+//
+//	(1+g_after)^n = (1+g)^n - t*((1+g)^n - 1)
+//
+// where t is the total rate on qualified income.
+func deferredGainAfterTax(gain, years float64, in Inputs) float64 {
+	if gain == 0 {
+		return 0
+	}
+	if years <= 1 {
+		return qualifiedAfterTax(gain, in)
+	}
+	keep := qualifiedAfterTax(1, in) // 1 - t
+	growth := math.Pow(1+gain/100, years)
+	after := growth - (1-keep)*(growth-1)
+	return 100 * (math.Pow(after, 1/years) - 1)
+}
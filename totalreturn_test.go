@@ -0,0 +1,52 @@
+package main
+
+import (
+	"math"
+	"testing"
+)
+
+func TestTotalReturnInstrument(t *testing.T) {
+	in := Inputs{FullyTaxable: 5, FedBracket: 32, QDIRate: 15}
+	// both return 8% a year
+	dividend := TotalReturnInstrument{Name: "Utility", DividendYield: 6, QualifiedFraction: 1, Appreciation: 2}
+	growth := TotalReturnInstrument{Name: "Growth", DividendYield: 1, QualifiedFraction: 1, Appreciation: 7}
+	afterTax := func(s TotalReturnInstrument) float64 {
+		t.Helper()
+		in := in
+		in.TotalReturn = s
+		line, ok := Compute(in).Line(TotalReturnKind)
+		if !ok || line.Label != s.Name || line.Yield != 8 {
+			t.Fatalf("%s: line %+v, %v", s.Name, line, ok)
+		}
+		return line.AfterTax
+	}
+
+	// taxed as it accrues, qualified dividends and gains are taxed alike
+	if d, g := afterTax(dividend), afterTax(growth); !near(d, 8*0.85) || !near(g, 8*0.85) {
+		t.Errorf("no deferral: dividend stock %v, growth stock %v; want both 6.8", d, g)
+	}
+
+	// Held 10 years, the growth stock defers most of its tax.
+	dividend.HoldingYears, growth.HoldingYears = 10, 10
+	deferred := func(gain float64) float64 {
+		g := math.Pow(1+gain/100, 10)
+		return 100 * (math.Pow(g-0.15*(g-1), 0.1) - 1)
+	}
+	d, g := afterTax(dividend), afterTax(growth)
+	if !near(d, 6*0.85+deferred(2)) || !near(g, 1*0.85+deferred(7)) {
+		t.Errorf("10 years: dividend stock %v, growth stock %v; want %v, %v", d, g, 6*0.85+deferred(2), 0.85+deferred(7))
+	}
+	if !(g > d && g > 6.8) {
+		t.Errorf("10 years: growth stock %v should beat the dividend stock %v and 6.8", g, d)
+	}
+
+	// nonqualified dividends are ordinary income
+	dividend.QualifiedFraction, dividend.HoldingYears = 0.5, 0
+	if got, want := afterTax(dividend), 3*0.68+3*0.85+2*0.85; !near(got, want) {
+		t.Errorf("half-qualified dividends: %v, want %v", got, want)
+	}
+
+	if _, ok := Compute(in).Line(TotalReturnKind); ok {
+		t.Error("a total return line without a total return instrument")
+	}
+}
@@ -0,0 +1,62 @@
+package main
+
+import "math"
+
+// TaxSystem selects which country's rules calcAfterTaxYield applies.
+type TaxSystem int
+
+const (
+	USTax TaxSystem = iota // the original JS model (default)
+	UKTax
+)
+
+// UKBand is a UK income tax band.
+type UKBand int
+
+const (
+	UKBasicRate UKBand = iota
+	UKHigherRate
+	UKAdditionalRate
+)
+
+// UK rates (%) for 2024/25, by band.
+var (
+	ukInterestRates = map[UKBand]float64{UKBasicRate: 20, UKHigherRate: 40, UKAdditionalRate: 45}
+	ukDividendRates = map[UKBand]float64{UKBasicRate: 8.75, UKHigherRate: 33.75, UKAdditionalRate: 39.35}
+)
+
+// UKDefaultSavingsAllowance is the 2024/25 personal savings allowance (£)
+// for a band. The dividend allowance is £500 for everyone.
+func UKDefaultSavingsAllowance(b UKBand) float64 {
+	switch b {
+	case UKBasicRate:
+		return 1000
+	case UKHigherRate:
+		return 500
+	default:
+		return 0
+	}
+}
+
+// calcAfterTaxYieldUK taxes yield as UK savings interest, or as dividends if
+// dividend is set. Gilts are income-taxable like any other interest (their
+// CGT exemption doesn't matter here, since only income is modeled).
+//
+// The allowances only come into play when in.UKHoldingAmount is set: income is
+// yield% of that amount, and only income above the allowance is taxed.
+// Otherwise the allowance is assumed used up and the band's rate applies to
+// everything.
+func calcAfterTaxYieldUK(yield float64, dividend bool, in Inputs) float64 {
+	rate := ukInterestRates[in.UKBand]
+	allowance := in.UKSavingsAllowance
+	if dividend {
+		rate = ukDividendRates[in.UKBand]
+		allowance = in.UKDividendAllowance
+	}
+	if in.UKHoldingAmount <= 0 {
+		return yield * (1 - rate/100)
+	}
+	income := in.UKHoldingAmount * yield / 100
+	tax := math.Max(0, income-allowance) * rate / 100
+	return (income - tax) / in.UKHoldingAmount * 100
+}
@@ -0,0 +1,45 @@
+package main
+
+import "testing"
+
+func TestUKHigherRate(t *testing.T) {
+	in := Inputs{TaxSystem: UKTax, UKBand: UKHigherRate}
+	tests := []struct {
+		name     string
+		holding  float64
+		dividend bool
+		want     float64
+	}{
+		// allowances assumed used up: 40% on interest, 33.75% on dividends
+		{"interest", 0, false, 3},
+		{"dividends", 0, true, 3.3125},
+		// £1,000 of income on £20,000, less the £500 allowances
+		{"interest over the allowance", 20000, false, 4},
+		{"dividends over the allowance", 20000, true, 4.15625},
+		// all of it inside the allowances
+		{"interest inside the allowance", 10000, false, 5},
+	}
+	for _, tt := range tests {
+		in := in
+		in.UKHoldingAmount = tt.holding
+		in.UKSavingsAllowance = UKDefaultSavingsAllowance(UKHigherRate)
+		in.UKDividendAllowance = 500
+		if got := calcAfterTaxYieldUK(5, tt.dividend, in); !near(got, tt.want) {
+			t.Errorf("%s: 5%% nets %v, want %v", tt.name, got, tt.want)
+		}
+	}
+	// dividends beat interest at the same yield for a higher-rate payer
+	if i, d := calcAfterTaxYieldUK(5, false, in), calcAfterTaxYieldUK(5, true, in); !(d > i) {
+		t.Errorf("dividends net %v, interest %v", d, i)
+	}
+}
+
+func TestUKComputeIgnoresUSSettings(t *testing.T) {
+	in := exampleInputs()
+	in.TaxSystem, in.UKBand = UKTax, UKHigherRate
+	res := Compute(in)
+	// gilts and munis alike are plain interest under UK rules
+	if !near(res.TreasuryAfterTax, 4.5*0.6) || !near(res.NatlAfterTax, 3.8*0.6) {
+		t.Errorf("treasury %v, natl %v; want 40%% off each", res.TreasuryAfterTax, res.NatlAfterTax)
+	}
+}
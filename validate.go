@@ -0,0 +1,92 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"math"
+)
+
+// Validate reports settings Compute would quietly paper over, joined into
+// one error. A nil error doesn't promise finite results; see ComputeStrict
+// for that.
+func (in Inputs) Validate() error {
+	var errs []error
+	if in.AMT && (in.AMTBracketIndex < 0 || in.AMTBracketIndex > 4) {
+		errs = append(errs, fmt.Errorf("AMTBracketIndex %d out of range 0..4", in.AMTBracketIndex))
+	}
+	if in.AMT {
+		for _, f := range in.amtPcts() {
+			if !(*f.p >= 0 && *f.p <= 100) {
+				errs = append(errs, fmt.Errorf("%s %g out of range 0..100", f.name, *f.p))
+			}
+		}
+	}
+	if in.Benchmark != FullyTaxableKind && in.Benchmark != TreasuryKind {
+		errs = append(errs, fmt.Errorf("Benchmark %s: want fully_taxable or treasury", in.Benchmark))
+	}
+	if b := in.Balanced; b.Yield != 0 {
+		if b.OrdinaryFraction < 0 || b.QualifiedFraction < 0 || math.Abs(b.OrdinaryFraction+b.QualifiedFraction-1) > weightEpsilon {
+			errs = append(errs, fmt.Errorf("Balanced fractions %g ordinary + %g qualified: want non-negative, summing to 1",
+				b.OrdinaryFraction, b.QualifiedFraction))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// ClampAMTPct is the lenient alternative to Validate's AMT-exposure check:
+// it pulls AMT-affected portions outside [0, 100] back to the nearest end
+// (NaN becomes 0) and says what it changed. Without AMT they're unused and
+// left alone.
+func ClampAMTPct(in Inputs) (Inputs, []string) {
+	if !in.AMT {
+		return in, nil
+	}
+	var warnings []string
+	for _, f := range in.amtPcts() {
+		v := *f.p
+		if v >= 0 && v <= 100 {
+			continue
+		}
+		*f.p = 0
+		if v > 100 {
+			*f.p = 100
+		}
+		warnings = append(warnings, fmt.Sprintf("%s: clamped %g to %g%%", f.name, v, *f.p))
+	}
+	return in, warnings
+}
+
+// amtPcts points at in's AMT-affected portions, for checking them in place.
+func (in *Inputs) amtPcts() []struct {
+	name string
+	p    *float64
+} {
+	return []struct {
+		name string
+		p    *float64
+	}{
+		{"NatlAmTPct", &in.NatlAmTPct},
+		{"StateAmTPct", &in.StateAmTPct},
+		{"TaxFreeMMFAMTPct", &in.TaxFreeMMFAMTPct},
+		{"AuthorityAmTPct", &in.AuthorityAmTPct},
+	}
+}
+
+// ComputeChecked is Compute, after Validate.
+func ComputeChecked(in Inputs) (Result, error) {
+	if err := in.Validate(); err != nil {
+		return Result{}, err
+	}
+	return Compute(in), nil
+}
+
+// MustCompute is ComputeChecked, panicking on a Validate error, like
+// regexp.MustCompile. Use it only on inputs you trust, such as constants in
+// tests and scripts; anything from a user should go through ComputeChecked.
+func MustCompute(in Inputs) Result {
+	res, err := ComputeChecked(in)
+	if err != nil {
+		panic("MustCompute: " + err.Error())
+	}
+	return res
+}
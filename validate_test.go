@@ -0,0 +1,119 @@
+package main
+
+import (
+	"math"
+	"strings"
+	"testing"
+)
+
+func TestAMTBracketIndex(t *testing.T) {
+	for _, tt := range []struct {
+		index   int
+		rate    float64
+		invalid bool
+	}{
+		{-1, 26, true},
+		{0, 26, false}, // unset; the same as 1
+		{1, 26, false},
+		{2, 32.5, false},
+		{3, 35, false},
+		{4, 28, false},
+		{5, 26, true},
+		{100, 26, true},
+	} {
+		in := Inputs{NatlTaxExempt: 4, NatlAmTPct: 100, FedBracket: 24, AMT: true, AMTBracketIndex: tt.index}
+		if got := amtRate(in); got != tt.rate {
+			t.Errorf("index %d: rate %v, want %v", tt.index, got, tt.rate)
+		}
+		if got := Compute(in).NatlAfterTax; !near(got, 4*(1-tt.rate/100)) {
+			t.Errorf("index %d: fully AMT-exposed muni nets %v, want %v", tt.index, got, 4*(1-tt.rate/100))
+		}
+		_, err := ComputeChecked(in)
+		if tt.invalid != (err != nil) {
+			t.Errorf("index %d: ComputeChecked error %v, want error %v", tt.index, err, tt.invalid)
+		}
+		if err != nil && !strings.Contains(err.Error(), "AMTBracketIndex") {
+			t.Errorf("index %d: error %q doesn't name AMTBracketIndex", tt.index, err)
+		}
+		// the index is unused without AMT
+		in.AMT = false
+		if _, err := ComputeChecked(in); err != nil {
+			t.Errorf("index %d without AMT: %v", tt.index, err)
+		}
+	}
+}
+
+func TestAMTPctStrict(t *testing.T) {
+	for _, pct := range []float64{-10, 150, math.NaN()} {
+		in := exampleInputs()
+		in.AMT, in.NatlAmTPct = true, pct
+		_, err := ComputeChecked(in)
+		if err == nil || !strings.Contains(err.Error(), "NatlAmTPct") {
+			t.Errorf("NatlAmTPct %v: ComputeChecked error %v, want one naming NatlAmTPct", pct, err)
+		}
+		// the portion is unused without AMT
+		in.AMT = false
+		if err := in.Validate(); err != nil {
+			t.Errorf("NatlAmTPct %v without AMT: %v", pct, err)
+		}
+	}
+	for _, pct := range []float64{0, 20, 100} {
+		in := exampleInputs()
+		in.AMT, in.NatlAmTPct = true, pct
+		if err := in.Validate(); err != nil {
+			t.Errorf("NatlAmTPct %v: %v", pct, err)
+		}
+	}
+}
+
+func TestClampAMTPct(t *testing.T) {
+	for _, tt := range []struct {
+		pct, want float64
+	}{
+		{150, 100},
+		{-10, 0},
+		{math.NaN(), 0},
+		{20, 20},
+	} {
+		in := exampleInputs()
+		in.AMT, in.NatlAmTPct = true, tt.pct
+		got, warnings := ClampAMTPct(in)
+		if got.NatlAmTPct != tt.want {
+			t.Errorf("NatlAmTPct %v: clamped to %v, want %v", tt.pct, got.NatlAmTPct, tt.want)
+		}
+		if changed := tt.pct != tt.want; changed != (len(warnings) == 1) || changed && !strings.Contains(warnings[0], "NatlAmTPct") {
+			t.Errorf("NatlAmTPct %v: warnings %q", tt.pct, warnings)
+		}
+		if err := got.Validate(); err != nil {
+			t.Errorf("NatlAmTPct %v: clamped inputs invalid: %v", tt.pct, err)
+		}
+		want := in
+		want.NatlAmTPct = tt.want
+		if a, b := Compute(got).NatlAfterTax, Compute(want).NatlAfterTax; a != b {
+			t.Errorf("NatlAmTPct %v: clamped muni nets %v, want %v", tt.pct, a, b)
+		}
+	}
+	in := exampleInputs()
+	in.NatlAmTPct = 150
+	if got, warnings := ClampAMTPct(in); got.NatlAmTPct != 150 || warnings != nil {
+		t.Errorf("without AMT: clamped to %v with %q, want it left alone", got.NatlAmTPct, warnings)
+	}
+}
+
+func TestMustCompute(t *testing.T) {
+	if got := MustCompute(exampleInputs()); !near(got.FullyTaxableAfterTax, 3.4466) {
+		t.Errorf("fully taxable nets %v, want 3.4466", got.FullyTaxableAfterTax)
+	}
+
+	in := exampleInputs()
+	in.AMT, in.NatlAmTPct = true, 150
+	defer func() {
+		r := recover()
+		msg, ok := r.(string)
+		if !ok || !strings.HasPrefix(msg, "MustCompute: ") || !strings.Contains(msg, "NatlAmTPct 150") {
+			t.Errorf("panicked with %#v, want the NatlAmTPct error", r)
+		}
+	}()
+	MustCompute(in)
+	t.Error("no panic for invalid inputs")
+}
@@ -0,0 +1,41 @@
+package main
+
+import "math"
+
+// worstAMTBracketIndex is the highest AMT rate amtRate offers (35%).
+const worstAMTBracketIndex = 3
+
+// WorstCaseAfterTax is Compute under the least favorable assumptions: no
+// itemized deductions (nor a deductible fee), NIIT at the flat 3.8%, and
+// AMT binding at its top 35% rate. AMT can lower the tax on taxable bonds
+// when the regular bracket is above 35%, so each line is taxed with or
+// without AMT, whichever leaves it less; that keeps every after-tax yield at
+// or below Compute(in)'s. TEYs use the worst-case gross-up.
+func WorstCaseAfterTax(in Inputs) Result {
+	worst := in
+	worst.Itemize = false
+	worst.FeeIsDeductible = false
+	worst.NIIT = true
+	worst.MAGI, worst.NetInvestmentIncome = 0, 0
+	worst.AMT = true
+	worst.AMTBracketIndex = worstAMTBracketIndex
+
+	insts := worst.ReportedInstruments()
+	for i := range insts {
+		insts[i].Rule = worstCaseRule{insts[i].rule()}
+	}
+	return ComputeInstruments(insts, worst)
+}
+
+// worstCaseRule is inner taxed with or without AMT, whichever is worse.
+type worstCaseRule struct {
+	inner InstrumentRule
+}
+
+func (r worstCaseRule) AfterTax(yield float64, in Inputs) float64 {
+	noAMT := in
+	noAMT.AMT = false
+	return math.Min(r.inner.AfterTax(yield, in), r.inner.AfterTax(yield, noAMT))
+}
+
+func (worstCaseRule) builtinRule() {}
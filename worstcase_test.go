@@ -0,0 +1,39 @@
+package main
+
+import "testing"
+
+func TestWorstCaseAfterTax(t *testing.T) {
+	high := exampleInputs()
+	high.FedBracket, high.StateBracket, high.NatlAmTPct = 37, 13.3, 25
+	fee := exampleInputs()
+	fee.AdvisoryFee, fee.FeeIsDeductible = 0.5, true
+	amt := exampleInputs()
+	amt.AMT, amt.AMTBracketIndex, amt.StateAmTPct = true, 4, 10
+	for name, in := range map[string]Inputs{"example": exampleInputs(), "37% California": high, "deductible fee": fee, "already AMT": amt} {
+		base, worst := Compute(in), WorstCaseAfterTax(in)
+		if len(worst.Lines) != len(base.Lines) {
+			t.Fatalf("%s: %d worst-case lines, %d base", name, len(worst.Lines), len(base.Lines))
+		}
+		for i, l := range base.Lines {
+			w := worst.Lines[i]
+			if w.Kind != l.Kind || w.AfterTax > l.AfterTax+1e-12 || w.AfterTaxAfterFee > l.AfterTaxAfterFee+1e-12 {
+				t.Errorf("%s: %s worst case %v (%v after fee), base %v (%v)", name, l.Label, w.AfterTax, w.AfterTaxAfterFee, l.AfterTax, l.AfterTaxAfterFee)
+			}
+		}
+	}
+
+	// 24%: the 35% AMT binds, with NIIT and no deduction
+	in := Inputs{FullyTaxable: 5, NatlTaxExempt: 3.5, NatlAmTPct: 20, FedBracket: 24, StateBracket: 5, Itemize: true}
+	worst := WorstCaseAfterTax(in)
+	if want := 5 * (1 - 0.35 - 0.05 - 0.038); !near(worst.FullyTaxableAfterTax, want) {
+		t.Errorf("fully taxable worst case %v, want %v", worst.FullyTaxableAfterTax, want)
+	}
+	if want := 3.5 * (1 - 0.05 - 0.2*0.35); !near(worst.NatlAfterTax, want) {
+		t.Errorf("natl worst case %v, want %v", worst.NatlAfterTax, want)
+	}
+	// above 35% the regular bracket is worse than AMT
+	in.FedBracket = 37
+	if want := 5 * (1 - 0.37 - 0.05 - 0.038); !near(WorstCaseAfterTax(in).FullyTaxableAfterTax, want) {
+		t.Errorf("37%%: fully taxable worst case %v, want %v", WorstCaseAfterTax(in).FullyTaxableAfterTax, want)
+	}
+}
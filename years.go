@@ -0,0 +1,64 @@
+package main
+
+import (
+	"fmt"
+	"math"
+)
+
+// ForYear returns in with the federal bracket and the LTCG rate (QDIRate)
+// looked up in year's rate tables (DefaultRateTables) for in.TaxableIncome
+// and in.FilingStatus. Under AMT, the AMT rate at that income picks
+// AMTBracketIndex too.
+func (in Inputs) ForYear(year int) (Inputs, error) {
+	if in.TaxableIncome <= 0 {
+		return in, fmt.Errorf("year %d: TaxableIncome is needed to look up brackets", year)
+	}
+	fed, err := FederalMarginalRate(year, in.TaxableIncome)
+	if err != nil {
+		return in, err
+	}
+	in.FedBracket = fed
+	qdi := LTCGRate(in.TaxableIncome, in.FilingStatus, year)
+	if math.IsNaN(qdi) {
+		return in, fmt.Errorf("year %d: no LTCG breakpoints for %s", year, in.FilingStatus)
+	}
+	in.QDIRate = qdi
+	if in.AMT {
+		rate, err := AMTMarginalRate(year, in.TaxableIncome)
+		if err != nil {
+			return in, err
+		}
+		in.AMTBracketIndex = amtBracketIndex(rate)
+	}
+	return in, nil
+}
+
+// amtBracketIndex is the inverse of amtRate.
+func amtBracketIndex(rate float64) int {
+	switch rate {
+	case 32.5:
+		return 2
+	case 35:
+		return 3
+	case 28:
+		return 4
+	default:
+		return 0
+	}
+}
+
+// CompareYears computes in under yearA's and yearB's rate tables and diffs
+// the two, B against A, to show the effect of a tax-law change. It fails if
+// either year can't be looked up (no tables, or no TaxableIncome).
+func CompareYears(in Inputs, yearA, yearB int) (Result, Result, []LineDiff, error) {
+	a, err := in.ForYear(yearA)
+	if err != nil {
+		return Result{}, Result{}, nil, err
+	}
+	b, err := in.ForYear(yearB)
+	if err != nil {
+		return Result{}, Result{}, nil, err
+	}
+	ra, rb := Compute(a), Compute(b)
+	return ra, rb, Diff(ra, rb), nil
+}
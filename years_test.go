@@ -0,0 +1,54 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestCompareYears(t *testing.T) {
+	// two made-up years far from the built-in ones: a 24% bracket that
+	// becomes 32%
+	ltcg := map[FilingStatus][2]float64{Single: {50000, 500000}}
+	DefaultRateTables.Register(2901, YearTables{Federal: []Bracket{{0, 10}, {100000, 24}}, LTCG: ltcg})
+	DefaultRateTables.Register(2902, YearTables{Federal: []Bracket{{0, 10}, {100000, 32}}, LTCG: ltcg})
+
+	in := exampleInputs()
+	in.Itemize = false
+	in.TaxableIncome = 150000
+	ra, rb, diff, err := CompareYears(in, 2901, 2902)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(diff) != len(ra.Lines) || len(diff) != len(rb.Lines) {
+		t.Fatalf("%d diffs for %d and %d lines", len(diff), len(ra.Lines), len(rb.Lines))
+	}
+	want := map[string]float64{
+		"fully_taxable": -5 * 0.08,
+		"treasury":      -4.5 * 0.08,
+		"natl":          0,
+		"state":         0,
+		"amt_free":      0,
+	}
+	for _, d := range diff {
+		w, ok := want[d.Key]
+		if !ok {
+			t.Errorf("unexpected line %q", d.Key)
+			continue
+		}
+		if !near(d.AfterTax, w) {
+			t.Errorf("%s: after-tax change %v, want %v", d.Key, d.AfterTax, w)
+		}
+	}
+}
+
+func TestCompareYearsErrors(t *testing.T) {
+	in := exampleInputs()
+	in.TaxableIncome = 150000
+	if _, _, _, err := CompareYears(in, 2024, 1900); err == nil || !strings.Contains(err.Error(), "no rate tables for 1900") {
+		t.Errorf("unknown year: err = %v", err)
+	}
+	in.TaxableIncome = 0
+	if _, _, _, err := CompareYears(in, 2024, 2025); err == nil || !strings.Contains(err.Error(), "TaxableIncome") {
+		t.Errorf("no income: err = %v", err)
+	}
+}
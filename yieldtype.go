@@ -0,0 +1,54 @@
+package main
+
+// YieldType is the basis a fund's yield was quoted on. Mixing bases makes
+// comparisons misleading, so each instrument carries its own.
+type YieldType int
+
+const (
+	// SECYield is the standardized SEC 30-day yield (the default).
+	SECYield YieldType = iota
+	// DistributionYield is trailing distributions over NAV.
+	DistributionYield
+)
+
+func (t YieldType) String() string {
+	switch t {
+	case SECYield:
+		return "SEC 30-day"
+	case DistributionYield:
+		return "distribution"
+	default:
+		return "unknown"
+	}
+}
+
+// ConversionParams describes how a fund's distribution yield differs from its
+// SEC yield. Values are in yield points (0.3 for 0.3%).
+type ConversionParams struct {
+	// ReturnOfCapital is the part of the distribution that hands back
+	// principal rather than income.
+	ReturnOfCapital float64
+	// PremiumAmortization is coupon income above the holdings' yield to
+	// maturity, which distribution yields count but SEC yields amortize away.
+	PremiumAmortization float64
+}
+
+// ConvertYield converts y between yield bases:
+//
+//	SEC = Distribution - ReturnOfCapital - PremiumAmortization
+//
+// Converting to the same type returns y unchanged.
+func ConvertYield(y float64, from, to YieldType, params ConversionParams) float64 {
+	if from == to {
+		return y
+	}
+	adj := params.ReturnOfCapital + params.PremiumAmortization
+	switch {
+	case from == DistributionYield && to == SECYield:
+		return y - adj
+	case from == SECYield && to == DistributionYield:
+		return y + adj
+	default:
+		return y
+	}
+}
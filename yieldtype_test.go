@@ -0,0 +1,42 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestConvertYield(t *testing.T) {
+	params := ConversionParams{ReturnOfCapital: 0.3, PremiumAmortization: 0.2}
+	tests := []struct {
+		name     string
+		y        float64
+		from, to YieldType
+		want     float64
+	}{
+		{"SEC to SEC", 4.1, SECYield, SECYield, 4.1},
+		{"distribution to distribution", 4.1, DistributionYield, DistributionYield, 4.1},
+		{"distribution to SEC", 4.5, DistributionYield, SECYield, 4.0},
+		{"SEC to distribution", 4.0, SECYield, DistributionYield, 4.5},
+	}
+	for _, tt := range tests {
+		if got := ConvertYield(tt.y, tt.from, tt.to, params); !near(got, tt.want) {
+			t.Errorf("%s: ConvertYield(%v) = %v, want %v", tt.name, tt.y, got, tt.want)
+		}
+	}
+}
+
+func TestYieldTypeInResult(t *testing.T) {
+	in := exampleInputs()
+	in.NatlTaxExemptType = DistributionYield
+	res := Compute(in)
+	if res.NatlTaxExemptType != DistributionYield || res.TreasuryType != SECYield {
+		t.Errorf("types %v, %v; want distribution, SEC 30-day", res.NatlTaxExemptType, res.TreasuryType)
+	}
+	l, ok := res.Line(NatlTaxExemptKind)
+	if !ok || l.Basis != DistributionYield {
+		t.Fatalf("natl line %+v", l)
+	}
+	if !strings.Contains(res.Text, "distribution") {
+		t.Errorf("Text doesn't label the distribution basis:\n%s", res.Text)
+	}
+}